@@ -0,0 +1,44 @@
+package main
+
+import (
+	"io"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
+
+// promptedMsg reports that the questions of form were asked as plain prompts
+type promptedMsg struct {
+	form *huh.Form
+	err  error
+}
+
+// formPrompt asks the questions of a form as plain prompts, one per line, which screen
+// readers can follow. It runs with the terminal handed over by Bubble Tea, see tea.Exec.
+type formPrompt struct {
+	form *huh.Form
+	in   io.Reader
+	out  io.Writer
+}
+
+func (p *formPrompt) SetStdin(r io.Reader)  { p.in = r }
+func (p *formPrompt) SetStdout(w io.Writer) { p.out = w }
+func (p *formPrompt) SetStderr(io.Writer)   {}
+
+// Run asks the questions, leaving the answers in the form's fields
+func (p *formPrompt) Run() error {
+	// Running a form makes it quit the program once submitted; it is still ours to update
+	submit, cancel := p.form.SubmitCmd, p.form.CancelCmd
+	defer func() {
+		p.form.SubmitCmd, p.form.CancelCmd = submit, cancel
+	}()
+	return p.form.WithAccessible(true).WithInput(p.in).WithOutput(p.out).Run()
+}
+
+// prompt hands the terminal over to ask the questions of form as plain prompts,
+// reporting back with a promptedMsg
+func prompt(form *huh.Form) tea.Cmd {
+	return tea.Exec(&formPrompt{form: form}, func(err error) tea.Msg {
+		return promptedMsg{form: form, err: err}
+	})
+}
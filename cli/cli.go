@@ -0,0 +1,82 @@
+// Package cli implements the non-interactive termsheet subcommands
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// command is a single termsheet subcommand
+type command struct {
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+// commands maps subcommand names to their implementations
+var commands = map[string]command{
+	"clients": {
+		summary: "list clients (--tag to filter, --archived to include archived ones)",
+		run:     runClients,
+	},
+	"doctor": {
+		summary: "check the database for integrity problems (--repair to fix them)",
+		run:     runDoctor,
+	},
+	"fields": {
+		summary: "list, add and remove custom fields on clients, providers and invoices",
+		run:     runFields,
+	},
+	"invoices": {
+		summary: "list invoices (--tag to filter)",
+		run:     runInvoices,
+	},
+	"tags": {
+		summary: "list tags and how many clients and invoices carry them",
+		run:     runTags,
+	},
+	"vars": {
+		summary: "print an invoice's template variables, custom fields included",
+		run:     runVars,
+	},
+}
+
+// Run executes the subcommand named by args[0] and returns the process exit code.
+// The database must already be initialised.
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return 2
+	}
+
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(stdout)
+		return 0
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "termsheet: unknown command %q\n\n", args[0])
+		printUsage(stderr)
+		return 2
+	}
+
+	return cmd.run(args[1:], stdout, stderr)
+}
+
+// printUsage lists the available subcommands
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: termsheet [command]")
+	fmt.Fprintln(w, "\nRun without a command to start the interactive interface.")
+	fmt.Fprintln(w, "\nCommands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].summary)
+	}
+}
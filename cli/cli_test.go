@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/GVPproj/termsheet/storage"
+)
+
+func TestRunUnknownCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	code := Run([]string{"frobnicate"}, &stdout, &stderr)
+
+	if code != 2 {
+		t.Errorf("expected exit code 2, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "unknown command") {
+		t.Errorf("expected unknown command message, got %q", stderr.String())
+	}
+}
+
+func TestRunHelpListsCommands(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	code := Run([]string{"help"}, &stdout, &stderr)
+
+	if code != 0 {
+		t.Errorf("expected exit code 0, got %d", code)
+	}
+	if !strings.Contains(stdout.String(), "doctor") {
+		t.Errorf("expected usage to list doctor, got %q", stdout.String())
+	}
+}
+
+func TestDoctorHealthyDatabase(t *testing.T) {
+	if err := storage.InitDB(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.CloseDB()
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"doctor"}, &stdout, &stderr)
+
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "No integrity problems") {
+		t.Errorf("expected healthy report, got %q", stdout.String())
+	}
+}
+
+func TestFieldsAddListRemove(t *testing.T) {
+	if err := storage.InitDB(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.CloseDB()
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"fields", "add", "invoice", "cli_test_centre", "Cost Centre", "select", "R&D", "Sales"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	defer Run([]string{"fields", "remove", "invoice", "cli_test_centre"}, &stdout, &stderr)
+
+	stdout.Reset()
+	if code := Run([]string{"fields", "list", "invoice"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+	if !strings.Contains(stdout.String(), "cli_test_centre") || !strings.Contains(stdout.String(), "[R&D, Sales]") {
+		t.Errorf("expected field in listing, got %q", stdout.String())
+	}
+
+	stderr.Reset()
+	if code := Run([]string{"fields", "add", "invoice", "cli_test_centre", "Again", "text"}, &stdout, &stderr); code != 1 {
+		t.Errorf("expected duplicate field to fail with exit code 1, got %d", code)
+	}
+
+	stderr.Reset()
+	if code := Run([]string{"fields", "add", "order", "x", "X", "text"}, &stdout, &stderr); code != 2 {
+		t.Errorf("expected unknown entity type to fail with exit code 2, got %d", code)
+	}
+}
+
+func TestListCommandsFilterByTag(t *testing.T) {
+	if err := storage.InitDB(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.CloseDB()
+
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"invoices", "--tag", "cli-test-unused"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "No invoices found") {
+		t.Errorf("expected no invoices for an unused tag, got %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := Run([]string{"clients", "--tag", "#cli-test-unused", "--archived"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "No clients found") {
+		t.Errorf("expected no clients for an unused tag, got %q", stdout.String())
+	}
+
+	if code := Run([]string{"clients", "--colour"}, &stdout, &stderr); code != 2 {
+		t.Errorf("expected unknown flag to fail with exit code 2, got %d", code)
+	}
+}
+
+func TestVarsPrintsTemplateVariables(t *testing.T) {
+	if err := storage.InitDB(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer storage.CloseDB()
+
+	providerID, _ := storage.CreateProvider("CLI Vars Provider", nil, nil, nil)
+	defer storage.DeleteProvider(providerID)
+	clientID, _ := storage.CreateClient("CLI Vars Client", nil, nil, nil)
+	defer storage.DeleteClient(clientID)
+	invoiceID, err := storage.CreateInvoice(providerID, clientID, false)
+	if err != nil {
+		t.Fatalf("Failed to create test invoice: %v", err)
+	}
+	defer func() {
+		_ = storage.TrashInvoice(invoiceID)
+		_ = storage.PurgeInvoice(invoiceID)
+	}()
+
+	var stdout, stderr bytes.Buffer
+	ref := "#" + strconv.Itoa(invoiceID)
+	if code := Run([]string{"vars", ref}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	for _, line := range []string{"client.name=CLI Vars Client", "invoice.number=" + ref, "provider.name=CLI Vars Provider"} {
+		if !strings.Contains(stdout.String(), line+"\n") {
+			t.Errorf("expected %q in output, got %q", line, stdout.String())
+		}
+	}
+
+	if code := Run([]string{"vars", "no-such-invoice"}, &stdout, &stderr); code != 1 {
+		t.Errorf("expected an unknown invoice to fail with exit code 1, got %d", code)
+	}
+	if code := Run([]string{"vars"}, &stdout, &stderr); code != 2 {
+		t.Errorf("expected a missing invoice to fail with exit code 2, got %d", code)
+	}
+}
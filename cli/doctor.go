@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/GVPproj/termsheet/storage"
+)
+
+// runDoctor implements `termsheet doctor [--repair]`
+func runDoctor(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	repair := fs.Bool("repair", false, "repair the problems that are found")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var report *storage.IntegrityReport
+	var err error
+	if *repair {
+		report, err = storage.RepairIntegrity()
+	} else {
+		report, err = storage.CheckIntegrity()
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "doctor: %v\n", err)
+		return 1
+	}
+
+	if report.OK() {
+		fmt.Fprintln(stdout, "No integrity problems found")
+		return 0
+	}
+
+	for _, item := range report.OrphanedItems {
+		fmt.Fprintf(stdout, "invoice item %d (%q) references missing invoice #%d\n",
+			item.ItemID, item.ItemName, item.InvoiceID)
+	}
+	for _, inv := range report.OrphanedInvoices {
+		if inv.MissingProviderID != "" {
+			fmt.Fprintf(stdout, "invoice #%d references missing provider %s\n", inv.InvoiceID, inv.MissingProviderID)
+		}
+		if inv.MissingClientID != "" {
+			fmt.Fprintf(stdout, "invoice #%d references missing client %s\n", inv.InvoiceID, inv.MissingClientID)
+		}
+	}
+
+	if *repair {
+		fmt.Fprintf(stdout, "\nRepaired: deleted %d orphaned item(s), recreated placeholders for %d invoice(s)\n",
+			len(report.OrphanedItems), len(report.OrphanedInvoices))
+		return 0
+	}
+
+	fmt.Fprintln(stdout, "\nRun `termsheet doctor --repair` to fix these problems")
+	return 1
+}
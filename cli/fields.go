@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/GVPproj/termsheet/models"
+	"github.com/GVPproj/termsheet/storage"
+	"github.com/GVPproj/termsheet/utils"
+)
+
+const fieldsUsage = `Usage:
+  termsheet fields list [client|provider|invoice]
+  termsheet fields add <client|provider|invoice> <key> <label> <text|number|date|select> [option...]
+  termsheet fields remove <client|provider|invoice> <key>`
+
+// runFields implements `termsheet fields`, which manages custom field definitions
+func runFields(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, fieldsUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "list":
+		return listFields(args[1:], stdout, stderr)
+	case "add":
+		return addField(args[1:], stdout, stderr)
+	case "remove":
+		return removeField(args[1:], stdout, stderr)
+	}
+
+	fmt.Fprintf(stderr, "fields: unknown subcommand %q\n\n%s\n", args[0], fieldsUsage)
+	return 2
+}
+
+// listFields prints the custom fields of one or all entity types
+func listFields(args []string, stdout, stderr io.Writer) int {
+	entityTypes := models.EntityTypes
+	if len(args) > 0 {
+		entityType, ok := parseEntityType(args[0], stderr)
+		if !ok {
+			return 2
+		}
+		entityTypes = []models.EntityType{entityType}
+	}
+
+	found := false
+	for _, entityType := range entityTypes {
+		fields, err := storage.ListCustomFields(entityType)
+		if err != nil {
+			fmt.Fprintf(stderr, "fields: %v\n", err)
+			return 1
+		}
+		for _, f := range fields {
+			found = true
+			line := fmt.Sprintf("%-8s %s %-8s %s", f.EntityType, utils.PadRight(f.Key, 20), f.Kind, f.Label)
+			if len(f.Options) > 0 {
+				line += fmt.Sprintf(" [%s]", strings.Join(f.Options, ", "))
+			}
+			fmt.Fprintln(stdout, line)
+		}
+	}
+
+	if !found {
+		fmt.Fprintln(stdout, "No custom fields defined")
+	}
+	return 0
+}
+
+// addField defines a new custom field
+func addField(args []string, stdout, stderr io.Writer) int {
+	if len(args) < 4 {
+		fmt.Fprintln(stderr, fieldsUsage)
+		return 2
+	}
+
+	entityType, ok := parseEntityType(args[0], stderr)
+	if !ok {
+		return 2
+	}
+
+	field := models.CustomField{
+		EntityType: entityType,
+		Key:        args[1],
+		Label:      args[2],
+		Kind:       models.CustomFieldKind(args[3]),
+		Options:    args[4:],
+	}
+	if _, err := storage.CreateCustomField(field); err != nil {
+		fmt.Fprintf(stderr, "fields: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "Added %s field %s\n", field.EntityType, field.Key)
+	return 0
+}
+
+// removeField deletes a custom field and its values
+func removeField(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 2 {
+		fmt.Fprintln(stderr, fieldsUsage)
+		return 2
+	}
+
+	entityType, ok := parseEntityType(args[0], stderr)
+	if !ok {
+		return 2
+	}
+
+	err := storage.DeleteCustomField(entityType, args[1])
+	if errors.Is(err, sql.ErrNoRows) {
+		fmt.Fprintf(stderr, "fields: no %s field %q\n", entityType, args[1])
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "fields: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "Removed %s field %s and its values\n", entityType, args[1])
+	return 0
+}
+
+// parseEntityType reports an error to stderr if s isn't a known entity type
+func parseEntityType(s string, stderr io.Writer) (models.EntityType, bool) {
+	for _, t := range models.EntityTypes {
+		if string(t) == s {
+			return t, true
+		}
+	}
+	fmt.Fprintf(stderr, "fields: unknown entity type %q (want client, provider or invoice)\n", s)
+	return "", false
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/GVPproj/termsheet/models"
+	"github.com/GVPproj/termsheet/storage"
+	"github.com/GVPproj/termsheet/utils"
+)
+
+// tagFlags collects repeated --tag flags
+type tagFlags []string
+
+func (f *tagFlags) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *tagFlags) Set(value string) error {
+	*f = append(*f, models.ParseTags(value)...)
+	return nil
+}
+
+// runTags implements `termsheet tags`, which lists tags with how often they are used
+func runTags(args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 {
+		fmt.Fprintln(stderr, "Usage: termsheet tags")
+		return 2
+	}
+
+	tags, err := storage.ListTags()
+	if err != nil {
+		fmt.Fprintf(stderr, "tags: %v\n", err)
+		return 1
+	}
+	if len(tags) == 0 {
+		fmt.Fprintln(stdout, "No tags in use")
+		return 0
+	}
+
+	for _, t := range tags {
+		fmt.Fprintf(stdout, "%s %d\n", utils.PadRight("#"+t.Name, 25), t.Count)
+	}
+	return 0
+}
+
+// runClients implements `termsheet clients [--archived] [--tag name]...`
+func runClients(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("clients", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	archived := fs.Bool("archived", false, "include archived clients")
+	var tags tagFlags
+	fs.Var(&tags, "tag", "only list clients with this tag (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	clients, err := storage.ListEntitiesMatching("client", storage.EntityFilter{
+		IncludeArchived: *archived,
+		Tags:            tags,
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "clients: %v\n", err)
+		return 1
+	}
+	if len(clients) == 0 {
+		fmt.Fprintln(stdout, "No clients found")
+		return 0
+	}
+
+	for _, c := range clients {
+		line := c.Name
+		if c.Email != nil {
+			line += fmt.Sprintf(" <%s>", *c.Email)
+		}
+		if c.Archived {
+			line += " [archived]"
+		}
+		if len(c.Tags) > 0 {
+			line += "  " + models.TagChips(c.Tags)
+		}
+		fmt.Fprintln(stdout, line)
+	}
+	return 0
+}
+
+// runInvoices implements `termsheet invoices [--tag name]...`
+func runInvoices(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("invoices", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var tags tagFlags
+	fs.Var(&tags, "tag", "only list invoices with this tag (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	invoices, err := storage.ListInvoicesMatching(storage.InvoiceFilter{Tags: tags})
+	if err != nil {
+		fmt.Fprintf(stderr, "invoices: %v\n", err)
+		return 1
+	}
+	if len(invoices) == 0 {
+		fmt.Fprintln(stdout, "No invoices found")
+		return 0
+	}
+
+	for _, inv := range invoices {
+		paid := "unpaid"
+		if inv.Paid {
+			paid = "paid"
+		}
+		line := fmt.Sprintf("%s %s  %s → %s (%s)",
+			utils.PadRight(inv.DisplayNumber(), 14), inv.DateCreated.Format("2006-01-02"), inv.ProviderName, inv.ClientName, paid)
+		if len(inv.Tags) > 0 {
+			line += "  " + models.TagChips(inv.Tags)
+		}
+		fmt.Fprintln(stdout, line)
+	}
+	return 0
+}
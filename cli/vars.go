@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/GVPproj/termsheet/storage"
+)
+
+// runVars implements `termsheet vars <invoice>`, which prints an invoice's template
+// variables as name=value lines for scripts and templates to fill in
+func runVars(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintln(stderr, "Usage: termsheet vars <invoice number>")
+		return 2
+	}
+
+	// Only an exact number or ID, so a script never fills in the wrong invoice
+	ref := strings.TrimPrefix(args[0], "#")
+	invoices, err := storage.FindInvoicesByNumber(ref, 1)
+	if err != nil {
+		fmt.Fprintf(stderr, "vars: %v\n", err)
+		return 1
+	}
+	if len(invoices) == 0 || (!strings.EqualFold(invoices[0].Number, ref) && strconv.Itoa(invoices[0].ID) != ref) {
+		fmt.Fprintf(stderr, "vars: no invoice %s\n", args[0])
+		return 1
+	}
+
+	data, err := storage.GetInvoiceData(invoices[0].ID)
+	if err != nil {
+		fmt.Fprintf(stderr, "vars: %v\n", err)
+		return 1
+	}
+
+	vars := data.TemplateVariables()
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		fmt.Fprintf(stdout, "%s=%s\n", name, vars[name])
+	}
+	return 0
+}
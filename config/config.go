@@ -0,0 +1,100 @@
+// Package config loads the user's settings, such as key bindings and the theme, from the config file.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// EnvPath names the environment variable that points at a config file
+// in place of the default location
+const EnvPath = "TERMSHEET_CONFIG"
+
+// EnvAccessible names the environment variable that turns on accessible mode when set,
+// as it does for other programs built on huh
+const EnvAccessible = "ACCESSIBLE"
+
+// Config holds the settings read from the config file. Settings left out keep their defaults.
+type Config struct {
+	Keymap Keymap `json:"keymap"`
+	Theme  Theme  `json:"theme"`
+	// Accessible asks the questions of forms as plain prompts, one per line,
+	// in place of redrawn forms that screen readers can't follow
+	Accessible bool `json:"accessible"`
+}
+
+// Keymap chooses the key bindings: a preset, with single actions rebound on top of it
+type Keymap struct {
+	// Preset is "default", "vim" or "emacs"; empty means "default"
+	Preset string `json:"preset"`
+	// Bindings maps action names, e.g. "delete", to the keys that trigger them
+	Bindings map[string][]string `json:"bindings"`
+}
+
+// Theme chooses the colours: a built-in theme, with single colours changed on top of it.
+// In the config file it is either an object or just the name of the built-in theme.
+type Theme struct {
+	// Base is "dark", "light", "solarized" or "high-contrast"; empty means "dark"
+	Base string `json:"base"`
+	// Colors maps colour roles, e.g. "primary", to hex colours or ANSI colour numbers
+	Colors map[string]string `json:"colors"`
+}
+
+// UnmarshalJSON reads a theme from an object, or from a string naming the built-in theme
+func (t *Theme) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = Theme{Base: name}
+		return nil
+	}
+	// A distinct type, so decoding the object doesn't call this method again
+	type theme Theme
+	return json.Unmarshal(data, (*theme)(t))
+}
+
+// AccessibleMode reports whether accessible mode is on, in the config file or through $ACCESSIBLE
+func (c Config) AccessibleMode() bool {
+	return c.Accessible || os.Getenv(EnvAccessible) != ""
+}
+
+// Path returns where the config file is read from: $TERMSHEET_CONFIG if set,
+// otherwise termsheet/config.json in the user's config directory
+func Path() (string, error) {
+	if path := os.Getenv(EnvPath); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "termsheet", "config.json"), nil
+}
+
+// Load reads the config file; without one, every setting has its default
+func Load() (Config, error) {
+	path, err := Path()
+	if err != nil {
+		return Config{}, err
+	}
+	return LoadFile(path)
+}
+
+// LoadFile reads the config file at path; if there is no file there, every setting has its default
+func LoadFile(path string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return cfg, nil
+}
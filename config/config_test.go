@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"keymap": {"preset": "vim", "bindings": {"delete": ["D", "delete"]}}, "theme": "solarized", "accessible": true}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if cfg.Keymap.Preset != "vim" || !slices.Equal(cfg.Keymap.Bindings["delete"], []string{"D", "delete"}) {
+		t.Errorf("unexpected keymap %+v", cfg.Keymap)
+	}
+	if cfg.Theme.Base != "solarized" || cfg.Theme.Colors != nil || !cfg.Accessible {
+		t.Errorf("unexpected theme %+v or accessible %v", cfg.Theme, cfg.Accessible)
+	}
+}
+
+func TestLoadFileThemeColors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"theme": {"base": "light", "colors": {"primary": "#FF8800", "error": "196"}}}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if cfg.Theme.Base != "light" || cfg.Theme.Colors["primary"] != "#FF8800" || cfg.Theme.Colors["error"] != "196" {
+		t.Errorf("unexpected theme %+v", cfg.Theme)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"theme": 7}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(path); err == nil {
+		t.Error("expected an error for a theme that is neither a name nor an object")
+	}
+}
+
+func TestLoadFileMissing(t *testing.T) {
+	cfg, err := LoadFile(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("expected no error without a config file, got %v", err)
+	}
+	if cfg.Keymap.Preset != "" || cfg.Keymap.Bindings != nil {
+		t.Errorf("expected the defaults, got %+v", cfg)
+	}
+}
+
+func TestLoadFileInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"keymap": `), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFile(path); err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("expected an error naming the file, got %v", err)
+	}
+}
+
+func TestPathFromEnvironment(t *testing.T) {
+	t.Setenv(EnvPath, "/tmp/termsheet.json")
+
+	path, err := Path()
+	if err != nil || path != "/tmp/termsheet.json" {
+		t.Errorf("expected the path from %s, got %q (%v)", EnvPath, path, err)
+	}
+}
+
+func TestAccessibleModeFromEnvironment(t *testing.T) {
+	t.Setenv(EnvAccessible, "")
+	if (Config{}).AccessibleMode() {
+		t.Error("expected accessible mode to be off by default")
+	}
+
+	t.Setenv(EnvAccessible, "1")
+	if !(Config{}).AccessibleMode() {
+		t.Errorf("expected %s to turn on accessible mode", EnvAccessible)
+	}
+}
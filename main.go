@@ -4,17 +4,32 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 
+	"github.com/GVPproj/termsheet/cli"
+	"github.com/GVPproj/termsheet/config"
+	"github.com/GVPproj/termsheet/models"
 	"github.com/GVPproj/termsheet/storage"
 	"github.com/GVPproj/termsheet/tui/components/client"
 	"github.com/GVPproj/termsheet/tui/components/invoice"
+	"github.com/GVPproj/termsheet/tui/components/palette"
 	"github.com/GVPproj/termsheet/tui/components/provider"
+	"github.com/GVPproj/termsheet/tui/components/search"
+	"github.com/GVPproj/termsheet/tui/components/status"
+	"github.com/GVPproj/termsheet/tui/keys"
+	"github.com/GVPproj/termsheet/tui/router"
+	"github.com/GVPproj/termsheet/tui/theme"
 	"github.com/GVPproj/termsheet/tui/views"
 	"github.com/GVPproj/termsheet/types"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
 )
 
+// LogFile receives log output while the TUI is running, since writing to
+// stderr would corrupt the alt-screen
+const LogFile = "termsheet.log"
+
 type model struct {
 	currentView types.View
 	cursor      int
@@ -27,22 +42,54 @@ type model struct {
 	providerComponent *provider.Controller
 	clientComponent   *client.Controller
 	invoiceComponent  *invoice.Controller
+	searchComponent   *search.Controller
+	paletteComponent  *palette.Controller
+
+	// router knows which component owns each view and how to draw it
+	router *router.Router
+
+	// status shows success, warning and error notifications below the current view
+	status status.Model
+
+	// history holds the views esc goes back through, oldest first;
+	// crumb labels the current view in the breadcrumbs
+	history []navEntry
+	crumb   string
+
+	// showHelp covers the current view with the help overlay until a key is pressed
+	showHelp bool
+
+	// width and height are the size of the terminal, or zero before the first
+	// tea.WindowSizeMsg; views are drawn to fit them
+	width  int
+	height int
+
+	// accessible asks the questions of the forms to fill in as plain prompts
+	accessible bool
+}
+
+// navEntry is a view the user came through, with its breadcrumb label
+type navEntry struct {
+	view  types.View
+	crumb string
 }
 
 // createMenuForm is a method on the model struct
 // The (m *model) part is called a receiver - it makes createMenuForm() a method on the model struct
 func (m *model) createMenuForm() *huh.Form {
+	// The menu lists the components that registered an entry with the router
+	options := make([]huh.Option[string], 0, len(m.router.Menu()))
+	for _, entry := range m.router.Menu() {
+		options = append(options, huh.NewOption(entry.Label, entry.Key))
+	}
+
 	return huh.NewForm(
 		huh.NewGroup(
 			huh.NewSelect[string]().
 				// .Title() - Sets the prompt text shown to the user
 				Title("Please select a view").
 				// .Options() - Defines the selectable choices (label + value pairs)
-				Options(
-					huh.NewOption("Providers - Who is invoicing?", "Providers"),
-					huh.NewOption("Clients - Who is paying?", "Clients"),
-					huh.NewOption("Invoices - Create, Edit, Track, Export", "Invoices"),
-				).
+				Options(options...).
 				// .Value(&m.selection) - Binds the selected value to the m.selection field on the model struct
 				// using a pointer to that variable.
 				// Why a Pointer is Needed for Modification: If Value() were given just m.selection (the value itself, not its address),
@@ -52,7 +99,7 @@ func (m *model) createMenuForm() *huh.Form {
 				// the user's selection, directly updating m.selection in your model struct.
 				Value(&m.selection),
 		),
-	).WithTheme(views.GetMenuTheme())
+	).WithTheme(views.GetMenuTheme()).WithKeyMap(keys.Current().Form())
 }
 
 func initialModel() *model {
@@ -62,8 +109,21 @@ func initialModel() *model {
 		providerComponent: provider.NewController(),
 		clientComponent:   client.NewController(),
 		invoiceComponent:  invoice.NewController(),
+		searchComponent:   search.NewController(),
+		paletteComponent:  palette.NewController(),
+		status:            status.New(),
+		router:            router.New(),
 	}
 
+	// Components appear in the menu in the order they are registered
+	m.router.Handle(router.Route{View: types.MenuView, Title: "Menu", Render: views.RenderMenu, Shortcuts: true})
+	m.router.Register(m.providerComponent)
+	m.router.Register(m.clientComponent)
+	m.router.Register(m.invoiceComponent)
+	m.router.Register(m.searchComponent)
+	m.router.Register(m.paletteComponent)
+
+	m.crumb = m.router.Title(types.MenuView)
 	m.form = m.createMenuForm()
 	return m
 }
@@ -73,20 +133,85 @@ func (m *model) Init() tea.Cmd {
 }
 
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Views reflow to the terminal; forms are refitted whenever the size or the form changes
+	size, resized := msg.(tea.WindowSizeMsg)
+	var resizeCmd tea.Cmd
+	if resized {
+		m.width, m.height = size.Width, size.Height
+		m.providerComponent.Resize(m.width, m.height)
+		m.clientComponent.Resize(m.width, m.height)
+		resizeCmd = m.invoiceComponent.Resize(m.width, m.height)
+	}
+
+	form := m.form
+	model, cmd := m.update(msg)
+	cmd = tea.Batch(resizeCmd, cmd)
+	if m.form != nil && (resized || m.form != form) {
+		m.form.WithWidth(views.FormWidth(m.width))
+	}
+	if m.form != nil && m.form != form {
+		// Every new form takes the current theme and key bindings, overriding any the view set
+		m.form.WithTheme(views.GetMenuTheme()).WithKeyMap(keys.Current().Form())
+		if m.accessible && m.router.Prompt(m.currentView) {
+			cmd = tea.Batch(cmd, prompt(m.form))
+		}
+	}
+	return model, cmd
+}
+
+func (m *model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Notifications belong to the status bar and are never passed to the views
+	if handled, cmd := m.status.Update(msg); handled {
+		return m, cmd
+	}
+
 	switch msg := msg.(type) {
+	case search.OpenMsg:
+		return m.applyTransition(m.openSearchResult(msg))
+	case palette.RunMsg:
+		return m.applyTransition(m.runCommand(msg.Command))
+	case promptedMsg:
+		// Answers to a form since replaced are dropped
+		if msg.form != m.form {
+			return m, nil
+		}
+		if msg.err != nil {
+			return m, status.Err("asking questions", msg.err)
+		}
+		// The owner of the view takes the answered form as submitted
+		m.form.State = huh.StateCompleted
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		// Any key closes the help overlay
+		if m.showHelp {
+			m.showHelp = false
+			return m, nil
+		}
+
+		km := keys.Current()
+		switch {
+		case keys.Filtering(m.form):
+			// Keys go to the filter, esc included, until it is set or cleared
+		case key.Matches(msg, km.Quit):
 			if m.currentView == types.MenuView {
 				return m, tea.Quit
 			}
-		case "esc":
+		case key.Matches(msg, km.Search):
+			// Only where keys can't be text input, i.e. menus, lists and the invoice display
+			if m.router.Shortcuts(m.currentView) {
+				return m.applyTransition(m.searchComponent.Open())
+			}
+		case key.Matches(msg, km.Palette):
+			if m.router.Shortcuts(m.currentView) {
+				return m.applyTransition(m.paletteComponent.Open())
+			}
+		case key.Matches(msg, km.Help):
+			if m.router.Shortcuts(m.currentView) {
+				m.showHelp = true
+				return m, nil
+			}
+		case key.Matches(msg, km.Back):
 			if m.currentView != types.MenuView {
-				// Reset form when returning to menu
-				m.currentView = types.MenuView
-				m.selection = ""
-				m.form = m.createMenuForm()
-				return m, m.form.Init()
+				return m.back()
 			}
 		}
 	}
@@ -100,126 +225,169 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Check if form is completed
 		if m.form.State == huh.StateCompleted {
-			// Map selection to view
-			switch m.selection {
-			case "Providers":
-				m.currentView = types.ProvidersListView
-				// Initialize provider list form
-				providerForm, err := m.providerComponent.InitListView()
-				if err != nil {
-					log.Printf("Error creating provider form: %v", err)
-					return m, nil
-				}
-				m.form = providerForm
-				return m, m.form.Init()
-			case "Clients":
-				m.currentView = types.ClientsListView
-				clientForm, err := m.clientComponent.InitListView()
-				if err != nil {
-					log.Printf("Error creating client form: %v", err)
-					return m, nil
-				}
-				m.form = clientForm
-				return m, m.form.Init()
-			case "Invoices":
-				m.currentView = types.InvoicesListView
-				// Initialize invoice list form
-				invoiceForm, err := m.invoiceComponent.InitListView()
-				if err != nil {
-					log.Printf("Error creating invoice form: %v", err)
-					return m, nil
-				}
-				m.form = invoiceForm
-				return m, m.form.Init()
+			if entry, ok := m.router.Entry(m.selection); ok {
+				return m.applyTransition(entry.Open())
 			}
 		}
 
 		return m, cmd
 	}
 
-	// Delegate to provider component for provider-related views
-	if m.currentView == types.ProvidersListView ||
-		m.currentView == types.ProviderCreateView ||
-		m.currentView == types.ProviderEditView ||
-		m.currentView == types.ProviderDeleteConfirmView {
-		transition, cmd := m.providerComponent.Update(msg, m.currentView)
+	// Delegate to the component that owns the current view
+	if owner := m.router.Owner(m.currentView); owner != nil {
+		transition, cmd := owner.Update(msg, m.currentView)
 		if transition != nil {
-			m.currentView = transition.NewView
-			m.form = transition.Form
-			return m, cmd
+			return m.applyTransition(transition, cmd)
 		}
 		// Update form reference from component
-		m.form = m.providerComponent.GetForm()
+		m.form = owner.GetForm()
 		return m, cmd
 	}
 
-	// Delegate to client component for client-related views
-	if m.currentView == types.ClientsListView ||
-		m.currentView == types.ClientCreateView ||
-		m.currentView == types.ClientEditView ||
-		m.currentView == types.ClientDeleteConfirmView {
-		transition, cmd := m.clientComponent.Update(msg, m.currentView)
-		if transition != nil {
-			m.currentView = transition.NewView
-			m.form = transition.Form
-			return m, cmd
+	return m, nil
+}
+
+// openSearchResult opens a global search result in the component that owns it
+func (m *model) openSearchResult(msg search.OpenMsg) (*types.ViewTransition, tea.Cmd) {
+	switch msg.Kind {
+	case models.SearchInvoice:
+		invoiceID, err := strconv.Atoi(msg.ID)
+		if err != nil {
+			return nil, status.Err("opening search result", err)
 		}
-		// Update form reference from component
-		m.form = m.clientComponent.GetForm()
+		return m.invoiceComponent.Open(invoiceID, msg.Edit)
+	case models.SearchClient:
+		return m.clientComponent.Edit(msg.ID)
+	case models.SearchProvider:
+		return m.providerComponent.Edit(msg.ID)
+	}
+	return nil, status.Err("opening search result", fmt.Errorf("unknown result kind %q", msg.Kind))
+}
+
+// runCommand runs a command picked in the palette in the component it belongs to
+func (m *model) runCommand(command views.PaletteCommand) (*types.ViewTransition, tea.Cmd) {
+	switch command.Action {
+	case views.PaletteNewInvoice:
+		return m.invoiceComponent.New()
+	case views.PaletteNewClient:
+		return m.clientComponent.New()
+	case views.PaletteNewProvider:
+		return m.providerComponent.New()
+	case views.PaletteOpenClient:
+		return m.clientComponent.Edit(command.ID)
+	case views.PaletteOpenProvider:
+		return m.providerComponent.Edit(command.ID)
+	}
+
+	// The rest act on an invoice
+	invoiceID, err := strconv.Atoi(command.ID)
+	if err != nil {
+		return nil, status.Err("running command", fmt.Errorf("invalid invoice ID %q", command.ID))
+	}
+	switch command.Action {
+	case views.PaletteOpenInvoice:
+		return m.invoiceComponent.Open(invoiceID, false)
+	case views.PaletteMarkPaid:
+		return m.invoiceComponent.MarkPaid(invoiceID)
+	}
+	return nil, status.Err("running command", fmt.Errorf("unknown command %q", command.Action))
+}
+
+// applyTransition switches to the view a component asked for, if any.
+// Moving on to a new view leaves the current one in the history for esc to go back to;
+// moving to a view already in the history, e.g. the list after saving, goes back to it.
+func (m *model) applyTransition(transition *types.ViewTransition, cmd tea.Cmd) (tea.Model, tea.Cmd) {
+	if transition == nil {
 		return m, cmd
 	}
 
-	// Delegate to invoice component for invoice-related views
-	if m.currentView == types.InvoicesListView ||
-		m.currentView == types.InvoiceActionMenuView ||
-		m.currentView == types.InvoiceViewView ||
-		m.currentView == types.InvoiceCreateView ||
-		m.currentView == types.InvoiceEditView {
-		transition, cmd := m.invoiceComponent.Update(msg, m.currentView)
+	if transition.NewView != m.currentView {
+		if i := m.historyIndex(transition.NewView); i >= 0 {
+			m.history = m.history[:i]
+		} else {
+			m.history = append(m.history, navEntry{view: m.currentView, crumb: m.crumb})
+		}
+	}
+
+	m.currentView = transition.NewView
+	m.form = transition.Form
+	m.crumb = transition.Crumb
+	if m.crumb == "" {
+		m.crumb = m.router.Title(transition.NewView)
+	}
+	return m, cmd
+}
+
+// historyIndex returns where view is in the history, or -1 if it isn't
+func (m *model) historyIndex(view types.View) int {
+	for i, entry := range m.history {
+		if entry.view == view {
+			return i
+		}
+	}
+	return -1
+}
+
+// back returns to the previous view, with its selection kept. Views that can't be
+// shown again, such as half-filled forms, are skipped; the menu is always there to return to.
+func (m *model) back() (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+	for len(m.history) > 0 {
+		entry := m.history[len(m.history)-1]
+		m.history = m.history[:len(m.history)-1]
+
+		transition, cmd := m.resume(entry.view)
+		cmds = append(cmds, cmd)
 		if transition != nil {
 			m.currentView = transition.NewView
 			m.form = transition.Form
-			return m, cmd
+			m.crumb = entry.crumb
+			return m, tea.Batch(cmds...)
 		}
-		// Update form reference from component
-		m.form = m.invoiceComponent.GetForm()
-		return m, cmd
 	}
 
-	return m, nil
+	m.history = nil
+	m.currentView = types.MenuView
+	m.form = m.createMenuForm()
+	m.crumb = m.router.Title(types.MenuView)
+	return m, tea.Batch(append(cmds, m.form.Init())...)
+}
+
+// resume asks the component that owns view to show it again
+func (m *model) resume(view types.View) (*types.ViewTransition, tea.Cmd) {
+	if owner := m.router.Owner(view); owner != nil {
+		return owner.Resume(view)
+	}
+	return nil, nil
+}
+
+// breadcrumbs returns the labels of the views esc goes back through, ending with the current one
+func (m *model) breadcrumbs() []string {
+	crumbs := make([]string, 0, len(m.history)+1)
+	for _, entry := range m.history {
+		crumbs = append(crumbs, entry.crumb)
+	}
+	return append(crumbs, m.crumb)
 }
 
 func (m *model) View() string {
-	switch m.currentView {
-	case types.MenuView:
-		return views.RenderMenu(m.form)
-	case types.ProvidersListView:
-		return views.RenderProviders(m.form)
-	case types.ProviderCreateView, types.ProviderEditView:
-		return views.RenderProviders(m.form)
-	case types.ProviderDeleteConfirmView:
-		return views.RenderDeleteConfirm(m.form)
-	case types.ClientsListView:
-		return views.RenderClients(m.form)
-	case types.ClientCreateView, types.ClientEditView:
-		return views.RenderClients(m.form)
-	case types.ClientDeleteConfirmView:
-		return views.RenderDeleteConfirm(m.form)
-	case types.InvoicesListView:
-		return views.RenderInvoices(m.form)
-	case types.InvoiceActionMenuView:
-		return views.RenderInvoiceActionMenu(m.form)
-	case types.InvoiceViewView:
-		invoiceData := m.invoiceComponent.GetInvoiceData()
-		if invoiceData == nil {
-			return "Error: No invoice data available\n\nPress ESC to return"
-		}
-		return views.RenderInvoiceView(invoiceData)
-	case types.InvoiceCreateView, types.InvoiceEditView:
-		return views.RenderInvoices(m.form)
-	default:
-		return "View not implemented yet\n\nPress ESC to return to menu"
+	if m.showHelp {
+		return views.RenderHelp(m.router.Keys(m.currentView), m.width)
+	}
+
+	view := m.renderCurrentView()
+	if m.currentView != types.MenuView {
+		view = views.RenderBreadcrumbs(m.breadcrumbs(), m.width) + "\n" + view
+	}
+	if notice := m.status.View(); notice != "" {
+		view += "\n" + notice
 	}
+	return view
+}
+
+// renderCurrentView renders the active view without the status bar
+func (m *model) renderCurrentView() string {
+	return m.router.Render(m.currentView, m.form, m.width)
 }
 
 func main() {
@@ -229,7 +397,37 @@ func main() {
 	}
 	defer storage.CloseDB()
 
-	p := tea.NewProgram(initialModel())
+	// Subcommands run without starting the TUI
+	if len(os.Args) > 1 {
+		code := cli.Run(os.Args[1:], os.Stdout, os.Stderr)
+		storage.CloseDB()
+		os.Exit(code)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	km, err := keys.New(cfg.Keymap.Preset, cfg.Keymap.Bindings)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	keys.Use(km)
+	th, err := theme.New(cfg.Theme.Base, cfg.Theme.Colors)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	theme.Use(th)
+
+	logFile, err := tea.LogToFile(LogFile, "termsheet")
+	if err != nil {
+		log.Fatalf("Failed to open log file: %v", err)
+	}
+	defer logFile.Close()
+
+	m := initialModel()
+	m.accessible = cfg.AccessibleMode()
+	p := tea.NewProgram(m)
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
 		os.Exit(1)
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EntityType names the kinds of records that can carry custom fields
+type EntityType string
+
+const (
+	EntityClient   EntityType = "client"
+	EntityProvider EntityType = "provider"
+	EntityInvoice  EntityType = "invoice"
+)
+
+// EntityTypes lists every entity type that supports custom fields
+var EntityTypes = []EntityType{EntityClient, EntityProvider, EntityInvoice}
+
+// CustomFieldKind is the type of value a custom field holds
+type CustomFieldKind string
+
+const (
+	FieldText   CustomFieldKind = "text"
+	FieldNumber CustomFieldKind = "number"
+	// FieldDate values are stored as YYYY-MM-DD
+	FieldDate   CustomFieldKind = "date"
+	FieldSelect CustomFieldKind = "select"
+)
+
+// CustomFieldKinds lists every supported custom field kind
+var CustomFieldKinds = []CustomFieldKind{FieldText, FieldNumber, FieldDate, FieldSelect}
+
+// CustomFieldDateLayout is the layout of date custom field values
+const CustomFieldDateLayout = "2006-01-02"
+
+// customFieldKey matches keys usable as template variable names, e.g. "po_number"
+var customFieldKey = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// reservedFieldKeys are used by the built-in template variables
+var reservedFieldKeys = []string{"name", "number", "date"}
+
+// CustomField is a user-defined field on clients, providers or invoices,
+// such as a PO number or cost centre
+type CustomField struct {
+	ID         int
+	EntityType EntityType
+	// Key identifies the field in templates and exports
+	Key   string
+	Label string
+	Kind  CustomFieldKind
+	// Options are the allowed values of a select field
+	Options []string
+}
+
+// Validate checks the field definition itself
+func (f CustomField) Validate() error {
+	if !slices.Contains(EntityTypes, f.EntityType) {
+		return fmt.Errorf("unknown entity type %q", f.EntityType)
+	}
+	if !customFieldKey.MatchString(f.Key) {
+		return fmt.Errorf("invalid field key %q: use lowercase letters, digits and underscores", f.Key)
+	}
+	if slices.Contains(reservedFieldKeys, f.Key) {
+		return fmt.Errorf("field key %q is reserved", f.Key)
+	}
+	if strings.TrimSpace(f.Label) == "" {
+		return fmt.Errorf("field %s needs a label", f.Key)
+	}
+	if !slices.Contains(CustomFieldKinds, f.Kind) {
+		return fmt.Errorf("unknown field kind %q", f.Kind)
+	}
+	if f.Kind == FieldSelect && len(f.Options) == 0 {
+		return fmt.Errorf("select field %s needs at least one option", f.Key)
+	}
+	return nil
+}
+
+// ValidateValue checks that value fits the field's kind. Empty values are always valid.
+func (f CustomField) ValidateValue(value string) error {
+	if value == "" {
+		return nil
+	}
+
+	switch f.Kind {
+	case FieldNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%s must be a number", f.Label)
+		}
+	case FieldDate:
+		if _, err := time.Parse(CustomFieldDateLayout, value); err != nil {
+			return fmt.Errorf("%s must be a date like 2026-01-31", f.Label)
+		}
+	case FieldSelect:
+		if !slices.Contains(f.Options, value) {
+			return fmt.Errorf("%s must be one of %s", f.Label, strings.Join(f.Options, ", "))
+		}
+	}
+	return nil
+}
+
+// CustomFieldValue is a custom field together with the value set on one record
+type CustomFieldValue struct {
+	Field CustomField
+	Value string
+}
@@ -1,7 +1,13 @@
 // Package models defines the core data structures for invoicing entities.
 package models
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/GVPproj/termsheet/numbering"
+)
 
 // Entity represents a generic contact entity (client or provider)
 type Entity struct {
@@ -13,6 +19,163 @@ type Entity struct {
 	Address *string
 	Email   *string
 	Phone   *string
+	// Archived entities are hidden from lists and selections by default
+	// but keep their invoices
+	Archived bool
+	// Tags are only used on clients
+	Tags []string
+}
+
+// Address is a structured postal address
+type Address struct {
+	Line1      string
+	Line2      string
+	City       string
+	Region     string
+	PostalCode string
+	Country    string
+}
+
+// IsEmpty reports whether no address field is set
+func (a Address) IsEmpty() bool {
+	return a == Address{}
+}
+
+// Lines returns the address formatted for printing, one line per entry,
+// e.g. "1 Main St", "Springfield, IL 62701", "United States"
+func (a Address) Lines() []string {
+	var lines []string
+	for _, line := range []string{a.Line1, a.Line2} {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	locality := a.City
+	if a.Region != "" {
+		locality = joinNonEmpty(", ", locality, a.Region)
+	}
+	locality = joinNonEmpty(" ", locality, a.PostalCode)
+	if locality != "" {
+		lines = append(lines, locality)
+	}
+
+	if a.Country != "" {
+		lines = append(lines, a.Country)
+	}
+	return lines
+}
+
+// String returns the address on a single line
+func (a Address) String() string {
+	return strings.Join(a.Lines(), ", ")
+}
+
+// Contact is a named person at a client
+type Contact struct {
+	ID       int
+	ClientID string
+	Name     string
+	Email    string
+	Phone    string
+	// Billing marks the contact whose email invoices are sent to; a client has at most one
+	Billing bool
+}
+
+// ClientDetails holds a client's structured addresses and contacts
+type ClientDetails struct {
+	BillingAddress Address
+	// ShippingAddress is empty when the client ships to its billing address
+	ShippingAddress Address
+	Contacts        []Contact
+}
+
+// BillingContact returns the designated billing contact, or nil if there is none
+func (d ClientDetails) BillingContact() *Contact {
+	for i := range d.Contacts {
+		if d.Contacts[i].Billing {
+			return &d.Contacts[i]
+		}
+	}
+	return nil
+}
+
+// InvoiceEmail returns the address invoices for the client should be sent to:
+// the billing contact's email, falling back to the client's own email
+func (d ClientDetails) InvoiceEmail(client Entity) string {
+	if contact := d.BillingContact(); contact != nil && contact.Email != "" {
+		return contact.Email
+	}
+	if client.Email != nil {
+		return *client.Email
+	}
+	return ""
+}
+
+// joinNonEmpty joins the non-empty parts with sep
+func joinNonEmpty(sep string, parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, sep)
+}
+
+// NumberingScheme is a provider's invoice numbering configuration.
+// An empty Format means numbering.DefaultFormat.
+type NumberingScheme struct {
+	Format string
+	Reset  numbering.Reset
+}
+
+// ClientDraft is everything entered in the client form, saved together by storage.SaveClient
+type ClientDraft struct {
+	// ID is the client being edited, or "" for a new client
+	ID      string
+	Name    string
+	Email   *string
+	Phone   *string
+	Details ClientDetails
+	Tags    []string
+	// CustomValues are the client's custom field values by field key
+	CustomValues map[string]string
+}
+
+// ProviderDraft is everything entered in the provider form, saved together by storage.SaveProvider
+type ProviderDraft struct {
+	// ID is the provider being edited, or "" for a new provider
+	ID      string
+	Name    string
+	Address *string
+	Email   *string
+	Phone   *string
+	Profile ProviderProfile
+	// Numbering is the provider's invoice numbering scheme
+	Numbering NumberingScheme
+	// CustomValues are the provider's custom field values by field key
+	CustomValues map[string]string
+}
+
+// ProviderProfile holds the business details a provider needs to issue legal invoices.
+// Empty fields are left off the invoice.
+type ProviderProfile struct {
+	// LegalName is the registered business name, if it differs from the display name
+	LegalName           string
+	TaxID               string
+	CompanyNumber       string
+	Website             string
+	LogoPath            string
+	IBAN                string
+	BIC                 string
+	AccountDetails      string
+	PaymentInstructions string
+}
+
+// HasPaymentDetails reports whether any bank or payment information is set
+func (p ProviderProfile) HasPaymentDetails() bool {
+	return p.IBAN != "" || p.BIC != "" || p.AccountDetails != "" || p.PaymentInstructions != ""
 }
 
 type Invoice struct {
@@ -23,20 +186,158 @@ type Invoice struct {
 	DateCreated time.Time
 }
 
+// InvoiceDraft is everything entered in the invoice form, saved together by storage.SaveInvoice
+type InvoiceDraft struct {
+	// ID is the invoice being edited, or 0 for a new invoice
+	ID         int
+	ProviderID string
+	ClientID   string
+	Paid       bool
+	Items      []InvoiceItem
+	Tags       []string
+	// Notes is free text printed at the end of the invoice
+	Notes string
+	// Terms are the payment terms printed on the invoice
+	Terms string
+	// DueDate is when payment is due, or nil for no due date
+	DueDate *time.Time
+	// CustomValues are the invoice's custom field values by field key
+	CustomValues map[string]string
+}
+
 type InvoiceItem struct {
 	ID          int
 	InvoiceID   int
 	ItemName    string
 	Amount      float64
 	CostPerUnit float64
+	// Position orders the items of an invoice, from 0
+	Position int
+}
+
+// InvoiceSummary is a row in the invoice list, joined with provider and client names
+type InvoiceSummary struct {
+	ID           int
+	ProviderName string
+	ClientName   string
+	DateCreated  time.Time
+	Paid         bool
+	// DeletedAt is set when the invoice has been moved to the trash
+	DeletedAt *time.Time
+	// Number is the human invoice number, assigned when the invoice is issued
+	Number   string
+	IssuedAt *time.Time
+	// DueDate is when payment is due, if the invoice has a due date
+	DueDate *time.Time
+	// Total is the sum of the invoice's items
+	Total float64
+	Tags  []string
+}
+
+// DisplayNumber returns the invoice number shown to users
+func (s InvoiceSummary) DisplayNumber() string {
+	return displayNumber(s.ID, s.Number)
+}
+
+// IsDraft reports whether the invoice has not been issued yet
+func (s InvoiceSummary) IsDraft() bool {
+	return s.IssuedAt == nil
+}
+
+// Status returns where the invoice is in its lifecycle
+func (s InvoiceSummary) Status() InvoiceStatus {
+	switch {
+	case s.Paid:
+		return StatusPaid
+	case s.IsDraft():
+		return StatusDraft
+	}
+	return StatusIssued
 }
 
+// InvoiceStatus is where an invoice is in its lifecycle: a draft, issued and awaiting payment, or paid
+type InvoiceStatus string
+
+const (
+	StatusDraft  InvoiceStatus = "draft"
+	StatusIssued InvoiceStatus = "issued"
+	StatusPaid   InvoiceStatus = "paid"
+)
+
+// InvoiceStatuses lists every invoice status in lifecycle order
+var InvoiceStatuses = []InvoiceStatus{StatusDraft, StatusIssued, StatusPaid}
+
 // InvoiceData contains complete invoice information including provider and client details
 type InvoiceData struct {
 	InvoiceID   int
+	Number      string
 	DateCreated time.Time
-	Paid        bool
-	Provider    Entity
-	Client      Entity
-	Items       []InvoiceItem
+	IssuedAt    *time.Time
+	// DueDate is when payment is due, if the invoice has a due date
+	DueDate  *time.Time
+	Paid     bool
+	Provider Entity
+	// ProviderProfile holds the provider's business and payment details
+	ProviderProfile ProviderProfile
+	Client          Entity
+	// ClientDetails holds the client's addresses and billing contact
+	ClientDetails ClientDetails
+	Items         []InvoiceItem
+	Tags          []string
+	// Notes is free text printed at the end of the invoice
+	Notes string
+	// Terms are the payment terms printed on the invoice, e.g. "Net 30"
+	Terms string
+	// CustomFields holds the non-empty custom field values of the invoice,
+	// its provider and its client
+	CustomFields []CustomFieldValue
+}
+
+// DisplayNumber returns the invoice number shown to users
+func (d *InvoiceData) DisplayNumber() string {
+	return displayNumber(d.InvoiceID, d.Number)
+}
+
+// IsDraft reports whether the invoice has not been issued yet
+func (d *InvoiceData) IsDraft() bool {
+	return d.IssuedAt == nil
+}
+
+// CustomFieldsFor returns the invoice's custom field values that belong to the given entity type
+func (d *InvoiceData) CustomFieldsFor(entityType EntityType) []CustomFieldValue {
+	var values []CustomFieldValue
+	for _, v := range d.CustomFields {
+		if v.Field.EntityType == entityType {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// TemplateVariables returns the invoice's values by variable name for use in
+// exports and templates, e.g. "invoice.number", "client.name" and, for custom
+// fields, "<entity type>.<field key>" such as "client.po_number"
+func (d *InvoiceData) TemplateVariables() map[string]string {
+	vars := map[string]string{
+		"invoice.number": d.DisplayNumber(),
+		"invoice.date":   d.DateCreated.Format(CustomFieldDateLayout),
+		"provider.name":  d.Provider.Name,
+		"client.name":    d.Client.Name,
+	}
+	if d.IssuedAt != nil {
+		vars["invoice.date"] = d.IssuedAt.Format(CustomFieldDateLayout)
+	}
+	for _, v := range d.CustomFields {
+		vars[string(v.Field.EntityType)+"."+v.Field.Key] = v.Value
+	}
+	return vars
+}
+
+// displayNumber falls back to the internal ID for drafts and for invoices
+// issued before numbering schemes existed
+func displayNumber(id int, number string) string {
+	if number != "" {
+		return number
+	}
+	return fmt.Sprintf("#%d", id)
 }
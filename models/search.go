@@ -0,0 +1,54 @@
+package models
+
+// SearchKind is the kind of record a search result points at
+type SearchKind string
+
+const (
+	SearchInvoice  SearchKind = "invoice"
+	SearchItem     SearchKind = "item"
+	SearchClient   SearchKind = "client"
+	SearchProvider SearchKind = "provider"
+	SearchContact  SearchKind = "contact"
+)
+
+// SearchResult is a match from the global search
+type SearchResult struct {
+	Kind SearchKind
+	// Title is the matched record's name, e.g. the item name or client name
+	Title string
+	// Snippet is an excerpt of the other matched text such as invoice notes or email addresses
+	Snippet string
+
+	// InvoiceID is set for invoice and item results, with the invoice's number if it has been issued
+	InvoiceID     int
+	InvoiceNumber string
+	// EntityID is the client ID for client and contact results and the provider ID for provider results
+	EntityID string
+	// ClientName is the invoice's client for invoice and item results, and the contact's client for contacts
+	ClientName string
+}
+
+// DisplayNumber returns the number users know the result's invoice by
+func (r SearchResult) DisplayNumber() string {
+	return displayNumber(r.InvoiceID, r.InvoiceNumber)
+}
+
+// RecentEntity is an invoice, client or provider the user opened recently
+type RecentEntity struct {
+	// Kind is SearchInvoice, SearchClient or SearchProvider
+	Kind SearchKind
+	// InvoiceID is set for invoices, with the invoice's number if it has been issued
+	// and whether it has been paid
+	InvoiceID     int
+	InvoiceNumber string
+	Paid          bool
+	// EntityID is set for clients and providers
+	EntityID string
+	// Name is the client or provider's name, or the invoice's client
+	Name string
+}
+
+// DisplayNumber returns the number users know the recent invoice by
+func (r RecentEntity) DisplayNumber() string {
+	return displayNumber(r.InvoiceID, r.InvoiceNumber)
+}
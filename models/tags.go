@@ -0,0 +1,42 @@
+package models
+
+import (
+	"strings"
+)
+
+// Tag is a label such as "agency" or "2026-Q3" attached to clients and invoices
+type Tag struct {
+	Name string
+	// Count is the number of clients and invoices carrying the tag
+	Count int
+}
+
+// ParseTags splits comma-separated tag input such as "agency, #retainer" into
+// tag names. Leading '#' is dropped and duplicates are removed ignoring case.
+func ParseTags(s string) []string {
+	var tags []string
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ",") {
+		name := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(part), "#"))
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		tags = append(tags, name)
+	}
+	return tags
+}
+
+// FormatTags joins tag names back into the comma-separated form read by ParseTags
+func FormatTags(tags []string) string {
+	return strings.Join(tags, ", ")
+}
+
+// TagChips renders tags as "#agency #retainer" for list labels
+func TagChips(tags []string) string {
+	chips := make([]string, len(tags))
+	for i, t := range tags {
+		chips[i] = "#" + t
+	}
+	return strings.Join(chips, " ")
+}
@@ -0,0 +1,174 @@
+// Package numbering formats human-readable invoice numbers from provider-defined patterns.
+//
+// A pattern is literal text with placeholders in braces:
+//
+//	{YYYY}    four-digit year the invoice was issued
+//	{YY}      two-digit year
+//	{MM}      two-digit month
+//	{seq}     sequence number within the numbering period
+//	{seq:04}  sequence number zero-padded to the given width
+//
+// For example "ACME-{YYYY}-{seq:04}" produces "ACME-2026-0042".
+package numbering
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reset controls when the sequence number starts again from 1
+type Reset string
+
+const (
+	ResetNever  Reset = "never"
+	ResetYearly Reset = "yearly"
+)
+
+// DefaultFormat is used for providers that haven't configured a pattern
+const DefaultFormat = "INV-{seq:04}"
+
+// Validate checks that format is a usable pattern: placeholders must be known
+// and it must contain a sequence placeholder so numbers are unique
+func Validate(format string) error {
+	if strings.TrimSpace(format) == "" {
+		return errors.New("number format is required")
+	}
+
+	hasSeq := false
+	err := walk(format, func(literal string) {}, func(token string) error {
+		if token == "seq" || strings.HasPrefix(token, "seq:") {
+			hasSeq = true
+		}
+		_, err := expand(token, time.Time{}, 0)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if !hasSeq {
+		return errors.New("number format must contain {seq}")
+	}
+	return nil
+}
+
+// ValidateReset checks that reset is a known reset policy
+func ValidateReset(reset Reset) error {
+	switch reset {
+	case ResetNever, ResetYearly:
+		return nil
+	}
+	return fmt.Errorf("unknown reset policy %q", reset)
+}
+
+// ValidateScheme checks a format and reset policy together; an empty format stands for
+// DefaultFormat. Sequences restarting every year repeat their numbers, so a yearly reset
+// needs the year in the format to keep numbers unique.
+func ValidateScheme(format string, reset Reset) error {
+	if format == "" {
+		format = DefaultFormat
+	}
+	if err := Validate(format); err != nil {
+		return err
+	}
+	if err := ValidateReset(reset); err != nil {
+		return err
+	}
+
+	if reset == ResetYearly {
+		hasYear := false
+		_ = walk(format, func(string) {}, func(token string) error {
+			if token == "YYYY" || token == "YY" {
+				hasYear = true
+			}
+			return nil
+		})
+		if !hasYear {
+			return errors.New("numbers restarting every year must contain {YYYY} or {YY}, or they would repeat")
+		}
+	}
+	return nil
+}
+
+// Format renders the invoice number for sequence seq issued at issued
+func Format(format string, issued time.Time, seq int) (string, error) {
+	var b strings.Builder
+	err := walk(format, func(literal string) {
+		b.WriteString(literal)
+	}, func(token string) error {
+		value, err := expand(token, issued, seq)
+		if err != nil {
+			return err
+		}
+		b.WriteString(value)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Period returns the numbering period an invoice issued at issued falls into.
+// Sequences are counted separately per period: the year for yearly resets,
+// and a single period 0 when numbers never reset.
+func Period(reset Reset, issued time.Time) int {
+	if reset == ResetYearly {
+		return issued.Year()
+	}
+	return 0
+}
+
+// walk splits format into literal text and {placeholder} tokens
+func walk(format string, literal func(string), token func(string) error) error {
+	for format != "" {
+		open := strings.IndexByte(format, '{')
+		if open < 0 {
+			if strings.IndexByte(format, '}') >= 0 {
+				return errors.New("unmatched '}' in number format")
+			}
+			literal(format)
+			return nil
+		}
+		if strings.IndexByte(format[:open], '}') >= 0 {
+			return errors.New("unmatched '}' in number format")
+		}
+		literal(format[:open])
+
+		end := strings.IndexByte(format[open:], '}')
+		if end < 0 {
+			return errors.New("unclosed '{' in number format")
+		}
+		if err := token(format[open+1 : open+end]); err != nil {
+			return err
+		}
+		format = format[open+end+1:]
+	}
+	return nil
+}
+
+// expand returns the value of a single placeholder
+func expand(token string, issued time.Time, seq int) (string, error) {
+	switch token {
+	case "YYYY":
+		return fmt.Sprintf("%04d", issued.Year()), nil
+	case "YY":
+		return fmt.Sprintf("%02d", issued.Year()%100), nil
+	case "MM":
+		return fmt.Sprintf("%02d", int(issued.Month())), nil
+	case "seq":
+		return strconv.Itoa(seq), nil
+	}
+
+	if width, ok := strings.CutPrefix(token, "seq:"); ok {
+		n, err := strconv.Atoi(width)
+		if err != nil || n < 1 || n > 12 {
+			return "", fmt.Errorf("invalid sequence width in {%s}", token)
+		}
+		return fmt.Sprintf("%0*d", n, seq), nil
+	}
+
+	return "", fmt.Errorf("unknown placeholder {%s}", token)
+}
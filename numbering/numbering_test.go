@@ -0,0 +1,98 @@
+package numbering
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormat(t *testing.T) {
+	issued := time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		format   string
+		seq      int
+		expected string
+	}{
+		{"default format", DefaultFormat, 7, "INV-0007"},
+		{"year and padded sequence", "ACME-{YYYY}-{seq:04}", 42, "ACME-2026-0042"},
+		{"short year and month", "{YY}{MM}-{seq}", 3, "2603-3"},
+		{"sequence wider than padding", "{seq:02}", 1234, "1234"},
+		{"plain sequence", "{seq}", 1, "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Format(tt.format, issued, tt.seq)
+			if err != nil {
+				t.Fatalf("Format(%q) failed: %v", tt.format, err)
+			}
+			if result != tt.expected {
+				t.Errorf("Format(%q, %d) = %q, want %q", tt.format, tt.seq, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		wantErr bool
+	}{
+		{"valid default", DefaultFormat, false},
+		{"valid with year", "ACME-{YYYY}-{seq:04}", false},
+		{"empty", "", true},
+		{"missing sequence", "ACME-{YYYY}", true},
+		{"unknown placeholder", "{DD}-{seq}", true},
+		{"bad width", "{seq:abc}", true},
+		{"unclosed brace", "INV-{seq", true},
+		{"stray closing brace", "INV}-{seq}", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%q) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateScheme(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		reset   Reset
+		wantErr bool
+	}{
+		{"default never resets", "", ResetNever, false},
+		{"default yearly", "", ResetYearly, true},
+		{"no year yearly", "INV-{MM}-{seq}", ResetYearly, true},
+		{"four-digit year yearly", "{YYYY}/{seq}", ResetYearly, false},
+		{"two-digit year yearly", "INV{YY}-{seq:03}", ResetYearly, false},
+		{"bad format", "{seq", ResetNever, true},
+		{"unknown reset", DefaultFormat, Reset("monthly"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateScheme(tt.format, tt.reset)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateScheme(%q, %q) error = %v, wantErr %v", tt.format, tt.reset, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPeriod(t *testing.T) {
+	issued := time.Date(2026, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+	if p := Period(ResetNever, issued); p != 0 {
+		t.Errorf("expected period 0 for never reset, got %d", p)
+	}
+	if p := Period(ResetYearly, issued); p != 2026 {
+		t.Errorf("expected period 2026 for yearly reset, got %d", p)
+	}
+}
@@ -0,0 +1,252 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// benchmarkInvoices is how many invoices the invoice list benchmarks run against
+const benchmarkInvoices = 100_000
+
+// Budgets for opening the invoice list, i.e. counting the invoices and loading the first page.
+// They hold however many invoices there are, since pages are fetched by keyset.
+const (
+	listOpenTimeBudget   = 50 * time.Millisecond
+	listOpenMemoryBudget = 1 << 20
+	listPageSize         = 100
+)
+
+// listScanTimeBudget is the time budget for opening the invoice list in the ways that read
+// every invoice, which are exempt from listOpenTimeBudget:
+//   - sorting by any column but the date, since only idx_invoice_date_created orders invoices;
+//     the client and provider names are joined and the totals and statuses are computed
+//   - searching, since text is matched anywhere in numbers and names, which no index covers
+//
+// Their time grows with the number of invoices, but their memory doesn't, so they are
+// still held to listOpenMemoryBudget.
+const listScanTimeBudget = 500 * time.Millisecond
+
+var (
+	benchmarkDBOnce sync.Once
+	benchmarkDB     *sql.DB
+)
+
+// setupBenchmarkDB points the package at an in-memory database seeded with
+// benchmarkInvoices invoices, seeding it on first use
+func setupBenchmarkDB(b testing.TB) {
+	b.Helper()
+	benchmarkDBOnce.Do(func() {
+		setupTestDB(b)
+		start := time.Now()
+		if err := seedInvoices(benchmarkInvoices); err != nil {
+			b.Fatalf("failed to seed invoices: %v", err)
+		}
+		b.Logf("seeded %d invoices in %v", benchmarkInvoices, time.Since(start))
+		benchmarkDB = db
+	})
+	if benchmarkDB == nil {
+		b.Fatal("benchmark database failed to seed")
+	}
+	db = benchmarkDB
+}
+
+// seedInvoices adds n invoices with one item each, spread over 20 clients and a few years.
+// Every third invoice is a draft and every other one is paid.
+func seedInvoices(n int) error {
+	providerID, err := CreateProvider("Benchmark Provider", nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	clients := make([]string, 20)
+	for i := range clients {
+		if clients[i], err = CreateClient(fmt.Sprintf("Client %02d", i), nil, nil, nil); err != nil {
+			return err
+		}
+	}
+
+	// Indexing every row as it is inserted would dominate the time taken;
+	// the search index is rebuilt in one go afterwards instead
+	var searchTriggers []string
+	for _, source := range searchSources {
+		searchTriggers = append(searchTriggers, source.triggers()...)
+		for _, event := range []string{"insert", "update", "delete"} {
+			if _, err := db.Exec(fmt.Sprintf("DROP TRIGGER %s_search_%s", source.table, event)); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Generated in SQL, since inserting row by row through the driver takes far longer
+	_, err = db.Exec(`
+		WITH RECURSIVE
+			seq(n) AS (SELECT 0 UNION ALL SELECT n + 1 FROM seq WHERE n < ? - 1),
+			clients(id, k) AS (SELECT id, row_number() OVER (ORDER BY rowid) - 1 FROM client)
+		INSERT INTO invoice (provider_id, client_id, paid, date_created, number, issued_at)
+		SELECT
+			?,
+			clients.id,
+			n % 2 = 0,
+			datetime('2020-01-01 09:00:00', '+' || (n * 20) || ' minutes'),
+			CASE WHEN n % 3 != 0 THEN printf('INV-%06d', n) END,
+			CASE WHEN n % 3 != 0 THEN datetime('2020-01-01 09:00:00', '+' || (n * 20) || ' minutes') END
+		FROM seq JOIN clients ON clients.k = n % ?
+		ORDER BY n
+	`, n, providerID, len(clients))
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+		INSERT INTO invoice_item (invoice_id, item_name, amount, cost_per_unit)
+		SELECT id, 'Consulting ' || id, 1 + id % 10, 75 FROM invoice
+	`)
+	if err != nil {
+		return err
+	}
+
+	if err := rebuildSearchIndex(db); err != nil {
+		return err
+	}
+	for _, trigger := range searchTriggers {
+		if _, err := db.Exec(trigger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openInvoiceList does what opening the invoice list does: count the invoices and load the first page
+func openInvoiceList(b testing.TB, filter InvoiceFilter, sort InvoiceSort) {
+	count, err := CountInvoices(filter)
+	if err != nil {
+		b.Fatalf("CountInvoices failed: %v", err)
+	}
+	page, err := ListInvoicesPage(filter, sort, PageCursor{}, listPageSize)
+	if err != nil {
+		b.Fatalf("ListInvoicesPage failed: %v", err)
+	}
+	if count == 0 || len(page) == 0 {
+		b.Fatal("expected invoices")
+	}
+}
+
+// checkBudget fails the benchmark if each iteration since before took longer or allocated more than allowed
+func checkBudget(b *testing.B, before *runtime.MemStats, timeBudget time.Duration, memoryBudget uint64) {
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+
+	allocated := (after.TotalAlloc - before.TotalAlloc) / uint64(b.N)
+	b.ReportMetric(float64(allocated), "alloc-bytes/op")
+	checkListBudget(b, b.Elapsed()/time.Duration(b.N), allocated, timeBudget, memoryBudget)
+}
+
+// checkListBudget fails tb if perOp or allocated exceed their budgets
+func checkListBudget(tb testing.TB, perOp time.Duration, allocated uint64, timeBudget time.Duration, memoryBudget uint64) {
+	tb.Helper()
+	if perOp > timeBudget {
+		tb.Errorf("took %v per iteration, budget is %v", perOp, timeBudget)
+	}
+	if allocated > memoryBudget {
+		tb.Errorf("allocated %d bytes per iteration, budget is %d", allocated, memoryBudget)
+	}
+}
+
+// TestInvoiceListBudget holds opening the invoice list to the same budgets as the benchmarks,
+// so that a regression fails the tests. -short skips it, since seeding takes a few seconds.
+func TestInvoiceListBudget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("seeding the invoice list budget database is slow")
+	}
+	setupBenchmarkDB(t)
+
+	tests := []struct {
+		name       string
+		filter     InvoiceFilter
+		sort       InvoiceSort
+		timeBudget time.Duration
+	}{
+		{"by date", InvoiceFilter{}, InvoiceSort{}, listOpenTimeBudget},
+		{"by client", InvoiceFilter{}, InvoiceSort{Column: SortByClient}, listScanTimeBudget},
+		{"searched", InvoiceFilter{Search: "Client 07"}, InvoiceSort{}, listScanTimeBudget},
+	}
+
+	const runs = 5
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The first run warms the page cache, as using the list would
+			openInvoiceList(t, tt.filter, tt.sort)
+
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			start := time.Now()
+			for range runs {
+				openInvoiceList(t, tt.filter, tt.sort)
+			}
+			perOp := time.Since(start) / runs
+			runtime.ReadMemStats(&after)
+
+			checkListBudget(t, perOp, (after.TotalAlloc-before.TotalAlloc)/runs, tt.timeBudget, listOpenMemoryBudget)
+		})
+	}
+}
+
+func BenchmarkOpenInvoiceList(b *testing.B) {
+	setupBenchmarkDB(b)
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	for b.Loop() {
+		openInvoiceList(b, InvoiceFilter{}, InvoiceSort{})
+	}
+
+	checkBudget(b, &before, listOpenTimeBudget, listOpenMemoryBudget)
+}
+
+// BenchmarkInvoiceListDeepPage loads a page from the middle of the list, which
+// costs the same as the first page since it is found by keyset rather than offset
+func BenchmarkInvoiceListDeepPage(b *testing.B) {
+	setupBenchmarkDB(b)
+	cursor := PageCursor{ID: strconv.Itoa(benchmarkInvoices / 2)}
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	for b.Loop() {
+		if _, err := ListInvoicesPage(InvoiceFilter{}, InvoiceSort{}, cursor, listPageSize); err != nil {
+			b.Fatalf("ListInvoicesPage failed: %v", err)
+		}
+	}
+
+	checkBudget(b, &before, listOpenTimeBudget, listOpenMemoryBudget)
+}
+
+// BenchmarkOpenInvoiceListSortedByClient opens the list in an order no index covers,
+// so it is held to listScanTimeBudget
+func BenchmarkOpenInvoiceListSortedByClient(b *testing.B) {
+	setupBenchmarkDB(b)
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	for b.Loop() {
+		openInvoiceList(b, InvoiceFilter{}, InvoiceSort{Column: SortByClient})
+	}
+
+	checkBudget(b, &before, listScanTimeBudget, listOpenMemoryBudget)
+}
+
+// BenchmarkOpenInvoiceListFiltered opens the list searched by a client name, which
+// reads every invoice to count the matches, so it is held to listScanTimeBudget
+func BenchmarkOpenInvoiceListFiltered(b *testing.B) {
+	setupBenchmarkDB(b)
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	for b.Loop() {
+		openInvoiceList(b, InvoiceFilter{Search: "Client 07"}, InvoiceSort{})
+	}
+
+	checkBudget(b, &before, listScanTimeBudget, listOpenMemoryBudget)
+}
@@ -8,8 +8,18 @@ func CreateClient(name string, address, email, phone *string) (string, error) {
 	return CreateEntity("client", name, address, email, phone)
 }
 
+// ListClients returns the clients that are not archived
 func ListClients() ([]models.Entity, error) {
-	return ListEntities("client")
+	return ListEntities("client", false)
+}
+
+// ListAllClients returns every client, including archived ones
+func ListAllClients() ([]models.Entity, error) {
+	return ListEntities("client", true)
+}
+
+func GetClient(clientID string) (*models.Entity, error) {
+	return GetEntity("client", clientID)
 }
 
 func UpdateClient(clientID, name string, address, email, phone *string) error {
@@ -19,3 +29,11 @@ func UpdateClient(clientID, name string, address, email, phone *string) error {
 func DeleteClient(clientID string) error {
 	return DeleteEntity("client", clientID)
 }
+
+func ArchiveClient(clientID string) error {
+	return ArchiveEntity("client", clientID)
+}
+
+func RestoreClient(clientID string) error {
+	return RestoreEntity("client", clientID)
+}
@@ -0,0 +1,288 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/GVPproj/termsheet/models"
+	"github.com/GVPproj/termsheet/validation"
+	"github.com/google/uuid"
+)
+
+// Address kinds stored in client_address
+const (
+	billingAddress  = "billing"
+	shippingAddress = "shipping"
+)
+
+// CreateClientWithDetails creates a client together with its addresses and contacts
+// in a single transaction. The client's address column holds the billing address
+// on one line so list views and older code keep working.
+func CreateClientWithDetails(name string, email, phone *string, details models.ClientDetails) (string, error) {
+	return saveClient(models.ClientDraft{Name: name, Email: email, Phone: phone, Details: details}, nil)
+}
+
+// UpdateClientWithDetails updates a client and replaces its addresses and contacts
+// in a single transaction. Contacts keep their IDs when they are passed back unchanged.
+func UpdateClientWithDetails(clientID, name string, email, phone *string, details models.ClientDetails) error {
+	_, err := saveClient(models.ClientDraft{ID: clientID, Name: name, Email: email, Phone: phone, Details: details}, nil)
+	return err
+}
+
+// SaveClient creates (draft.ID == "") or updates a client with everything entered in the
+// client form: its addresses, contacts, tags and custom field values. It all runs in a
+// single transaction, so a failed save changes nothing. Returns the client's ID.
+func SaveClient(draft models.ClientDraft) (string, error) {
+	fields, err := checkCustomValues(models.EntityClient, draft.CustomValues)
+	if err != nil {
+		return "", err
+	}
+
+	return saveClient(draft, func(tx *sql.Tx, clientID string) error {
+		if err := setTags(tx, clientTags, clientID, draft.Tags); err != nil {
+			return err
+		}
+		return setCustomValues(tx, fields, clientID, draft.CustomValues)
+	})
+}
+
+// saveClient creates or updates a client with its addresses and contacts in a single
+// transaction, running extra, if not nil, in the same transaction to save the rest.
+// Returns the client's ID.
+func saveClient(draft models.ClientDraft, extra func(tx *sql.Tx, clientID string) error) (string, error) {
+	if strings.TrimSpace(draft.Name) == "" {
+		return "", errors.New("client name is required")
+	}
+	email, phone, err := normalizeContactDetails(draft.Email, draft.Phone)
+	if err != nil {
+		return "", err
+	}
+	details, err := normalizeClientDetails(draft.Details)
+	if err != nil {
+		return "", err
+	}
+
+	clientID := draft.ID
+	if clientID == "" {
+		clientID = uuid.New().String()
+	}
+	err = withTx(func(tx *sql.Tx) error {
+		var err error
+		if draft.ID == "" {
+			_, err = tx.Exec(
+				"INSERT INTO client (id, name, address, email, phone) VALUES (?, ?, ?, ?, ?)",
+				clientID,
+				strings.TrimSpace(draft.Name),
+				nullIfEmpty(details.BillingAddress.String()),
+				email,
+				phone,
+			)
+		} else {
+			err = execExpectingRow(tx,
+				"UPDATE client SET name = ?, address = ?, email = ?, phone = ? WHERE id = ?",
+				strings.TrimSpace(draft.Name),
+				nullIfEmpty(details.BillingAddress.String()),
+				email,
+				phone,
+				clientID,
+			)
+		}
+		if err != nil {
+			return err
+		}
+		if err := saveClientDetails(tx, clientID, details); err != nil {
+			return err
+		}
+		if extra != nil {
+			return extra(tx, clientID)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return clientID, nil
+}
+
+// GetClientDetails returns a client's addresses and contacts, billing contact first
+func GetClientDetails(clientID string) (models.ClientDetails, error) {
+	var details models.ClientDetails
+
+	rows, err := db.Query(`
+		SELECT kind, COALESCE(line1, ''), COALESCE(line2, ''), COALESCE(city, ''),
+			COALESCE(region, ''), COALESCE(postal_code, ''), COALESCE(country, '')
+		FROM client_address
+		WHERE client_id = ?
+	`, clientID)
+	if err != nil {
+		return details, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var kind string
+		var a models.Address
+		if err := rows.Scan(&kind, &a.Line1, &a.Line2, &a.City, &a.Region, &a.PostalCode, &a.Country); err != nil {
+			return details, err
+		}
+		switch kind {
+		case billingAddress:
+			details.BillingAddress = a
+		case shippingAddress:
+			details.ShippingAddress = a
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return details, err
+	}
+
+	contactRows, err := db.Query(`
+		SELECT id, client_id, name, COALESCE(email, ''), COALESCE(phone, ''), is_billing
+		FROM client_contact
+		WHERE client_id = ?
+		ORDER BY is_billing DESC, id
+	`, clientID)
+	if err != nil {
+		return details, err
+	}
+	defer contactRows.Close()
+
+	for contactRows.Next() {
+		var c models.Contact
+		if err := contactRows.Scan(&c.ID, &c.ClientID, &c.Name, &c.Email, &c.Phone, &c.Billing); err != nil {
+			return details, err
+		}
+		details.Contacts = append(details.Contacts, c)
+	}
+
+	return details, contactRows.Err()
+}
+
+// normalizeClientDetails validates contacts and postal codes and returns the
+// details with phone numbers and postal codes normalised. At most one contact
+// may be the billing contact.
+func normalizeClientDetails(details models.ClientDetails) (models.ClientDetails, error) {
+	var err error
+	details.BillingAddress.PostalCode, err = validation.PostalCode(details.BillingAddress.Country, details.BillingAddress.PostalCode)
+	if err != nil {
+		return details, fmt.Errorf("billing address: %w", err)
+	}
+	details.ShippingAddress.PostalCode, err = validation.PostalCode(details.ShippingAddress.Country, details.ShippingAddress.PostalCode)
+	if err != nil {
+		return details, fmt.Errorf("shipping address: %w", err)
+	}
+
+	// Copy so normalising doesn't modify the caller's contacts
+	details.Contacts = append([]models.Contact(nil), details.Contacts...)
+	billing := 0
+	for i := range details.Contacts {
+		c := &details.Contacts[i]
+		if strings.TrimSpace(c.Name) == "" {
+			return details, errors.New("contact name is required")
+		}
+		if err := validation.Email(c.Email); err != nil {
+			return details, fmt.Errorf("contact %s: %w", c.Name, err)
+		}
+		if c.Phone, err = validation.Phone(c.Phone); err != nil {
+			return details, fmt.Errorf("contact %s: %w", c.Name, err)
+		}
+		if c.Billing {
+			billing++
+		}
+	}
+	if billing > 1 {
+		return details, fmt.Errorf("a client can only have one billing contact, got %d", billing)
+	}
+	return details, nil
+}
+
+// saveClientDetails replaces a client's addresses and contacts.
+// Contacts with an ID are updated in place, new ones are inserted and
+// contacts no longer present are removed.
+func saveClientDetails(tx *sql.Tx, clientID string, details models.ClientDetails) error {
+	addresses := map[string]models.Address{
+		billingAddress:  details.BillingAddress,
+		shippingAddress: details.ShippingAddress,
+	}
+	for kind, a := range addresses {
+		if _, err := tx.Exec("DELETE FROM client_address WHERE client_id = ? AND kind = ?", clientID, kind); err != nil {
+			return err
+		}
+		if a.IsEmpty() {
+			continue
+		}
+		_, err := tx.Exec(`
+			INSERT INTO client_address (client_id, kind, line1, line2, city, region, postal_code, country)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`,
+			clientID,
+			kind,
+			nullIfEmpty(a.Line1),
+			nullIfEmpty(a.Line2),
+			nullIfEmpty(a.City),
+			nullIfEmpty(a.Region),
+			nullIfEmpty(a.PostalCode),
+			nullIfEmpty(a.Country),
+		)
+		if err != nil {
+			return fmt.Errorf("saving %s address: %w", kind, err)
+		}
+	}
+
+	// Clear the billing flag first so the unique billing index isn't tripped
+	// while the designation moves from one contact to another
+	if _, err := tx.Exec("UPDATE client_contact SET is_billing = FALSE WHERE client_id = ?", clientID); err != nil {
+		return err
+	}
+
+	keep := make([]any, 0, len(details.Contacts)+1)
+	keep = append(keep, clientID)
+	var placeholders []string
+	for _, c := range details.Contacts {
+		if c.ID != 0 {
+			keep = append(keep, c.ID)
+			placeholders = append(placeholders, "?")
+		}
+	}
+	query := "DELETE FROM client_contact WHERE client_id = ?"
+	if len(placeholders) > 0 {
+		query += " AND id NOT IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+	if _, err := tx.Exec(query, keep...); err != nil {
+		return err
+	}
+
+	for _, c := range details.Contacts {
+		if c.ID != 0 {
+			err := execExpectingRow(tx,
+				"UPDATE client_contact SET name = ?, email = ?, phone = ?, is_billing = ? WHERE id = ? AND client_id = ?",
+				strings.TrimSpace(c.Name),
+				nullIfEmpty(c.Email),
+				nullIfEmpty(c.Phone),
+				c.Billing,
+				c.ID,
+				clientID,
+			)
+			if err != nil {
+				return fmt.Errorf("updating contact %s: %w", c.Name, err)
+			}
+			continue
+		}
+		_, err := tx.Exec(
+			"INSERT INTO client_contact (client_id, name, email, phone, is_billing) VALUES (?, ?, ?, ?, ?)",
+			clientID,
+			strings.TrimSpace(c.Name),
+			nullIfEmpty(c.Email),
+			nullIfEmpty(c.Phone),
+			c.Billing,
+		)
+		if err != nil {
+			return fmt.Errorf("adding contact %s: %w", c.Name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/GVPproj/termsheet/models"
+)
+
+// CreateCustomField defines a new custom field and returns its ID
+func CreateCustomField(field models.CustomField) (int, error) {
+	if err := field.Validate(); err != nil {
+		return 0, err
+	}
+
+	result, err := db.Exec(
+		"INSERT INTO custom_field (entity_type, key, label, kind, options) VALUES (?, ?, ?, ?, ?)",
+		string(field.EntityType),
+		field.Key,
+		strings.TrimSpace(field.Label),
+		string(field.Kind),
+		nullIfEmpty(strings.Join(field.Options, "\n")),
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return 0, fmt.Errorf("%s already has a field %q", field.EntityType, field.Key)
+		}
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// ListCustomFields returns the custom fields defined for an entity type in the order they were created
+func ListCustomFields(entityType models.EntityType) ([]models.CustomField, error) {
+	rows, err := db.Query(`
+		SELECT id, entity_type, key, label, kind, COALESCE(options, '')
+		FROM custom_field
+		WHERE entity_type = ?
+		ORDER BY id
+	`, string(entityType))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fields []models.CustomField
+	for rows.Next() {
+		f, err := scanCustomField(rows)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+
+	return fields, rows.Err()
+}
+
+// DeleteCustomField removes a custom field and every value stored for it
+func DeleteCustomField(entityType models.EntityType, key string) error {
+	return execExpectingRow(db,
+		"DELETE FROM custom_field WHERE entity_type = ? AND key = ?",
+		string(entityType),
+		key,
+	)
+}
+
+// GetCustomValues returns an entity's custom field values keyed by field key
+func GetCustomValues(entityType models.EntityType, entityID string) (map[string]string, error) {
+	values, err := listCustomFieldValues(entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]string, len(values))
+	for _, v := range values {
+		byKey[v.Field.Key] = v.Value
+	}
+	return byKey, nil
+}
+
+// checkCustomValues checks values against the custom fields of entityType,
+// returning the fields for setCustomValues
+func checkCustomValues(entityType models.EntityType, values map[string]string) ([]models.CustomField, error) {
+	fields, err := ListCustomFields(entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		known[f.Key] = true
+		if err := f.ValidateValue(values[f.Key]); err != nil {
+			return nil, err
+		}
+	}
+	for key := range values {
+		if !known[key] {
+			return nil, fmt.Errorf("%s has no custom field %q", entityType, key)
+		}
+	}
+	return fields, nil
+}
+
+// setCustomValues replaces an entity's values of the given fields, checked with checkCustomValues,
+// as part of saving the entity. values is keyed by field key; fields left out or set to "" are cleared.
+func setCustomValues(tx *sql.Tx, fields []models.CustomField, entityID string, values map[string]string) error {
+	for _, f := range fields {
+		var err error
+		value := strings.TrimSpace(values[f.Key])
+		if value == "" {
+			_, err = tx.Exec("DELETE FROM custom_field_value WHERE field_id = ? AND entity_id = ?", f.ID, entityID)
+		} else {
+			_, err = tx.Exec(`
+				INSERT INTO custom_field_value (field_id, entity_id, value) VALUES (?, ?, ?)
+				ON CONFLICT (field_id, entity_id) DO UPDATE SET value = excluded.value
+			`, f.ID, entityID, value)
+		}
+		if err != nil {
+			return fmt.Errorf("saving %s: %w", f.Label, err)
+		}
+	}
+	return nil
+}
+
+// listCustomFieldValues returns an entity's non-empty custom field values with their fields
+func listCustomFieldValues(entityType models.EntityType, entityID string) ([]models.CustomFieldValue, error) {
+	rows, err := db.Query(`
+		SELECT f.id, f.entity_type, f.key, f.label, f.kind, COALESCE(f.options, ''), v.value
+		FROM custom_field_value v
+		JOIN custom_field f ON f.id = v.field_id
+		WHERE f.entity_type = ? AND v.entity_id = ?
+		ORDER BY f.id
+	`, string(entityType), entityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []models.CustomFieldValue
+	for rows.Next() {
+		var v models.CustomFieldValue
+		f, err := scanCustomField(rows, &v.Value)
+		if err != nil {
+			return nil, err
+		}
+		v.Field = f
+		values = append(values, v)
+	}
+
+	return values, rows.Err()
+}
+
+// scanCustomField scans a custom field row, followed by any extra columns into extra
+func scanCustomField(rows *sql.Rows, extra ...any) (models.CustomField, error) {
+	var f models.CustomField
+	var entityType, kind, options string
+	dest := append([]any{&f.ID, &entityType, &f.Key, &f.Label, &kind, &options}, extra...)
+	if err := rows.Scan(dest...); err != nil {
+		return f, err
+	}
+	f.EntityType = models.EntityType(entityType)
+	f.Kind = models.CustomFieldKind(kind)
+	if options != "" {
+		f.Options = strings.Split(options, "\n")
+	}
+	return f, nil
+}
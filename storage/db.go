@@ -2,6 +2,7 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -15,7 +16,7 @@ var db *sql.DB
 // InitDB initializes the database connection and creates tables if they don't exist
 func InitDB() error {
 	var err error
-	db, err = sql.Open("sqlite", DBFile)
+	db, err = openDB(DBFile)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
@@ -33,22 +34,60 @@ func InitDB() error {
 	return nil
 }
 
-// createTables creates all required tables
+// openDB opens the sqlite database at path.
+// Pragmas passed in the DSN are applied by the driver to every new connection in the
+// pool, which is what SQLite needs for foreign key enforcement to be reliable.
+func openDB(path string) (*sql.DB, error) {
+	return sql.Open("sqlite", path+"?_pragma=foreign_keys(1)")
+}
+
+// createTables creates all required tables and upgrades databases created by
+// older versions of termsheet to the current schema
 func createTables() error {
+	fresh, err := isEmptyDatabase()
+	if err != nil {
+		return err
+	}
+
 	tables := []string{
 		`CREATE TABLE IF NOT EXISTS provider (
 			id TEXT PRIMARY KEY,
 			name TEXT NOT NULL,
 			address TEXT,
 			email TEXT,
-			phone TEXT
+			phone TEXT,
+			archived BOOLEAN NOT NULL DEFAULT FALSE,
+			number_format TEXT,
+			number_reset TEXT NOT NULL DEFAULT 'never'
 		)`,
 		`CREATE TABLE IF NOT EXISTS client (
 			id TEXT PRIMARY KEY,
 			name TEXT NOT NULL,
 			address TEXT,
 			email TEXT,
-			phone TEXT
+			phone TEXT,
+			archived BOOLEAN NOT NULL DEFAULT FALSE
+		)`,
+		`CREATE TABLE IF NOT EXISTS client_address (
+			client_id TEXT NOT NULL,
+			kind TEXT NOT NULL CHECK (kind IN ('billing', 'shipping')),
+			line1 TEXT,
+			line2 TEXT,
+			city TEXT,
+			region TEXT,
+			postal_code TEXT,
+			country TEXT,
+			PRIMARY KEY (client_id, kind),
+			FOREIGN KEY (client_id) REFERENCES client (id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS client_contact (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			client_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			email TEXT,
+			phone TEXT,
+			is_billing BOOLEAN NOT NULL DEFAULT FALSE,
+			FOREIGN KEY (client_id) REFERENCES client (id) ON DELETE CASCADE
 		)`,
 		`CREATE TABLE IF NOT EXISTS invoice (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -56,8 +95,14 @@ func createTables() error {
 			client_id TEXT NOT NULL,
 			paid BOOLEAN DEFAULT FALSE,
 			date_created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (provider_id) REFERENCES provider (id),
-			FOREIGN KEY (client_id) REFERENCES client (id)
+			deleted_at TIMESTAMP,
+			number TEXT,
+			issued_at TIMESTAMP,
+			notes TEXT,
+			due_date DATE,
+			terms TEXT,
+			FOREIGN KEY (provider_id) REFERENCES provider (id) ON DELETE RESTRICT,
+			FOREIGN KEY (client_id) REFERENCES client (id) ON DELETE RESTRICT
 		)`,
 		`CREATE TABLE IF NOT EXISTS invoice_item (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -65,8 +110,73 @@ func createTables() error {
 			item_name TEXT NOT NULL,
 			amount REAL NOT NULL,
 			cost_per_unit REAL NOT NULL,
-			FOREIGN KEY (invoice_id) REFERENCES invoice (id)
+			position INTEGER NOT NULL DEFAULT 0,
+			FOREIGN KEY (invoice_id) REFERENCES invoice (id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS provider_profile (
+			provider_id TEXT PRIMARY KEY,
+			legal_name TEXT,
+			tax_id TEXT,
+			company_number TEXT,
+			website TEXT,
+			logo_path TEXT,
+			iban TEXT,
+			bic TEXT,
+			account_details TEXT,
+			payment_instructions TEXT,
+			FOREIGN KEY (provider_id) REFERENCES provider (id) ON DELETE CASCADE
 		)`,
+		`CREATE TABLE IF NOT EXISTS custom_field (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entity_type TEXT NOT NULL CHECK (entity_type IN ('client', 'provider', 'invoice')),
+			key TEXT NOT NULL,
+			label TEXT NOT NULL,
+			kind TEXT NOT NULL CHECK (kind IN ('text', 'number', 'date', 'select')),
+			options TEXT,
+			UNIQUE (entity_type, key)
+		)`,
+		// entity_id holds a client or provider UUID or an invoice ID as text;
+		// the triggers below remove values when their entity is deleted
+		`CREATE TABLE IF NOT EXISTS custom_field_value (
+			field_id INTEGER NOT NULL,
+			entity_id TEXT NOT NULL,
+			value TEXT NOT NULL,
+			PRIMARY KEY (field_id, entity_id),
+			FOREIGN KEY (field_id) REFERENCES custom_field (id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS tag (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE COLLATE NOCASE
+		)`,
+		`CREATE TABLE IF NOT EXISTS client_tag (
+			client_id TEXT NOT NULL,
+			tag_id INTEGER NOT NULL,
+			PRIMARY KEY (client_id, tag_id),
+			FOREIGN KEY (client_id) REFERENCES client (id) ON DELETE CASCADE,
+			FOREIGN KEY (tag_id) REFERENCES tag (id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS invoice_tag (
+			invoice_id INTEGER NOT NULL,
+			tag_id INTEGER NOT NULL,
+			PRIMARY KEY (invoice_id, tag_id),
+			FOREIGN KEY (invoice_id) REFERENCES invoice (id) ON DELETE CASCADE,
+			FOREIGN KEY (tag_id) REFERENCES tag (id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS invoice_sequence (
+			provider_id TEXT NOT NULL,
+			period INTEGER NOT NULL,
+			last_seq INTEGER NOT NULL,
+			PRIMARY KEY (provider_id, period),
+			FOREIGN KEY (provider_id) REFERENCES provider (id) ON DELETE CASCADE
+		)`,
+		// seq orders the records from least to most recently used
+		`CREATE TABLE IF NOT EXISTS recent_entity (
+			seq INTEGER PRIMARY KEY,
+			kind TEXT NOT NULL CHECK (kind IN ('invoice', 'client', 'provider')),
+			ref TEXT NOT NULL,
+			UNIQUE (kind, ref)
+		)`,
+		searchIndexTable,
 	}
 
 	for _, table := range tables {
@@ -75,9 +185,238 @@ func createTables() error {
 		}
 	}
 
+	// A brand new database already has the latest schema
+	if fresh {
+		if err := setSchemaVersion(db, len(migrations)); err != nil {
+			return err
+		}
+	} else if err := migrate(); err != nil {
+		return err
+	}
+
+	// Indexes may cover migrated columns, so they are created last
+	indexes := []string{
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_invoice_provider_number ON invoice (provider_id, number)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_client_contact_billing ON client_contact (client_id) WHERE is_billing`,
+		`CREATE INDEX IF NOT EXISTS idx_client_tag_tag ON client_tag (tag_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_invoice_tag_tag ON invoice_tag (tag_id)`,
+		// Totals are summed per invoice, and the invoice list opens sorted by date
+		`CREATE INDEX IF NOT EXISTS idx_invoice_item_invoice ON invoice_item (invoice_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_invoice_date_created ON invoice (date_created, id)`,
+	}
+
+	for _, index := range indexes {
+		if _, err := db.Exec(index); err != nil {
+			return fmt.Errorf("failed to create index: %w", err)
+		}
+	}
+
+	triggers := []string{
+		customFieldCleanupTrigger("client", "OLD.id"),
+		customFieldCleanupTrigger("provider", "OLD.id"),
+		customFieldCleanupTrigger("invoice", "CAST(OLD.id AS TEXT)"),
+	}
+	for _, source := range searchSources {
+		triggers = append(triggers, source.triggers()...)
+	}
+
+	for _, trigger := range triggers {
+		if _, err := db.Exec(trigger); err != nil {
+			return fmt.Errorf("failed to create trigger: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// customFieldCleanupTrigger returns a trigger that deletes an entity's custom
+// field values along with it; idExpr converts OLD.id to the stored entity_id
+func customFieldCleanupTrigger(table, idExpr string) string {
+	return fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %[1]s_custom_field_cleanup
+		AFTER DELETE ON %[1]s
+		BEGIN
+			DELETE FROM custom_field_value
+			WHERE entity_id = %[2]s
+				AND field_id IN (SELECT id FROM custom_field WHERE entity_type = '%[1]s');
+		END`, table, idExpr)
+}
+
+// isEmptyDatabase reports whether the database has no tables yet
+func isEmptyDatabase() (bool, error) {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'").Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count == 0, nil
+}
+
+// migrations upgrade the schema of existing databases.
+// migrations[i] moves a database from schema version i to i+1; the current
+// version is tracked in SQLite's user_version pragma. Append new migrations to
+// the end and never reorder or edit released ones.
+var migrations = []func(tx *sql.Tx) error{
+	migrateForeignKeyActions,
+	addColumn("invoice", "deleted_at TIMESTAMP"),
+	addColumn("provider", "archived BOOLEAN NOT NULL DEFAULT FALSE"),
+	addColumn("client", "archived BOOLEAN NOT NULL DEFAULT FALSE"),
+	addColumn("provider", "number_format TEXT"),
+	addColumn("provider", "number_reset TEXT NOT NULL DEFAULT 'never'"),
+	addColumn("invoice", "number TEXT"),
+	addColumn("invoice", "issued_at TIMESTAMP"),
+	// Invoices created before numbering existed were already sent out,
+	// so treat them as issued and keep showing their internal ID
+	execStatement("UPDATE invoice SET issued_at = date_created WHERE issued_at IS NULL"),
+	// Free-text client addresses become the first line of the billing address
+	execStatement(`INSERT OR IGNORE INTO client_address (client_id, kind, line1)
+		SELECT id, 'billing', address FROM client WHERE address IS NOT NULL AND address != ''`),
+	addColumn("invoice", "notes TEXT"),
+	// Index everything that existed before full-text search; triggers keep it up to date from here on
+	func(tx *sql.Tx) error { return rebuildSearchIndex(tx) },
+	addColumn("invoice", "due_date DATE"),
+	addColumn("invoice_item", "position INTEGER NOT NULL DEFAULT 0"),
+	// Items used to be listed in the order they were added
+	execStatement(`UPDATE invoice_item SET position = (
+		SELECT COUNT(*) FROM invoice_item o WHERE o.invoice_id = invoice_item.invoice_id AND o.id < invoice_item.id)`),
+	addColumn("invoice", "terms TEXT"),
+	// Client addresses and custom field values became searchable
+	func(tx *sql.Tx) error { return rebuildSearchIndex(tx) },
+}
+
+// schemaVersion returns the schema version stored in the database
+func schemaVersion() (int, error) {
+	var version int
+	err := db.QueryRow("PRAGMA user_version").Scan(&version)
+	return version, err
+}
+
+// setSchemaVersion records the schema version in the database
+func setSchemaVersion(ex execer, version int) error {
+	// PRAGMA statements can't take bound parameters
+	_, err := ex.Exec(fmt.Sprintf("PRAGMA user_version = %d", version))
+	return err
+}
+
+// migrate applies all pending migrations.
+// Table rebuilds require foreign key enforcement to be off, which SQLite only
+// allows outside a transaction, so migrations run on a dedicated connection.
+func migrate() error {
+	version, err := schemaVersion()
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version >= len(migrations) {
+		return nil
+	}
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+
+	for ; version < len(migrations); version++ {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if err := migrations[version](tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d failed: %w", version+1, err)
+		}
+		if err := setSchemaVersion(tx, version+1); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// addColumn returns a migration that adds a column to an existing table
+func addColumn(table, definition string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", table, definition))
+		return err
+	}
+}
+
+// execStatement returns a migration that runs a single statement
+func execStatement(query string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(query)
+		return err
+	}
+}
+
+// migrateForeignKeyActions rebuilds the invoice tables so their foreign keys carry
+// ON DELETE actions. SQLite can't alter constraints in place, so each table is
+// copied into a new table with the updated definition and renamed.
+func migrateForeignKeyActions(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE invoice_new (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			provider_id TEXT NOT NULL,
+			client_id TEXT NOT NULL,
+			paid BOOLEAN DEFAULT FALSE,
+			date_created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (provider_id) REFERENCES provider (id) ON DELETE RESTRICT,
+			FOREIGN KEY (client_id) REFERENCES client (id) ON DELETE RESTRICT
+		)`,
+		`INSERT INTO invoice_new (id, provider_id, client_id, paid, date_created)
+			SELECT id, provider_id, client_id, paid, date_created FROM invoice`,
+		`DROP TABLE invoice`,
+		`ALTER TABLE invoice_new RENAME TO invoice`,
+		`CREATE TABLE invoice_item_new (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			invoice_id INTEGER NOT NULL,
+			item_name TEXT NOT NULL,
+			amount REAL NOT NULL,
+			cost_per_unit REAL NOT NULL,
+			FOREIGN KEY (invoice_id) REFERENCES invoice (id) ON DELETE CASCADE
+		)`,
+		`INSERT INTO invoice_item_new (id, invoice_id, item_name, amount, cost_per_unit)
+			SELECT id, invoice_id, item_name, amount, cost_per_unit FROM invoice_item`,
+		`DROP TABLE invoice_item`,
+		`ALTER TABLE invoice_item_new RENAME TO invoice_item`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withTx runs fn inside a single transaction.
+// The transaction is committed if fn succeeds and rolled back on any error,
+// so callers never observe partially applied changes.
+func withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
 // GetDB returns the database connection
 func GetDB() *sql.DB {
 	return db
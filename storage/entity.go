@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/GVPproj/termsheet/models"
+	"github.com/GVPproj/termsheet/validation"
 	"github.com/google/uuid"
 )
 
@@ -16,13 +17,23 @@ import (
 // 2. Field provided but empty - pointer points to an empty string ""
 // 3. Field has a value - pointer points to the actual string value
 func CreateEntity(tableName, name string, address, email, phone *string) (string, error) {
+	return insertEntity(db, tableName, name, address, email, phone)
+}
+
+// insertEntity creates an entity through ex, which may be the database or a transaction
+func insertEntity(ex execer, tableName, name string, address, email, phone *string) (string, error) {
 	if strings.TrimSpace(name) == "" {
 		return "", fmt.Errorf("%s name is required", tableName)
 	}
 
+	email, phone, err := normalizeContactDetails(email, phone)
+	if err != nil {
+		return "", err
+	}
+
 	entityID := uuid.New().String()
 
-	_, err := db.Exec(
+	_, err = ex.Exec(
 		fmt.Sprintf("INSERT INTO %s (id, name, address, email, phone) VALUES (?, ?, ?, ?, ?)", tableName),
 		entityID,
 		strings.TrimSpace(name),
@@ -37,9 +48,84 @@ func CreateEntity(tableName, name string, address, email, phone *string) (string
 	return entityID, nil
 }
 
-// ListEntities retrieves all entities from the specified table
-func ListEntities(tableName string) ([]models.Entity, error) {
-	rows, err := db.Query(fmt.Sprintf("SELECT id, name, address, email, phone FROM %s", tableName))
+// EntityFilter narrows down the entities returned by ListEntitiesMatching.
+// The zero value matches every entity that is not archived.
+type EntityFilter struct {
+	IncludeArchived bool
+	// Search matches the name, email, phone or address, ignoring case
+	Search string
+	// Tags lists tags an entity must all carry; only clients can be tagged
+	Tags []string
+}
+
+// likePattern returns a LIKE pattern matching s anywhere in a value, escaping LIKE wildcards
+// in s with backslashes; use it with ESCAPE '\'
+func likePattern(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return "%" + r.Replace(s) + "%"
+}
+
+// ListEntities retrieves entities from the specified table, leaving out
+// archived ones unless includeArchived is set
+func ListEntities(tableName string, includeArchived bool) ([]models.Entity, error) {
+	return ListEntitiesMatching(tableName, EntityFilter{IncludeArchived: includeArchived})
+}
+
+// ListEntitiesMatching retrieves the entities from the specified table that match filter
+func ListEntitiesMatching(tableName string, filter EntityFilter) ([]models.Entity, error) {
+	conditions, args, err := entityFilterConditions(tableName, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	query := entitySelect(tableName)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	return queryEntities(query, args...)
+}
+
+// entitySelect selects the columns scanned by queryEntities from tableName, aliased e
+func entitySelect(tableName string) string {
+	tags := "NULL"
+	if tableName == "client" {
+		tags = tagsColumn(clientTags, "e.id")
+	}
+	return fmt.Sprintf("SELECT e.id, e.name, e.address, e.email, e.phone, e.archived, %s FROM %s e", tags, tableName)
+}
+
+// entityFilterConditions returns the WHERE conditions and their arguments selecting the
+// entities that match filter
+func entityFilterConditions(tableName string, filter EntityFilter) ([]string, []any, error) {
+	var conditions []string
+	var args []any
+	if !filter.IncludeArchived {
+		conditions = append(conditions, "e.archived = FALSE")
+	}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		conditions = append(conditions, `(
+			e.name LIKE ? ESCAPE '\' OR
+			COALESCE(e.email, '') LIKE ? ESCAPE '\' OR
+			COALESCE(e.phone, '') LIKE ? ESCAPE '\' OR
+			COALESCE(e.address, '') LIKE ? ESCAPE '\'
+		)`)
+		pattern := likePattern(search)
+		args = append(args, pattern, pattern, pattern, pattern)
+	}
+	if len(filter.Tags) > 0 {
+		if tableName != "client" {
+			return nil, nil, fmt.Errorf("%s entries can't be tagged", tableName)
+		}
+		cond, tagArgs := tagFilter(clientTags, "e.id", filter.Tags)
+		conditions = append(conditions, cond)
+		args = append(args, tagArgs...)
+	}
+	return conditions, args, nil
+}
+
+// queryEntities runs a query selecting entitySelect's columns and scans the results
+func queryEntities(query string, args ...any) ([]models.Entity, error) {
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -48,22 +134,47 @@ func ListEntities(tableName string) ([]models.Entity, error) {
 	var entities []models.Entity
 	for rows.Next() {
 		var e models.Entity
-		if err := rows.Scan(&e.ID, &e.Name, &e.Address, &e.Email, &e.Phone); err != nil {
+		var tags sql.NullString
+		if err := rows.Scan(&e.ID, &e.Name, &e.Address, &e.Email, &e.Phone, &e.Archived, &tags); err != nil {
 			return nil, err
 		}
+		e.Tags = splitTags(tags)
 		entities = append(entities, e)
 	}
 
 	return entities, rows.Err()
 }
 
+// GetEntity retrieves a single entity by ID, archived or not
+func GetEntity(tableName, entityID string) (*models.Entity, error) {
+	var e models.Entity
+	err := db.QueryRow(
+		fmt.Sprintf("SELECT id, name, address, email, phone, archived FROM %s WHERE id = ?", tableName),
+		entityID,
+	).Scan(&e.ID, &e.Name, &e.Address, &e.Email, &e.Phone, &e.Archived)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
 // UpdateEntity updates an entity in the specified table
 func UpdateEntity(tableName, entityID, name string, address, email, phone *string) error {
+	return updateEntity(db, tableName, entityID, name, address, email, phone)
+}
+
+// updateEntity updates an entity through ex, which may be the database or a transaction
+func updateEntity(ex execer, tableName, entityID, name string, address, email, phone *string) error {
 	if strings.TrimSpace(name) == "" {
 		return fmt.Errorf("%s name is required", tableName)
 	}
 
-	result, err := db.Exec(
+	email, phone, err := normalizeContactDetails(email, phone)
+	if err != nil {
+		return err
+	}
+
+	return execExpectingRow(ex,
 		fmt.Sprintf("UPDATE %s SET name = ?, address = ?, email = ?, phone = ? WHERE id = ?", tableName),
 		strings.TrimSpace(name),
 		address,
@@ -71,20 +182,6 @@ func UpdateEntity(tableName, entityID, name string, address, email, phone *strin
 		phone,
 		entityID,
 	)
-	if err != nil {
-		return err
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
-	}
-
-	return nil
 }
 
 // HasInvoiceReferences checks if an entity (client or provider) has any associated invoices
@@ -119,7 +216,7 @@ func DeleteEntity(tableName, entityID string) error {
 		return err
 	}
 	if hasRefs {
-		return fmt.Errorf("cannot delete %s: it has associated invoices", tableName)
+		return fmt.Errorf("cannot delete %s: it has associated invoices, archive it instead", tableName)
 	}
 
 	result, err := db.Exec(fmt.Sprintf("DELETE FROM %s WHERE id = ?", tableName), entityID)
@@ -139,6 +236,57 @@ func DeleteEntity(tableName, entityID string) error {
 	return nil
 }
 
+// ArchiveEntity hides an entity from lists and selections without deleting it
+func ArchiveEntity(tableName, entityID string) error {
+	return setEntityArchived(tableName, entityID, true)
+}
+
+// RestoreEntity brings an archived entity back into lists and selections
+func RestoreEntity(tableName, entityID string) error {
+	return setEntityArchived(tableName, entityID, false)
+}
+
+// setEntityArchived updates the archived flag of an entity
+func setEntityArchived(tableName, entityID string, archived bool) error {
+	result, err := db.Exec(
+		fmt.Sprintf("UPDATE %s SET archived = ? WHERE id = ?", tableName),
+		archived,
+		entityID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// normalizeContactDetails validates an optional email and phone number and
+// returns the phone number normalised, see validation.Phone
+func normalizeContactDetails(email, phone *string) (*string, *string, error) {
+	if email != nil {
+		if err := validation.Email(*email); err != nil {
+			return nil, nil, err
+		}
+	}
+	if phone != nil {
+		normalized, err := validation.Phone(*phone)
+		if err != nil {
+			return nil, nil, err
+		}
+		phone = &normalized
+	}
+	return email, phone, nil
+}
+
 // ValidateEntityName validates that an entity name is not empty
 func ValidateEntityName(name string) error {
 	if strings.TrimSpace(name) == "" {
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// OrphanedItem is an invoice item whose invoice no longer exists
+type OrphanedItem struct {
+	ItemID    int
+	InvoiceID int
+	ItemName  string
+}
+
+// OrphanedInvoice is an invoice that references a missing client or provider.
+// MissingProviderID and MissingClientID are empty when that reference is intact.
+type OrphanedInvoice struct {
+	InvoiceID         int
+	MissingProviderID string
+	MissingClientID   string
+}
+
+// IntegrityReport lists rows that break the schema's referential constraints.
+// These can exist in databases written before foreign keys were enforced.
+type IntegrityReport struct {
+	OrphanedItems    []OrphanedItem
+	OrphanedInvoices []OrphanedInvoice
+}
+
+// OK reports whether no integrity problems were found
+func (r *IntegrityReport) OK() bool {
+	return len(r.OrphanedItems) == 0 && len(r.OrphanedInvoices) == 0
+}
+
+// CheckIntegrity scans the database for orphaned invoice items and invoices
+// pointing at missing clients or providers
+func CheckIntegrity() (*IntegrityReport, error) {
+	report := &IntegrityReport{}
+
+	rows, err := db.Query(`
+		SELECT ii.id, ii.invoice_id, ii.item_name
+		FROM invoice_item ii
+		LEFT JOIN invoice i ON ii.invoice_id = i.id
+		WHERE i.id IS NULL
+		ORDER BY ii.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item OrphanedItem
+		if err := rows.Scan(&item.ItemID, &item.InvoiceID, &item.ItemName); err != nil {
+			return nil, err
+		}
+		report.OrphanedItems = append(report.OrphanedItems, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	invoiceRows, err := db.Query(`
+		SELECT
+			i.id,
+			CASE WHEN p.id IS NULL THEN i.provider_id ELSE '' END,
+			CASE WHEN c.id IS NULL THEN i.client_id ELSE '' END
+		FROM invoice i
+		LEFT JOIN provider p ON i.provider_id = p.id
+		LEFT JOIN client c ON i.client_id = c.id
+		WHERE p.id IS NULL OR c.id IS NULL
+		ORDER BY i.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer invoiceRows.Close()
+
+	for invoiceRows.Next() {
+		var inv OrphanedInvoice
+		if err := invoiceRows.Scan(&inv.InvoiceID, &inv.MissingProviderID, &inv.MissingClientID); err != nil {
+			return nil, err
+		}
+		report.OrphanedInvoices = append(report.OrphanedInvoices, inv)
+	}
+
+	return report, invoiceRows.Err()
+}
+
+// RepairIntegrity fixes the problems found by CheckIntegrity in a single transaction.
+// Orphaned invoice items are deleted. Invoices pointing at a missing client or provider
+// are kept and a placeholder entity is recreated under the missing ID, so no billing
+// history is lost; the placeholder can then be renamed or edited from the UI.
+// Returns the report of what was repaired.
+func RepairIntegrity() (*IntegrityReport, error) {
+	report, err := CheckIntegrity()
+	if err != nil {
+		return nil, err
+	}
+	if report.OK() {
+		return report, nil
+	}
+
+	err = withTx(func(tx *sql.Tx) error {
+		for _, item := range report.OrphanedItems {
+			if _, err := tx.Exec("DELETE FROM invoice_item WHERE id = ?", item.ItemID); err != nil {
+				return err
+			}
+		}
+
+		for _, inv := range report.OrphanedInvoices {
+			if inv.MissingProviderID != "" {
+				if err := insertPlaceholderEntity(tx, "provider", inv.MissingProviderID); err != nil {
+					return err
+				}
+			}
+			if inv.MissingClientID != "" {
+				if err := insertPlaceholderEntity(tx, "client", inv.MissingClientID); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// insertPlaceholderEntity recreates a missing client or provider under its original ID.
+// Several invoices can share a missing entity, so existing rows are left untouched.
+func insertPlaceholderEntity(tx *sql.Tx, tableName, entityID string) error {
+	_, err := tx.Exec(
+		fmt.Sprintf("INSERT OR IGNORE INTO %s (id, name) VALUES (?, ?)", tableName),
+		entityID,
+		fmt.Sprintf("Recovered %s %s", tableName, shortID(entityID)),
+	)
+	return err
+}
+
+// shortID returns the first eight characters of an ID for display
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
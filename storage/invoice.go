@@ -3,6 +3,8 @@ package storage
 import (
 	"database/sql"
 	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -52,50 +54,292 @@ func UpdateInvoice(invoiceID int, providerID, clientID string, paid bool) error
 	return nil
 }
 
-func ListInvoices() ([]struct {
-	ID           int
-	ProviderName string
-	ClientName   string
-	DateCreated  time.Time
-	Paid         bool
-}, error,
-) {
-	rows, err := db.Query(`
-		SELECT
-			i.id,
-			p.name as provider_name,
-			c.name as client_name,
-			i.date_created,
-			i.paid
-		FROM invoice i
-		LEFT JOIN provider p ON i.provider_id = p.id
-		LEFT JOIN client c ON i.client_id = c.id
-		ORDER BY i.date_created DESC
-	`)
+// InvoiceFilter narrows down the invoices returned by ListInvoicesMatching.
+// The zero value matches every invoice that is not in the trash.
+type InvoiceFilter struct {
+	// Search matches the invoice number, provider and client names and item names, ignoring case
+	Search string
+	// Status limits invoices to one lifecycle status; empty matches all
+	Status     models.InvoiceStatus
+	ProviderID string
+	ClientID   string
+	// From and To limit the creation date, inclusive; zero times are unbounded
+	From time.Time
+	To   time.Time
+	// MinTotal and MaxTotal limit the invoice total, inclusive; nil is unbounded
+	MinTotal *float64
+	MaxTotal *float64
+	// Tags lists tags an invoice must all carry
+	Tags []string
+}
+
+// IsZero reports whether the filter matches every invoice
+func (f InvoiceFilter) IsZero() bool {
+	return strings.TrimSpace(f.Search) == "" && f.Status == "" && f.ProviderID == "" && f.ClientID == "" &&
+		f.From.IsZero() && f.To.IsZero() && f.MinTotal == nil && f.MaxTotal == nil && len(f.Tags) == 0
+}
+
+// invoiceTotalColumn computes the total of the invoice aliased i from its items
+const invoiceTotalColumn = "(SELECT COALESCE(SUM(it.amount * it.cost_per_unit), 0) FROM invoice_item it WHERE it.invoice_id = i.id)"
+
+// dateLayout is how filter dates are compared against date(i.date_created), and how due dates are stored
+const dateLayout = "2006-01-02"
+
+// InvoiceSortColumn is a column the invoice list can be sorted by
+type InvoiceSortColumn string
+
+const (
+	SortByNumber   InvoiceSortColumn = "number"
+	SortByDate     InvoiceSortColumn = "date"
+	SortByClient   InvoiceSortColumn = "client"
+	SortByProvider InvoiceSortColumn = "provider"
+	SortByTotal    InvoiceSortColumn = "total"
+	SortByStatus   InvoiceSortColumn = "status"
+	SortByDueDate  InvoiceSortColumn = "due"
+)
+
+// invoiceSortKey is how a sort column orders invoices
+type invoiceSortKey struct {
+	expression string
+	// nullable is set for columns that may be NULL, e.g. the number of a draft;
+	// invoices without a value sort last in both directions
+	nullable string
+}
+
+var invoiceSortKeys = map[InvoiceSortColumn]invoiceSortKey{
+	SortByNumber:   {expression: "COALESCE(i.number, '')", nullable: "i.number"},
+	SortByDate:     {expression: "i.date_created"},
+	SortByClient:   {expression: "c.name COLLATE NOCASE"},
+	SortByProvider: {expression: "p.name COLLATE NOCASE"},
+	SortByTotal:    {expression: invoiceTotalColumn},
+	SortByStatus:   {expression: "CASE WHEN i.paid THEN 2 WHEN i.issued_at IS NULL THEN 0 ELSE 1 END"},
+	SortByDueDate:  {expression: "COALESCE(i.due_date, '')", nullable: "i.due_date"},
+}
+
+// InvoiceSort orders the invoice list. The zero value sorts newest first.
+type InvoiceSort struct {
+	Column InvoiceSortColumn
+	Desc   bool
+}
+
+// DefaultInvoiceSort is the order the invoice list starts in
+var DefaultInvoiceSort = InvoiceSort{Column: SortByDate, Desc: true}
+
+// resolved returns the sort with the default order filled in for the zero value
+func (s InvoiceSort) resolved() InvoiceSort {
+	if s.Column == "" {
+		return DefaultInvoiceSort
+	}
+	return s
+}
+
+// keys returns the expressions invoices are ordered by, all compared in the sort's direction.
+// Invoice IDs come last to break ties, so the order is total and can be paged through by keyset.
+func (s InvoiceSort) keys() ([]string, error) {
+	s = s.resolved()
+	key, ok := invoiceSortKeys[s.Column]
+	if !ok {
+		return nil, fmt.Errorf("can't sort invoices by %q", s.Column)
+	}
+
+	var keys []string
+	if key.nullable != "" {
+		// Missing values compare greater going up and smaller going down
+		if s.Desc {
+			keys = append(keys, key.nullable+" IS NOT NULL")
+		} else {
+			keys = append(keys, key.nullable+" IS NULL")
+		}
+	}
+	return append(keys, key.expression, "i.id"), nil
+}
+
+// orderBy returns the ORDER BY clause for the sort, reversed if reverse is set
+func (s InvoiceSort) orderBy(reverse bool) (string, error) {
+	keys, err := s.keys()
+	if err != nil {
+		return "", err
+	}
+
+	direction := " ASC"
+	if s.resolved().Desc != reverse {
+		direction = " DESC"
+	}
+	terms := make([]string, len(keys))
+	for i, k := range keys {
+		terms[i] = k + direction
+	}
+	return strings.Join(terms, ", "), nil
+}
+
+// invoiceSummarySelect selects the columns scanned by queryInvoiceSummaries
+var invoiceSummarySelect = `
+	SELECT
+		i.id,
+		p.name as provider_name,
+		c.name as client_name,
+		i.date_created,
+		i.paid,
+		i.deleted_at,
+		COALESCE(i.number, ''),
+		i.issued_at,
+		i.due_date,
+		` + invoiceTotalColumn + ` AS total,
+		` + tagsColumn(invoiceTags, "i.id") + `
+	FROM invoice i
+	LEFT JOIN provider p ON i.provider_id = p.id
+	LEFT JOIN client c ON i.client_id = c.id
+`
+
+// ListInvoices returns all invoices that are not in the trash, newest first
+func ListInvoices() ([]models.InvoiceSummary, error) {
+	return ListInvoicesMatching(InvoiceFilter{})
+}
+
+// ListInvoicesMatching returns the invoices that are not in the trash and match filter, newest first
+func ListInvoicesMatching(filter InvoiceFilter) ([]models.InvoiceSummary, error) {
+	return ListInvoicesSorted(filter, InvoiceSort{})
+}
+
+// ListInvoicesSorted returns the invoices that are not in the trash and match filter, in the given order
+func ListInvoicesSorted(filter InvoiceFilter, sort InvoiceSort) ([]models.InvoiceSummary, error) {
+	conditions, args, err := invoiceFilterConditions(filter)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	orderBy, err := sort.orderBy(false)
+	if err != nil {
+		return nil, err
+	}
+
+	query := invoiceSummarySelect +
+		" WHERE " + strings.Join(conditions, " AND ") +
+		" ORDER BY " + orderBy
+	return queryInvoiceSummaries(query, args...)
+}
+
+// invoiceFilterConditions returns the WHERE conditions and their arguments selecting the
+// invoices that are not in the trash and match filter
+func invoiceFilterConditions(filter InvoiceFilter) ([]string, []any, error) {
+	conditions := []string{"i.deleted_at IS NULL"}
+	var args []any
 
-	var invoices []struct {
-		ID           int
-		ProviderName string
-		ClientName   string
-		DateCreated  time.Time
-		Paid         bool
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		pattern := likePattern(search)
+		// The names are matched in subqueries that run once, rather than once per invoice,
+		// so searching needs no joins and a long list is searched several times faster
+		conditions = append(conditions, `(
+			COALESCE(i.number, '') LIKE ? ESCAPE '\' OR
+			CAST(i.id AS TEXT) = ? OR
+			i.provider_id IN (SELECT id FROM provider WHERE name LIKE ? ESCAPE '\') OR
+			i.client_id IN (SELECT id FROM client WHERE name LIKE ? ESCAPE '\') OR
+			i.id IN (SELECT invoice_id FROM invoice_item WHERE item_name LIKE ? ESCAPE '\')
+		)`)
+		args = append(args, pattern, strings.TrimPrefix(search, "#"), pattern, pattern, pattern)
 	}
 
+	switch filter.Status {
+	case "":
+	case models.StatusDraft:
+		conditions = append(conditions, "NOT i.paid AND i.issued_at IS NULL")
+	case models.StatusIssued:
+		conditions = append(conditions, "NOT i.paid AND i.issued_at IS NOT NULL")
+	case models.StatusPaid:
+		conditions = append(conditions, "i.paid")
+	default:
+		return nil, nil, fmt.Errorf("unknown invoice status %q", filter.Status)
+	}
+
+	if filter.ProviderID != "" {
+		conditions = append(conditions, "i.provider_id = ?")
+		args = append(args, filter.ProviderID)
+	}
+	if filter.ClientID != "" {
+		conditions = append(conditions, "i.client_id = ?")
+		args = append(args, filter.ClientID)
+	}
+
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "date(i.date_created) >= ?")
+		args = append(args, filter.From.Format(dateLayout))
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "date(i.date_created) <= ?")
+		args = append(args, filter.To.Format(dateLayout))
+	}
+
+	if filter.MinTotal != nil {
+		conditions = append(conditions, invoiceTotalColumn+" >= ?")
+		args = append(args, *filter.MinTotal)
+	}
+	if filter.MaxTotal != nil {
+		conditions = append(conditions, invoiceTotalColumn+" <= ?")
+		args = append(args, *filter.MaxTotal)
+	}
+
+	if len(filter.Tags) > 0 {
+		cond, tagArgs := tagFilter(invoiceTags, "i.id", filter.Tags)
+		conditions = append(conditions, cond)
+		args = append(args, tagArgs...)
+	}
+
+	return conditions, args, nil
+}
+
+// ListTrashedInvoices returns the invoices in the trash, most recently deleted first
+func ListTrashedInvoices() ([]models.InvoiceSummary, error) {
+	return queryInvoiceSummaries(invoiceSummarySelect + `
+		WHERE i.deleted_at IS NOT NULL
+		ORDER BY i.deleted_at DESC, i.id DESC
+	`)
+}
+
+// FindInvoicesByNumber returns up to limit invoices, not in the trash, whose number or
+// internal ID is ref or whose number contains it, exact matches first. A leading "#" is
+// ignored, so the "#12" shown for drafts finds invoice 12.
+func FindInvoicesByNumber(ref string, limit int) ([]models.InvoiceSummary, error) {
+	ref = strings.TrimPrefix(strings.TrimSpace(ref), "#")
+	if ref == "" {
+		return nil, nil
+	}
+	return queryInvoiceSummaries(invoiceSummarySelect+`
+		WHERE i.deleted_at IS NULL AND (
+			CAST(i.id AS TEXT) = ? OR
+			i.number LIKE ? ESCAPE '\'
+		)
+		ORDER BY (CAST(i.id AS TEXT) = ? OR i.number = ? COLLATE NOCASE) DESC, length(i.number), i.id DESC
+		LIMIT ?
+	`, ref, likePattern(ref), ref, ref, limit)
+}
+
+// queryInvoiceSummaries runs a query selecting invoice summary columns and scans the rows
+func queryInvoiceSummaries(query string, args ...any) ([]models.InvoiceSummary, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invoices []models.InvoiceSummary
 	for rows.Next() {
-		var inv struct {
-			ID           int
-			ProviderName string
-			ClientName   string
-			DateCreated  time.Time
-			Paid         bool
-		}
-		if err := rows.Scan(&inv.ID, &inv.ProviderName, &inv.ClientName, &inv.DateCreated, &inv.Paid); err != nil {
+		var inv models.InvoiceSummary
+		var tags sql.NullString
+		if err := rows.Scan(
+			&inv.ID,
+			&inv.ProviderName,
+			&inv.ClientName,
+			&inv.DateCreated,
+			&inv.Paid,
+			&inv.DeletedAt,
+			&inv.Number,
+			&inv.IssuedAt,
+			&inv.DueDate,
+			&inv.Total,
+			&tags,
+		); err != nil {
 			return nil, err
 		}
+		inv.Tags = splitTags(tags)
 		invoices = append(invoices, inv)
 	}
 
@@ -108,22 +352,34 @@ func GetInvoiceData(invoiceID int) (*models.InvoiceData, error) {
 	err := db.QueryRow(`
 		SELECT
 			i.id,
+			COALESCE(i.number, ''),
 			i.date_created,
+			i.issued_at,
 			i.paid,
-			p.name, p.address, p.email, p.phone,
-			c.name, c.address, c.email, c.phone
+			COALESCE(i.notes, ''),
+			COALESCE(i.terms, ''),
+			i.due_date,
+			i.provider_id, p.name, p.address, p.email, p.phone,
+			i.client_id, c.name, c.address, c.email, c.phone
 		FROM invoice i
 		LEFT JOIN provider p ON i.provider_id = p.id
 		LEFT JOIN client c ON i.client_id = c.id
 		WHERE i.id = ?
 	`, invoiceID).Scan(
 		&data.InvoiceID,
+		&data.Number,
 		&data.DateCreated,
+		&data.IssuedAt,
 		&data.Paid,
+		&data.Notes,
+		&data.Terms,
+		&data.DueDate,
+		&data.Provider.ID,
 		&data.Provider.Name,
 		&data.Provider.Address,
 		&data.Provider.Email,
 		&data.Provider.Phone,
+		&data.Client.ID,
 		&data.Client.Name,
 		&data.Client.Address,
 		&data.Client.Email,
@@ -133,10 +389,42 @@ func GetInvoiceData(invoiceID int) (*models.InvoiceData, error) {
 		return nil, err
 	}
 
+	data.ProviderProfile, err = GetProviderProfile(data.Provider.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	data.ClientDetails, err = GetClientDetails(data.Client.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	data.Tags, err = GetInvoiceTags(data.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := []struct {
+		entityType models.EntityType
+		id         string
+	}{
+		{models.EntityInvoice, strconv.Itoa(data.InvoiceID)},
+		{models.EntityProvider, data.Provider.ID},
+		{models.EntityClient, data.Client.ID},
+	}
+	for _, owner := range owners {
+		values, err := listCustomFieldValues(owner.entityType, owner.id)
+		if err != nil {
+			return nil, err
+		}
+		data.CustomFields = append(data.CustomFields, values...)
+	}
+
 	rows, err := db.Query(`
-		SELECT id, invoice_id, item_name, amount, cost_per_unit
+		SELECT id, invoice_id, item_name, amount, cost_per_unit, position
 		FROM invoice_item
 		WHERE invoice_id = ?
+		ORDER BY position, id
 	`, invoiceID)
 	if err != nil {
 		return nil, err
@@ -145,7 +433,7 @@ func GetInvoiceData(invoiceID int) (*models.InvoiceData, error) {
 
 	for rows.Next() {
 		var item models.InvoiceItem
-		if err := rows.Scan(&item.ID, &item.InvoiceID, &item.ItemName, &item.Amount, &item.CostPerUnit); err != nil {
+		if err := rows.Scan(&item.ID, &item.InvoiceID, &item.ItemName, &item.Amount, &item.CostPerUnit, &item.Position); err != nil {
 			return nil, err
 		}
 		data.Items = append(data.Items, item)
@@ -154,23 +442,49 @@ func GetInvoiceData(invoiceID int) (*models.InvoiceData, error) {
 	return &data, rows.Err()
 }
 
+// execer is implemented by both *sql.DB and *sql.Tx so helpers can run
+// either standalone or as part of a transaction
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// AddInvoiceItem adds an item after the invoice's existing items
 func AddInvoiceItem(invoiceID int, itemName string, amount, costPerUnit float64) (int, error) {
+	var position int
+	err := db.QueryRow("SELECT COALESCE(MAX(position) + 1, 0) FROM invoice_item WHERE invoice_id = ?", invoiceID).Scan(&position)
+	if err != nil {
+		return 0, err
+	}
+	return insertInvoiceItem(db, invoiceID, itemName, amount, costPerUnit, position)
+}
+
+// validateInvoiceItem checks the item fields required by the invoice_item table
+func validateInvoiceItem(itemName string, amount, costPerUnit float64) error {
 	if strings.TrimSpace(itemName) == "" {
-		return 0, errors.New("item name is required")
+		return errors.New("item name is required")
 	}
 	if amount <= 0 {
-		return 0, errors.New("amount must be positive")
+		return errors.New("amount must be positive")
 	}
 	if costPerUnit <= 0 {
-		return 0, errors.New("cost per unit must be positive")
+		return errors.New("cost per unit must be positive")
+	}
+	return nil
+}
+
+// insertInvoiceItem validates and inserts a single item using the given executor
+func insertInvoiceItem(ex execer, invoiceID int, itemName string, amount, costPerUnit float64, position int) (int, error) {
+	if err := validateInvoiceItem(itemName, amount, costPerUnit); err != nil {
+		return 0, err
 	}
 
-	result, err := db.Exec(
-		"INSERT INTO invoice_item (invoice_id, item_name, amount, cost_per_unit) VALUES (?, ?, ?, ?)",
+	result, err := ex.Exec(
+		"INSERT INTO invoice_item (invoice_id, item_name, amount, cost_per_unit, position) VALUES (?, ?, ?, ?, ?)",
 		invoiceID,
 		strings.TrimSpace(itemName),
 		amount,
 		costPerUnit,
+		position,
 	)
 	if err != nil {
 		return 0, err
@@ -184,15 +498,268 @@ func AddInvoiceItem(invoiceID int, itemName string, amount, costPerUnit float64)
 	return int(itemID), nil
 }
 
-func DeleteInvoice(invoiceID int) error {
-	// First delete associated invoice items
-	_, err := db.Exec("DELETE FROM invoice_item WHERE invoice_id = ?", invoiceID)
+// SaveInvoiceWithItems creates (invoiceID == 0) or updates an invoice together with its items.
+// Items are kept in the order given. When updating, items with an ID are updated in place,
+// items without one are added and the invoice's other items are deleted, see saveInvoiceItems.
+// Everything runs in a single transaction: if any statement fails the whole save is rolled
+// back, so no half-written invoice is left behind. Returns the ID of the saved invoice.
+func SaveInvoiceWithItems(invoiceID int, providerID, clientID string, paid bool, items []models.InvoiceItem) (int, error) {
+	// Validate up front so obviously bad input never opens a transaction
+	if err := validateInvoiceItems(items); err != nil {
+		return 0, err
+	}
+
+	err := withTx(func(tx *sql.Tx) error {
+		var err error
+		invoiceID, err = saveInvoiceWithItems(tx, invoiceID, providerID, clientID, paid, items)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return invoiceID, nil
+}
+
+// SaveInvoice creates (draft.ID == 0) or updates an invoice with everything entered in the
+// invoice form in a single transaction, so a failed save changes nothing. Returns the invoice's ID.
+func SaveInvoice(draft models.InvoiceDraft) (int, error) {
+	if err := validateInvoiceItems(draft.Items); err != nil {
+		return 0, err
+	}
+	fields, err := checkCustomValues(models.EntityInvoice, draft.CustomValues)
+	if err != nil {
+		return 0, err
+	}
+
+	invoiceID := draft.ID
+	err = withTx(func(tx *sql.Tx) error {
+		var err error
+		invoiceID, err = saveInvoiceWithItems(tx, draft.ID, draft.ProviderID, draft.ClientID, draft.Paid, draft.Items)
+		if err != nil {
+			return err
+		}
+		var dueDate any
+		if draft.DueDate != nil {
+			dueDate = draft.DueDate.Format(dateLayout)
+		}
+		err = execExpectingRow(tx, "UPDATE invoice SET notes = ?, terms = ?, due_date = ? WHERE id = ?",
+			nullIfEmpty(draft.Notes), nullIfEmpty(draft.Terms), dueDate, invoiceID)
+		if err != nil {
+			return err
+		}
+		if err := setTags(tx, invoiceTags, invoiceID, draft.Tags); err != nil {
+			return err
+		}
+		return setCustomValues(tx, fields, strconv.Itoa(invoiceID), draft.CustomValues)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return invoiceID, nil
+}
+
+// validateInvoiceItems checks every item, numbering them from 1 in errors
+func validateInvoiceItems(items []models.InvoiceItem) error {
+	for i, item := range items {
+		if err := validateInvoiceItem(item.ItemName, item.Amount, item.CostPerUnit); err != nil {
+			return fmt.Errorf("item %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// saveInvoiceWithItems is SaveInvoiceWithItems as part of tx
+func saveInvoiceWithItems(tx *sql.Tx, invoiceID int, providerID, clientID string, paid bool, items []models.InvoiceItem) (int, error) {
+	if invoiceID == 0 {
+		result, err := tx.Exec(
+			"INSERT INTO invoice (provider_id, client_id, paid) VALUES (?, ?, ?)",
+			providerID,
+			clientID,
+			paid,
+		)
+		if err != nil {
+			return 0, err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return 0, err
+		}
+		invoiceID = int(id)
+	} else {
+		var savedProviderID string
+		var issuedAt *time.Time
+		err := tx.QueryRow("SELECT provider_id, issued_at FROM invoice WHERE id = ?", invoiceID).
+			Scan(&savedProviderID, &issuedAt)
+		if err != nil {
+			return 0, err
+		}
+		if issuedAt != nil && providerID != savedProviderID {
+			return 0, ErrIssuedProviderChange
+		}
+
+		err = execExpectingRow(tx,
+			"UPDATE invoice SET provider_id = ?, client_id = ?, paid = ? WHERE id = ?",
+			providerID,
+			clientID,
+			paid,
+			invoiceID,
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return invoiceID, saveInvoiceItems(tx, invoiceID, items)
+}
+
+// saveInvoiceItems makes items, in their order, the items of an invoice. Rather than
+// recreating every row it only touches what changed, so items keep their IDs.
+// An item without an ID is added; the invoice's items missing from items are deleted.
+func saveInvoiceItems(tx *sql.Tx, invoiceID int, items []models.InvoiceItem) error {
+	rows, err := tx.Query(`
+		SELECT id, item_name, amount, cost_per_unit, position
+		FROM invoice_item
+		WHERE invoice_id = ?
+	`, invoiceID)
 	if err != nil {
 		return err
 	}
+	saved := map[int]models.InvoiceItem{}
+	for rows.Next() {
+		var item models.InvoiceItem
+		if err := rows.Scan(&item.ID, &item.ItemName, &item.Amount, &item.CostPerUnit, &item.Position); err != nil {
+			rows.Close()
+			return err
+		}
+		saved[item.ID] = item
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	kept := map[int]bool{}
+	for _, item := range items {
+		if item.ID != 0 {
+			kept[item.ID] = true
+		}
+	}
+	for id := range saved {
+		if kept[id] {
+			continue
+		}
+		if _, err := tx.Exec("DELETE FROM invoice_item WHERE id = ?", id); err != nil {
+			return err
+		}
+	}
+
+	for i, item := range items {
+		if item.ID == 0 {
+			if _, err := insertInvoiceItem(tx, invoiceID, item.ItemName, item.Amount, item.CostPerUnit, i); err != nil {
+				return fmt.Errorf("item %d: %w", i+1, err)
+			}
+			continue
+		}
+
+		old, ok := saved[item.ID]
+		if !ok {
+			return fmt.Errorf("item %d: %w", i+1, sql.ErrNoRows)
+		}
+		name := strings.TrimSpace(item.ItemName)
+		if old.ItemName == name && old.Amount == item.Amount && old.CostPerUnit == item.CostPerUnit && old.Position == i {
+			continue
+		}
+		if err := validateInvoiceItem(name, item.Amount, item.CostPerUnit); err != nil {
+			return fmt.Errorf("item %d: %w", i+1, err)
+		}
+		if err := execExpectingRow(tx,
+			"UPDATE invoice_item SET item_name = ?, amount = ?, cost_per_unit = ?, position = ? WHERE id = ? AND invoice_id = ?",
+			name, item.Amount, item.CostPerUnit, i, item.ID, invoiceID,
+		); err != nil {
+			return fmt.Errorf("item %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// TrashInvoice soft-deletes an invoice by moving it to the trash
+func TrashInvoice(invoiceID int) error {
+	return execExpectingRow(db,
+		"UPDATE invoice SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL",
+		invoiceID,
+	)
+}
+
+// RestoreInvoice moves an invoice out of the trash
+func RestoreInvoice(invoiceID int) error {
+	return execExpectingRow(db,
+		"UPDATE invoice SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL",
+		invoiceID,
+	)
+}
+
+// PurgeInvoice permanently deletes a draft invoice that is in the trash
+func PurgeInvoice(invoiceID int) error {
+	return withTx(func(tx *sql.Tx) error {
+		var issuedAt *time.Time
+		err := tx.QueryRow("SELECT issued_at FROM invoice WHERE id = ? AND deleted_at IS NOT NULL", invoiceID).
+			Scan(&issuedAt)
+		if err != nil {
+			return err
+		}
+		if issuedAt != nil {
+			return ErrIssuedPurge
+		}
+		return execExpectingRow(tx, "DELETE FROM invoice WHERE id = ?", invoiceID)
+	})
+}
+
+// EmptyTrash permanently deletes every draft in the trash and returns how many were removed.
+// Issued invoices stay in the trash, since they can't be purged.
+func EmptyTrash() (int, error) {
+	result, err := db.Exec("DELETE FROM invoice WHERE deleted_at IS NOT NULL AND issued_at IS NULL")
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
+
+// SetInvoicePaid marks an invoice as paid, or as not paid yet
+func SetInvoicePaid(invoiceID int, paid bool) error {
+	return execExpectingRow(db, "UPDATE invoice SET paid = ? WHERE id = ?", paid, invoiceID)
+}
+
+// SetInvoiceNotes replaces an invoice's notes; empty notes are removed
+func SetInvoiceNotes(invoiceID int, notes string) error {
+	return execExpectingRow(db, "UPDATE invoice SET notes = ? WHERE id = ?", nullIfEmpty(notes), invoiceID)
+}
+
+// SetInvoiceTerms replaces an invoice's payment terms; empty terms are removed
+func SetInvoiceTerms(invoiceID int, terms string) error {
+	return execExpectingRow(db, "UPDATE invoice SET terms = ? WHERE id = ?", nullIfEmpty(terms), invoiceID)
+}
+
+// SetInvoiceDueDate sets when payment of an invoice is due; nil removes the due date
+func SetInvoiceDueDate(invoiceID int, due *time.Time) error {
+	var value any
+	if due != nil {
+		value = due.Format(dateLayout)
+	}
+	return execExpectingRow(db, "UPDATE invoice SET due_date = ? WHERE id = ?", value, invoiceID)
+}
 
-	// Then delete the invoice
-	result, err := db.Exec("DELETE FROM invoice WHERE id = ?", invoiceID)
+// execExpectingRow runs a statement that must affect at least one row,
+// returning sql.ErrNoRows if it didn't. ex may be the database or a transaction.
+func execExpectingRow(ex execer, query string, args ...any) error {
+	result, err := ex.Exec(query, args...)
 	if err != nil {
 		return err
 	}
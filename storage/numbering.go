@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/GVPproj/termsheet/models"
+	"github.com/GVPproj/termsheet/numbering"
+)
+
+// ErrAlreadyIssued is returned when issuing an invoice that already has a number
+var ErrAlreadyIssued = errors.New("invoice has already been issued")
+
+// ErrIssuedProviderChange is returned when saving an issued invoice under another provider,
+// whose numbering its number doesn't belong to
+var ErrIssuedProviderChange = errors.New("an issued invoice can't move to another provider")
+
+// ErrIssuedPurge is returned when purging an issued invoice; issued invoices stay on record
+var ErrIssuedPurge = errors.New("an issued invoice can't be permanently deleted")
+
+// GetNumberingScheme returns a provider's invoice numbering configuration
+func GetNumberingScheme(providerID string) (models.NumberingScheme, error) {
+	var scheme models.NumberingScheme
+	var reset string
+	err := db.QueryRow(
+		"SELECT COALESCE(number_format, ''), number_reset FROM provider WHERE id = ?",
+		providerID,
+	).Scan(&scheme.Format, &reset)
+	if err != nil {
+		return models.NumberingScheme{}, err
+	}
+	scheme.Reset = numbering.Reset(reset)
+	return scheme, nil
+}
+
+// SetNumberingScheme updates a provider's invoice numbering configuration.
+// An empty format resets the provider to numbering.DefaultFormat.
+func SetNumberingScheme(providerID string, scheme models.NumberingScheme) error {
+	return setNumberingScheme(db, providerID, scheme)
+}
+
+// setNumberingScheme updates a provider's numbering through ex, which may be the database or a transaction
+func setNumberingScheme(ex execer, providerID string, scheme models.NumberingScheme) error {
+	if scheme.Reset == "" {
+		scheme.Reset = numbering.ResetNever
+	}
+	if err := numbering.ValidateScheme(scheme.Format, scheme.Reset); err != nil {
+		return err
+	}
+	var format *string
+	if scheme.Format != "" {
+		format = &scheme.Format
+	}
+
+	return execExpectingRow(ex,
+		"UPDATE provider SET number_format = ?, number_reset = ? WHERE id = ?",
+		format,
+		string(scheme.Reset),
+		providerID,
+	)
+}
+
+// IssueInvoice assigns the next number from the provider's numbering scheme to a
+// draft invoice and marks it issued. Returns the assigned number.
+func IssueInvoice(invoiceID int) (string, error) {
+	return issueInvoiceAt(invoiceID, time.Now())
+}
+
+// issueInvoiceAt issues an invoice as of the given time.
+// The sequence increment and the number assignment share one transaction, so a
+// failure at any point leaves the sequence untouched and numbers stay gap-free.
+func issueInvoiceAt(invoiceID int, issued time.Time) (string, error) {
+	var number string
+
+	err := withTx(func(tx *sql.Tx) error {
+		var providerID string
+		var issuedAt *time.Time
+		err := tx.QueryRow(
+			"SELECT provider_id, issued_at FROM invoice WHERE id = ? AND deleted_at IS NULL",
+			invoiceID,
+		).Scan(&providerID, &issuedAt)
+		if err != nil {
+			return err
+		}
+		if issuedAt != nil {
+			return ErrAlreadyIssued
+		}
+
+		var format, reset string
+		err = tx.QueryRow(
+			"SELECT COALESCE(number_format, ''), number_reset FROM provider WHERE id = ?",
+			providerID,
+		).Scan(&format, &reset)
+		if err != nil {
+			return fmt.Errorf("loading numbering scheme: %w", err)
+		}
+		if format == "" {
+			format = numbering.DefaultFormat
+		}
+		// Schemes saved before yearly resets needed a year would issue numbers already taken
+		if err := numbering.ValidateScheme(format, numbering.Reset(reset)); err != nil {
+			return fmt.Errorf("provider's numbering scheme: %w", err)
+		}
+
+		period := numbering.Period(numbering.Reset(reset), issued)
+
+		// After a scheme change the next number may already have been issued under the old
+		// scheme, e.g. when dropping a yearly reset; the sequence moves past those numbers
+		for taken := true; taken; {
+			var seq int
+			err = tx.QueryRow(`
+				INSERT INTO invoice_sequence (provider_id, period, last_seq) VALUES (?, ?, 1)
+				ON CONFLICT (provider_id, period) DO UPDATE SET last_seq = last_seq + 1
+				RETURNING last_seq
+			`, providerID, period).Scan(&seq)
+			if err != nil {
+				return err
+			}
+
+			number, err = numbering.Format(format, issued, seq)
+			if err != nil {
+				return err
+			}
+
+			err = tx.QueryRow(
+				"SELECT EXISTS (SELECT 1 FROM invoice WHERE provider_id = ? AND number = ?)",
+				providerID,
+				number,
+			).Scan(&taken)
+			if err != nil {
+				return fmt.Errorf("checking number %s: %w", number, err)
+			}
+		}
+
+		_, err = tx.Exec(
+			"UPDATE invoice SET number = ?, issued_at = ? WHERE id = ?",
+			number,
+			issued,
+			invoiceID,
+		)
+		if err != nil {
+			return fmt.Errorf("assigning number %s: %w", number, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return number, nil
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/GVPproj/termsheet/models"
+)
+
+// PageCursor positions a page of a list by keyset rather than by offset, so pages stay
+// consistent while records are added or removed, and later pages cost no more than the first.
+type PageCursor struct {
+	// ID is the record the page starts after, or ends before when Backward is set.
+	// With no ID the page starts at the beginning of the list, or ends at its end.
+	ID string
+	// Backward pages towards the start of the list; the page is still returned in list order
+	Backward bool
+}
+
+// ListInvoicesPage returns up to limit invoices that are not in the trash and match filter,
+// in the given order, positioned by cursor. The cursor's invoice may itself be filtered out
+// or in the trash; the page then starts where it would have been.
+func ListInvoicesPage(filter InvoiceFilter, sort InvoiceSort, cursor PageCursor, limit int) ([]models.InvoiceSummary, error) {
+	conditions, args, err := invoiceFilterConditions(filter)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := sort.keys()
+	if err != nil {
+		return nil, err
+	}
+	orderBy, err := sort.orderBy(cursor.Backward)
+	if err != nil {
+		return nil, err
+	}
+
+	if cursor.ID != "" {
+		// Rows come after the cursor when their keys are greater going up, or smaller going down
+		op := ">"
+		if sort.resolved().Desc != cursor.Backward {
+			op = "<"
+		}
+		list := strings.Join(keys, ", ")
+		conditions = append(conditions, fmt.Sprintf(`(%s) %s (
+			SELECT %s FROM invoice i
+			LEFT JOIN provider p ON i.provider_id = p.id
+			LEFT JOIN client c ON i.client_id = c.id
+			WHERE i.id = ?
+		)`, list, op, list))
+		args = append(args, cursor.ID)
+	}
+
+	query := invoiceSummarySelect +
+		" WHERE " + strings.Join(conditions, " AND ") +
+		" ORDER BY " + orderBy +
+		" LIMIT ?"
+	invoices, err := queryInvoiceSummaries(query, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if cursor.Backward {
+		slices.Reverse(invoices)
+	}
+	return invoices, nil
+}
+
+// CountInvoices returns how many invoices are not in the trash and match filter
+func CountInvoices(filter InvoiceFilter) (int, error) {
+	conditions, args, err := invoiceFilterConditions(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	// The filters only look at the invoice's own columns, so the providers and
+	// clients aren't joined; joining them makes counting a long list several times slower
+	var count int
+	err = db.QueryRow("SELECT COUNT(*) FROM invoice i WHERE "+strings.Join(conditions, " AND "), args...).Scan(&count)
+	return count, err
+}
+
+// ListEntitiesPage returns up to limit entities from the specified table that match filter,
+// in the order they were created, positioned by cursor
+func ListEntitiesPage(tableName string, filter EntityFilter, cursor PageCursor, limit int) ([]models.Entity, error) {
+	conditions, args, err := entityFilterConditions(tableName, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	op, direction := ">", "ASC"
+	if cursor.Backward {
+		op, direction = "<", "DESC"
+	}
+	if cursor.ID != "" {
+		conditions = append(conditions, fmt.Sprintf("e.rowid %s (SELECT rowid FROM %s WHERE id = ?)", op, tableName))
+		args = append(args, cursor.ID)
+	}
+
+	query := entitySelect(tableName)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY e.rowid " + direction + " LIMIT ?"
+
+	entities, err := queryEntities(query, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if cursor.Backward {
+		slices.Reverse(entities)
+	}
+	return entities, nil
+}
+
+// CountEntities returns how many entities in the specified table match filter
+func CountEntities(tableName string, filter EntityFilter) (int, error) {
+	conditions, args, err := entityFilterConditions(tableName, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s e", tableName)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var count int
+	err = db.QueryRow(query, args...).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/GVPproj/termsheet/models"
+	"github.com/GVPproj/termsheet/validation"
+)
+
+// GetProviderProfile returns a provider's business and payment details.
+// Providers without a saved profile get an empty one.
+func GetProviderProfile(providerID string) (models.ProviderProfile, error) {
+	var p models.ProviderProfile
+	err := db.QueryRow(`
+		SELECT
+			COALESCE(legal_name, ''), COALESCE(tax_id, ''), COALESCE(company_number, ''),
+			COALESCE(website, ''), COALESCE(logo_path, ''), COALESCE(iban, ''), COALESCE(bic, ''),
+			COALESCE(account_details, ''), COALESCE(payment_instructions, '')
+		FROM provider_profile
+		WHERE provider_id = ?
+	`, providerID).Scan(
+		&p.LegalName,
+		&p.TaxID,
+		&p.CompanyNumber,
+		&p.Website,
+		&p.LogoPath,
+		&p.IBAN,
+		&p.BIC,
+		&p.AccountDetails,
+		&p.PaymentInstructions,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ProviderProfile{}, nil
+	}
+	return p, err
+}
+
+// SetProviderProfile saves a provider's business and payment details, replacing any existing ones.
+// EU VAT numbers are checked and stored compacted, see validation.TaxID.
+func SetProviderProfile(providerID string, profile models.ProviderProfile) error {
+	return setProviderProfile(db, providerID, profile)
+}
+
+// setProviderProfile saves a provider's profile through ex, which may be the database or a transaction
+func setProviderProfile(ex execer, providerID string, profile models.ProviderProfile) error {
+	taxID, err := validation.TaxID(profile.TaxID)
+	if err != nil {
+		return err
+	}
+	profile.TaxID = taxID
+	profile.IBAN = normalizeBankCode(profile.IBAN)
+	profile.BIC = normalizeBankCode(profile.BIC)
+
+	_, err = ex.Exec(`
+		INSERT INTO provider_profile (
+			provider_id, legal_name, tax_id, company_number, website, logo_path,
+			iban, bic, account_details, payment_instructions
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (provider_id) DO UPDATE SET
+			legal_name = excluded.legal_name,
+			tax_id = excluded.tax_id,
+			company_number = excluded.company_number,
+			website = excluded.website,
+			logo_path = excluded.logo_path,
+			iban = excluded.iban,
+			bic = excluded.bic,
+			account_details = excluded.account_details,
+			payment_instructions = excluded.payment_instructions
+	`,
+		providerID,
+		nullIfEmpty(profile.LegalName),
+		nullIfEmpty(profile.TaxID),
+		nullIfEmpty(profile.CompanyNumber),
+		nullIfEmpty(profile.Website),
+		nullIfEmpty(profile.LogoPath),
+		nullIfEmpty(profile.IBAN),
+		nullIfEmpty(profile.BIC),
+		nullIfEmpty(profile.AccountDetails),
+		nullIfEmpty(profile.PaymentInstructions),
+	)
+	return err
+}
+
+// normalizeBankCode strips spaces from IBANs and BICs and upper-cases them,
+// so "gb29 nwbk 6016..." is stored the way banks print it without grouping
+func normalizeBankCode(code string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(code), ""))
+}
+
+// nullIfEmpty stores empty optional text as NULL
+func nullIfEmpty(s string) *string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	return &s
+}
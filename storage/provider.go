@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"database/sql"
+
 	"github.com/GVPproj/termsheet/models"
 )
 
@@ -8,8 +10,52 @@ func CreateProvider(name string, address, email, phone *string) (string, error)
 	return CreateEntity("provider", name, address, email, phone)
 }
 
+// ListProviders returns the providers that are not archived
 func ListProviders() ([]models.Entity, error) {
-	return ListEntities("provider")
+	return ListEntities("provider", false)
+}
+
+// ListAllProviders returns every provider, including archived ones
+func ListAllProviders() ([]models.Entity, error) {
+	return ListEntities("provider", true)
+}
+
+// SaveProvider creates (draft.ID == "") or updates a provider with everything entered in the
+// provider form in a single transaction, so a failed save changes nothing. Returns the provider's ID.
+func SaveProvider(draft models.ProviderDraft) (string, error) {
+	fields, err := checkCustomValues(models.EntityProvider, draft.CustomValues)
+	if err != nil {
+		return "", err
+	}
+
+	providerID := draft.ID
+	err = withTx(func(tx *sql.Tx) error {
+		var err error
+		if providerID == "" {
+			providerID, err = insertEntity(tx, "provider", draft.Name, draft.Address, draft.Email, draft.Phone)
+		} else {
+			err = updateEntity(tx, "provider", providerID, draft.Name, draft.Address, draft.Email, draft.Phone)
+		}
+		if err != nil {
+			return err
+		}
+		if err := setProviderProfile(tx, providerID, draft.Profile); err != nil {
+			return err
+		}
+		if err := setNumberingScheme(tx, providerID, draft.Numbering); err != nil {
+			return err
+		}
+		return setCustomValues(tx, fields, providerID, draft.CustomValues)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return providerID, nil
+}
+
+func GetProvider(providerID string) (*models.Entity, error) {
+	return GetEntity("provider", providerID)
 }
 
 func UpdateProvider(providerID, name string, address, email, phone *string) error {
@@ -19,3 +65,11 @@ func UpdateProvider(providerID, name string, address, email, phone *string) erro
 func DeleteProvider(providerID string) error {
 	return DeleteEntity("provider", providerID)
 }
+
+func ArchiveProvider(providerID string) error {
+	return ArchiveEntity("provider", providerID)
+}
+
+func RestoreProvider(providerID string) error {
+	return RestoreEntity("provider", providerID)
+}
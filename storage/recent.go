@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"strconv"
+
+	"github.com/GVPproj/termsheet/models"
+)
+
+// recentLimit is how many recently used records are remembered
+const recentLimit = 50
+
+// RecordRecent remembers that the invoice, client or provider with the given ID was just used,
+// forgetting the oldest records past recentLimit
+func RecordRecent(kind models.SearchKind, id string) error {
+	// Replacing the row gives it the highest seq, making it the most recent
+	_, err := db.Exec("INSERT OR REPLACE INTO recent_entity (kind, ref) VALUES (?, ?)", kind, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		DELETE FROM recent_entity WHERE seq NOT IN (
+			SELECT seq FROM recent_entity ORDER BY seq DESC LIMIT ?
+		)
+	`, recentLimit)
+	return err
+}
+
+// ListRecent returns up to limit recently used invoices, clients and providers, most recent first.
+// Records deleted since, and invoices in the trash, are left out.
+func ListRecent(limit int) ([]models.RecentEntity, error) {
+	rows, err := db.Query(`
+		SELECT
+			r.kind,
+			r.ref,
+			COALESCE(ic.name, c.name, p.name, ''),
+			COALESCE(i.number, ''),
+			COALESCE(i.paid, FALSE)
+		FROM recent_entity r
+		LEFT JOIN invoice i ON r.kind = 'invoice' AND i.id = CAST(r.ref AS INTEGER) AND i.deleted_at IS NULL
+		LEFT JOIN client ic ON ic.id = i.client_id
+		LEFT JOIN client c ON r.kind = 'client' AND c.id = r.ref
+		LEFT JOIN provider p ON r.kind = 'provider' AND p.id = r.ref
+		WHERE i.id IS NOT NULL OR c.id IS NOT NULL OR p.id IS NOT NULL
+		ORDER BY r.seq DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recent []models.RecentEntity
+	for rows.Next() {
+		var r models.RecentEntity
+		var ref string
+		if err := rows.Scan(&r.Kind, &ref, &r.Name, &r.InvoiceNumber, &r.Paid); err != nil {
+			return nil, err
+		}
+		if r.Kind == models.SearchInvoice {
+			if r.InvoiceID, err = strconv.Atoi(ref); err != nil {
+				return nil, err
+			}
+		} else {
+			r.EntityID = ref
+		}
+		recent = append(recent, r)
+	}
+
+	return recent, rows.Err()
+}
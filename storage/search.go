@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/GVPproj/termsheet/models"
+)
+
+// searchSource describes how rows of one table are copied into the search_index
+// full-text table. Expressions use %[1]s for the row, e.g. "%[1]s.name".
+type searchSource struct {
+	table string
+	kind  models.SearchKind
+	// kindExpr computes the kind for tables whose rows belong to several kinds of record
+	kindExpr string
+	// code makes search_index rowids unique across tables: rowid = source rowid * 8 + code
+	code int
+	ref  string
+	// title is empty for rows describing a record indexed elsewhere, e.g. a client's address;
+	// Search shows the record's current name instead
+	title string
+	body  string
+}
+
+var searchSources = []searchSource{
+	{
+		table: "invoice",
+		kind:  models.SearchInvoice,
+		code:  1,
+		ref:   "%[1]s.id",
+		title: "COALESCE(%[1]s.number, '#' || %[1]s.id)",
+		body:  "COALESCE(%[1]s.notes, '')",
+	},
+	{
+		table: "invoice_item",
+		kind:  models.SearchItem,
+		code:  2,
+		ref:   "%[1]s.invoice_id",
+		title: "%[1]s.item_name",
+		body:  "''",
+	},
+	{
+		table: "client",
+		kind:  models.SearchClient,
+		code:  3,
+		ref:   "%[1]s.id",
+		title: "%[1]s.name",
+		body:  "concat_ws(' ', %[1]s.email, %[1]s.phone, %[1]s.address)",
+	},
+	{
+		table: "provider",
+		kind:  models.SearchProvider,
+		code:  4,
+		ref:   "%[1]s.id",
+		title: "%[1]s.name",
+		body:  "concat_ws(' ', %[1]s.email, %[1]s.phone, %[1]s.address)",
+	},
+	{
+		table: "client_contact",
+		kind:  models.SearchContact,
+		code:  5,
+		ref:   "%[1]s.client_id",
+		title: "%[1]s.name",
+		body:  "concat_ws(' ', %[1]s.email, %[1]s.phone)",
+	},
+	{
+		table: "client_address",
+		kind:  models.SearchClient,
+		code:  6,
+		ref:   "%[1]s.client_id",
+		title: "''",
+		body:  "concat_ws(' ', %[1]s.line1, %[1]s.line2, %[1]s.city, %[1]s.region, %[1]s.postal_code, %[1]s.country)",
+	},
+	{
+		// Custom field entity types are named like the search kinds of their entities
+		table:    "custom_field_value",
+		kindExpr: "(SELECT entity_type FROM custom_field WHERE id = %[1]s.field_id)",
+		code:     7,
+		ref:      "%[1]s.entity_id",
+		title:    "''",
+		body:     "%[1]s.value",
+	},
+}
+
+// searchIndexTable is the full-text index over invoices, items, clients, providers and contacts,
+// along with client addresses and custom field values.
+// kind and ref identify what a row points at, see models.SearchResult.
+const searchIndexTable = `CREATE VIRTUAL TABLE IF NOT EXISTS search_index USING fts5(
+	kind UNINDEXED,
+	ref UNINDEXED,
+	title,
+	body,
+	tokenize = 'unicode61 remove_diacritics 2',
+	prefix = '2 3'
+)`
+
+// values returns the search_index column values for row, which is NEW, OLD or a table alias
+func (s searchSource) values(row string) string {
+	kind := "'" + string(s.kind) + "'"
+	if s.kindExpr != "" {
+		kind = s.kindExpr
+	}
+	return fmt.Sprintf("%[1]s.rowid * 8 + %[2]d, "+kind+", "+s.ref+", "+s.title+", "+s.body,
+		row, s.code)
+}
+
+// triggers returns the triggers keeping search_index in sync with the source table
+func (s searchSource) triggers() []string {
+	insert := fmt.Sprintf("INSERT INTO search_index (rowid, kind, ref, title, body) VALUES (%s);", s.values("NEW"))
+	remove := fmt.Sprintf("DELETE FROM search_index WHERE rowid = OLD.rowid * 8 + %d;", s.code)
+
+	return []string{
+		fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s_search_insert AFTER INSERT ON %s BEGIN %s END", s.table, s.table, insert),
+		fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s_search_update AFTER UPDATE ON %s BEGIN %s %s END", s.table, s.table, remove, insert),
+		fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s_search_delete AFTER DELETE ON %s BEGIN %s END", s.table, s.table, remove),
+	}
+}
+
+// rebuildSearchIndex refills search_index from its source tables
+func rebuildSearchIndex(ex execer) error {
+	if _, err := ex.Exec("DELETE FROM search_index"); err != nil {
+		return err
+	}
+	for _, s := range searchSources {
+		_, err := ex.Exec(fmt.Sprintf(
+			"INSERT INTO search_index (rowid, kind, ref, title, body) SELECT %s FROM %s r",
+			s.values("r"), s.table,
+		))
+		if err != nil {
+			return fmt.Errorf("indexing %s: %w", s.table, err)
+		}
+	}
+	return nil
+}
+
+// Search returns the invoices, items, clients, providers and contacts matching query, best match first.
+// Clients also match on their addresses, and clients, providers and invoices on their custom field values.
+// Every word in query must match the start of a word in the result; invoices in the trash are left out.
+func Search(query string, limit int) ([]models.SearchResult, error) {
+	match := searchMatchExpression(query)
+	if match == "" {
+		return nil, nil
+	}
+
+	// Titles weigh more than the rest of the text, so "Acme" ranks the Acme client above
+	// clients with an acme.com email address. Rows without a title, such as addresses,
+	// take the name or number of the record they belong to, and a record matching on
+	// several rows is listed once.
+	rows, err := db.Query(`
+		WITH matches AS MATERIALIZED (
+			SELECT
+				s.kind,
+				s.ref,
+				COALESCE(NULLIF(s.title, ''), ec.name, ep.name, i.number, '#' || i.id, '') AS title,
+				snippet(search_index, 3, '', '', '…', 8) AS snippet,
+				COALESCE(i.id, 0) AS invoice_id,
+				COALESCE(i.number, '') AS invoice_number,
+				COALESCE(ic.name, cc.name, '') AS client_name,
+				bm25(search_index, 0, 0, 10, 1) AS score
+			FROM search_index s
+			LEFT JOIN invoice i ON s.kind IN ('invoice', 'item') AND i.id = s.ref
+			LEFT JOIN client ic ON ic.id = i.client_id
+			LEFT JOIN client cc ON s.kind = 'contact' AND cc.id = s.ref
+			LEFT JOIN client ec ON s.kind = 'client' AND ec.id = s.ref
+			LEFT JOIN provider ep ON s.kind = 'provider' AND ep.id = s.ref
+			WHERE search_index MATCH ?
+				AND (s.kind NOT IN ('invoice', 'item') OR i.deleted_at IS NULL)
+		)
+		-- The other columns come from the best scoring row of each group
+		SELECT kind, ref, title, snippet, invoice_id, invoice_number, client_name, MIN(score)
+		FROM matches
+		GROUP BY kind, ref, title
+		ORDER BY MIN(score)
+		LIMIT ?
+	`, match, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []models.SearchResult
+	for rows.Next() {
+		var r models.SearchResult
+		var ref string
+		var score float64
+		if err := rows.Scan(&r.Kind, &ref, &r.Title, &r.Snippet, &r.InvoiceID, &r.InvoiceNumber, &r.ClientName, &score); err != nil {
+			return nil, err
+		}
+		if r.InvoiceID == 0 {
+			r.EntityID = ref
+		}
+		results = append(results, r)
+	}
+
+	return results, rows.Err()
+}
+
+// searchMatchExpression turns free text into an FTS5 query matching every word as a prefix,
+// so punctuation in the input can't be mistaken for query syntax
+func searchMatchExpression(query string) string {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	terms := make([]string, len(words))
+	for i, w := range words {
+		terms[i] = `"` + w + `"*`
+	}
+	return strings.Join(terms, " ")
+}
@@ -2,19 +2,31 @@ package storage
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/GVPproj/termsheet/models"
+	"github.com/GVPproj/termsheet/numbering"
 	_ "modernc.org/sqlite"
 )
 
 // setupTestDB initializes an in-memory SQLite database for testing
-func setupTestDB(t *testing.T) {
+func setupTestDB(t testing.TB) {
 	var err error
-	db, err = sql.Open("sqlite", ":memory:")
+	db, err = openDB(":memory:")
 	if err != nil {
 		t.Fatalf("failed to open test database: %v", err)
 	}
 
+	// Each connection to ":memory:" gets its own database, so pin the pool
+	// to a single connection to keep transactions on the same data
+	db.SetMaxOpenConns(1)
+
 	if err := db.Ping(); err != nil {
 		t.Fatalf("failed to ping test database: %v", err)
 	}
@@ -25,7 +37,7 @@ func setupTestDB(t *testing.T) {
 }
 
 // teardownTestDB closes the test database connection
-func teardownTestDB(t *testing.T) {
+func teardownTestDB(t testing.TB) {
 	if db != nil {
 		if err := db.Close(); err != nil {
 			t.Errorf("failed to close test database: %v", err)
@@ -58,9 +70,9 @@ func TestCreateClientEmptyName(t *testing.T) {
 	defer teardownTestDB(t)
 
 	testCases := []struct {
-		name     string
-		input    string
-		wantErr  bool
+		name    string
+		input   string
+		wantErr bool
 	}{
 		{"empty string", "", true},
 		{"whitespace only", "   ", true},
@@ -606,3 +618,2204 @@ func TestGetInvoiceDataNonExistent(t *testing.T) {
 		t.Errorf("expected sql.ErrNoRows, got %v", err)
 	}
 }
+
+// injectItemInsertFailure installs a trigger that aborts any insert of an
+// invoice item with the given name, simulating a failure midway through a save
+func injectItemInsertFailure(t *testing.T, itemName string) {
+	_, err := db.Exec(`CREATE TRIGGER fail_item_insert BEFORE INSERT ON invoice_item
+		WHEN NEW.item_name = '` + itemName + `'
+		BEGIN SELECT RAISE(ABORT, 'injected failure'); END`)
+	if err != nil {
+		t.Fatalf("failed to install failure trigger: %v", err)
+	}
+}
+
+// countRows returns the number of rows in a table
+func countRows(t *testing.T, table string) int {
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+		t.Fatalf("failed to count %s rows: %v", table, err)
+	}
+	return n
+}
+
+// deleteInvoice permanently deletes an invoice, whether or not it was issued
+func deleteInvoice(t *testing.T, invoiceID int) {
+	if _, err := db.Exec("DELETE FROM invoice WHERE id = ?", invoiceID); err != nil {
+		t.Fatalf("failed to delete invoice %d: %v", invoiceID, err)
+	}
+}
+
+// TestSaveInvoiceWithItemsCreate tests creating an invoice with items in one call
+func TestSaveInvoiceWithItemsCreate(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+
+	items := []models.InvoiceItem{
+		{ItemName: "Design", Amount: 2, CostPerUnit: 100},
+		{ItemName: "Build", Amount: 3, CostPerUnit: 200},
+	}
+
+	invoiceID, err := SaveInvoiceWithItems(0, providerID, clientID, false, items)
+	if err != nil {
+		t.Fatalf("SaveInvoiceWithItems failed: %v", err)
+	}
+	if invoiceID == 0 {
+		t.Fatal("expected non-zero invoice ID")
+	}
+
+	data, err := GetInvoiceData(invoiceID)
+	if err != nil {
+		t.Fatalf("GetInvoiceData failed: %v", err)
+	}
+	if len(data.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(data.Items))
+	}
+	if data.Items[1].ItemName != "Build" {
+		t.Errorf("expected second item 'Build', got %q", data.Items[1].ItemName)
+	}
+}
+
+// TestSaveInvoiceWithItemsUpdate tests that updating replaces the invoice's items
+func TestSaveInvoiceWithItemsUpdate(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+
+	invoiceID, err := SaveInvoiceWithItems(0, providerID, clientID, false, []models.InvoiceItem{
+		{ItemName: "Old", Amount: 1, CostPerUnit: 10},
+	})
+	if err != nil {
+		t.Fatalf("SaveInvoiceWithItems failed: %v", err)
+	}
+
+	savedID, err := SaveInvoiceWithItems(invoiceID, providerID, clientID, true, []models.InvoiceItem{
+		{ItemName: "New A", Amount: 1, CostPerUnit: 10},
+		{ItemName: "New B", Amount: 1, CostPerUnit: 20},
+	})
+	if err != nil {
+		t.Fatalf("SaveInvoiceWithItems update failed: %v", err)
+	}
+	if savedID != invoiceID {
+		t.Errorf("expected invoice ID %d, got %d", invoiceID, savedID)
+	}
+
+	data, _ := GetInvoiceData(invoiceID)
+	if !data.Paid {
+		t.Error("expected invoice to be marked as paid")
+	}
+	if len(data.Items) != 2 || data.Items[0].ItemName != "New A" {
+		t.Errorf("expected items to be replaced, got %+v", data.Items)
+	}
+}
+
+// TestSaveInvoiceWithItemsKeepsItemIDs tests that updating edits, reorders, adds and deletes
+// items in place rather than recreating them
+func TestSaveInvoiceWithItemsKeepsItemIDs(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+
+	invoiceID, err := SaveInvoiceWithItems(0, providerID, clientID, false, []models.InvoiceItem{
+		{ItemName: "Design", Amount: 1, CostPerUnit: 10},
+		{ItemName: "Build", Amount: 2, CostPerUnit: 20},
+		{ItemName: "Support", Amount: 3, CostPerUnit: 30},
+	})
+	if err != nil {
+		t.Fatalf("SaveInvoiceWithItems failed: %v", err)
+	}
+	data, _ := GetInvoiceData(invoiceID)
+	design, build, support := data.Items[0], data.Items[1], data.Items[2]
+	if design.Position != 0 || build.Position != 1 || support.Position != 2 {
+		t.Fatalf("expected items in the order given, got %+v", data.Items)
+	}
+
+	// Move Build to the top, edit Design, drop Support and add Hosting
+	build.Position, design.Position = 0, 1
+	design.Amount = 4
+	_, err = SaveInvoiceWithItems(invoiceID, providerID, clientID, false, []models.InvoiceItem{
+		build,
+		design,
+		{ItemName: "Hosting", Amount: 1, CostPerUnit: 5},
+	})
+	if err != nil {
+		t.Fatalf("SaveInvoiceWithItems update failed: %v", err)
+	}
+
+	data, _ = GetInvoiceData(invoiceID)
+	if len(data.Items) != 3 {
+		t.Fatalf("expected 3 items, got %+v", data.Items)
+	}
+	if data.Items[0].ID != build.ID || data.Items[1].ID != design.ID {
+		t.Errorf("expected Build then Design with their IDs kept, got %+v", data.Items)
+	}
+	if data.Items[1].Amount != 4 {
+		t.Errorf("expected Design's amount to be updated, got %v", data.Items[1].Amount)
+	}
+	if data.Items[2].ItemName != "Hosting" || data.Items[2].Position != 2 {
+		t.Errorf("expected Hosting added last, got %+v", data.Items[2])
+	}
+	for _, item := range data.Items {
+		if item.ID == support.ID {
+			t.Error("expected Support to be deleted")
+		}
+	}
+}
+
+// TestSaveInvoiceWithItemsForeignItem tests that an invoice can't take over another invoice's item
+func TestSaveInvoiceWithItemsForeignItem(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+
+	first, _ := SaveInvoiceWithItems(0, providerID, clientID, false, []models.InvoiceItem{
+		{ItemName: "Mine", Amount: 1, CostPerUnit: 10},
+	})
+	second, _ := SaveInvoiceWithItems(0, providerID, clientID, false, nil)
+	data, _ := GetInvoiceData(first)
+
+	_, err := SaveInvoiceWithItems(second, providerID, clientID, false, data.Items)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows, got %v", err)
+	}
+	if data, _ := GetInvoiceData(first); len(data.Items) != 1 {
+		t.Errorf("expected the first invoice to keep its item, got %+v", data.Items)
+	}
+}
+
+// TestAddInvoiceItemPosition tests that added items go after the existing ones
+func TestAddInvoiceItemPosition(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+
+	invoiceID, _ := SaveInvoiceWithItems(0, providerID, clientID, false, []models.InvoiceItem{
+		{ItemName: "First", Amount: 1, CostPerUnit: 10},
+	})
+	if _, err := AddInvoiceItem(invoiceID, "Second", 1, 10); err != nil {
+		t.Fatalf("AddInvoiceItem failed: %v", err)
+	}
+
+	data, _ := GetInvoiceData(invoiceID)
+	if len(data.Items) != 2 || data.Items[1].ItemName != "Second" || data.Items[1].Position != 1 {
+		t.Errorf("expected Second at position 1, got %+v", data.Items)
+	}
+}
+
+// TestSaveInvoice tests saving an invoice with its items and custom field values
+func TestSaveInvoice(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+	if _, err := CreateCustomField(models.CustomField{EntityType: models.EntityInvoice, Key: "po", Label: "PO", Kind: models.FieldNumber}); err != nil {
+		t.Fatalf("CreateCustomField failed: %v", err)
+	}
+
+	due := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	draft := models.InvoiceDraft{
+		ProviderID:   providerID,
+		ClientID:     clientID,
+		Items:        []models.InvoiceItem{{ItemName: "Work", Amount: 1, CostPerUnit: 10}},
+		Tags:         []string{"retainer"},
+		Notes:        "Thanks",
+		Terms:        "Net 30",
+		DueDate:      &due,
+		CustomValues: map[string]string{"po": "1234"},
+	}
+	invoiceID, err := SaveInvoice(draft)
+	if err != nil {
+		t.Fatalf("SaveInvoice failed: %v", err)
+	}
+	if values, _ := GetCustomValues(models.EntityInvoice, strconv.Itoa(invoiceID)); values["po"] != "1234" {
+		t.Errorf("expected the custom value saved, got %v", values)
+	}
+	if tags, _ := GetInvoiceTags(invoiceID); !slices.Equal(tags, []string{"retainer"}) {
+		t.Errorf("expected the invoice tagged, got %v", tags)
+	}
+	if data, _ := GetInvoiceData(invoiceID); data.Notes != "Thanks" || data.Terms != "Net 30" || data.DueDate == nil || !data.DueDate.Equal(due) {
+		t.Errorf("expected the notes, terms and due date saved, got %q, %q and %v", data.Notes, data.Terms, data.DueDate)
+	}
+
+	// A bad custom value fails the whole save, leaving no second invoice behind
+	draft.CustomValues = map[string]string{"po": "soon"}
+	if _, err := SaveInvoice(draft); err == nil {
+		t.Error("expected an invalid custom value to fail the save")
+	}
+	if n := countRows(t, "invoice"); n != 1 {
+		t.Errorf("expected only the first invoice, got %d", n)
+	}
+	if n := countRows(t, "invoice_tag"); n != 1 {
+		t.Errorf("expected only the first invoice's tag, got %d", n)
+	}
+
+	// Saving with the ID updates the invoice
+	draft.ID = invoiceID
+	draft.CustomValues = nil
+	draft.Items[0].Amount = 2
+	if savedID, err := SaveInvoice(draft); err != nil || savedID != invoiceID {
+		t.Fatalf("SaveInvoice update failed: %v (ID %d)", err, savedID)
+	}
+	data, _ := GetInvoiceData(invoiceID)
+	if len(data.Items) != 1 || data.Items[0].Amount != 2 {
+		t.Errorf("expected the item updated, got %+v", data.Items)
+	}
+	if values, _ := GetCustomValues(models.EntityInvoice, strconv.Itoa(invoiceID)); values["po"] != "" {
+		t.Errorf("expected the custom value cleared, got %v", values)
+	}
+}
+
+// TestSetInvoiceTerms tests setting and removing an invoice's payment terms
+func TestSetInvoiceTerms(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+	invoiceID, _ := CreateInvoice(providerID, clientID, false)
+
+	if err := SetInvoiceTerms(invoiceID, "Net 30"); err != nil {
+		t.Fatalf("SetInvoiceTerms failed: %v", err)
+	}
+	if data, _ := GetInvoiceData(invoiceID); data.Terms != "Net 30" {
+		t.Errorf("expected terms %q, got %q", "Net 30", data.Terms)
+	}
+
+	if err := SetInvoiceTerms(invoiceID, ""); err != nil {
+		t.Fatalf("SetInvoiceTerms failed: %v", err)
+	}
+	if data, _ := GetInvoiceData(invoiceID); data.Terms != "" {
+		t.Errorf("expected terms removed, got %q", data.Terms)
+	}
+
+	if err := SetInvoiceTerms(999, "Net 30"); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows for a missing invoice, got %v", err)
+	}
+}
+
+// TestSaveInvoiceWithItemsUpdateNonExistent tests updating a missing invoice
+func TestSaveInvoiceWithItemsUpdateNonExistent(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	_, err := SaveInvoiceWithItems(999, "provider-id", "client-id", false, nil)
+	if err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows, got %v", err)
+	}
+}
+
+// TestSaveInvoiceWithItemsCreateRollback tests that a failed item insert leaves no invoice behind
+func TestSaveInvoiceWithItemsCreateRollback(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+	injectItemInsertFailure(t, "boom")
+
+	_, err := SaveInvoiceWithItems(0, providerID, clientID, false, []models.InvoiceItem{
+		{ItemName: "First", Amount: 1, CostPerUnit: 10},
+		{ItemName: "boom", Amount: 1, CostPerUnit: 10},
+	})
+	if err == nil {
+		t.Fatal("expected injected failure")
+	}
+
+	if n := countRows(t, "invoice"); n != 0 {
+		t.Errorf("expected no invoices after rollback, got %d", n)
+	}
+	if n := countRows(t, "invoice_item"); n != 0 {
+		t.Errorf("expected no invoice items after rollback, got %d", n)
+	}
+}
+
+// TestSaveInvoiceWithItemsUpdateRollback tests that a failed edit keeps the original invoice intact
+func TestSaveInvoiceWithItemsUpdateRollback(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+	invoiceID, err := SaveInvoiceWithItems(0, providerID, clientID, false, []models.InvoiceItem{
+		{ItemName: "Original A", Amount: 1, CostPerUnit: 10},
+		{ItemName: "Original B", Amount: 2, CostPerUnit: 20},
+	})
+	if err != nil {
+		t.Fatalf("SaveInvoiceWithItems failed: %v", err)
+	}
+
+	injectItemInsertFailure(t, "boom")
+	_, err = SaveInvoiceWithItems(invoiceID, providerID, clientID, true, []models.InvoiceItem{
+		{ItemName: "Replacement", Amount: 1, CostPerUnit: 10},
+		{ItemName: "boom", Amount: 1, CostPerUnit: 10},
+	})
+	if err == nil {
+		t.Fatal("expected injected failure")
+	}
+
+	data, err := GetInvoiceData(invoiceID)
+	if err != nil {
+		t.Fatalf("GetInvoiceData failed: %v", err)
+	}
+	if data.Paid {
+		t.Error("expected paid flag to be rolled back")
+	}
+	if len(data.Items) != 2 {
+		t.Fatalf("expected original 2 items, got %d", len(data.Items))
+	}
+	if data.Items[0].ItemName != "Original A" || data.Items[1].ItemName != "Original B" {
+		t.Errorf("expected original items, got %+v", data.Items)
+	}
+}
+
+// TestSaveInvoiceWithItemsValidation tests that invalid items are rejected before writing
+func TestSaveInvoiceWithItemsValidation(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+
+	_, err := SaveInvoiceWithItems(0, providerID, clientID, false, []models.InvoiceItem{
+		{ItemName: "Valid", Amount: 1, CostPerUnit: 10},
+		{ItemName: "Invalid", Amount: 0, CostPerUnit: 10},
+	})
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+
+	if n := countRows(t, "invoice"); n != 0 {
+		t.Errorf("expected no invoices, got %d", n)
+	}
+}
+
+// TestDeleteInvoiceRollback tests that a failed delete keeps the invoice's items
+func TestDeleteInvoiceRollback(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+	invoiceID, _ := SaveInvoiceWithItems(0, providerID, clientID, false, []models.InvoiceItem{
+		{ItemName: "Item", Amount: 1, CostPerUnit: 10},
+	})
+
+	_, err := db.Exec(`CREATE TRIGGER fail_invoice_delete BEFORE DELETE ON invoice
+		BEGIN SELECT RAISE(ABORT, 'injected failure'); END`)
+	if err != nil {
+		t.Fatalf("failed to install failure trigger: %v", err)
+	}
+
+	if _, err := db.Exec("DELETE FROM invoice WHERE id = ?", invoiceID); err == nil {
+		t.Fatal("expected injected failure")
+	}
+
+	if n := countRows(t, "invoice_item"); n != 1 {
+		t.Errorf("expected invoice item to survive rollback, got %d rows", n)
+	}
+}
+
+// TestForeignKeysEnforced tests that invoices can't reference missing entities
+func TestForeignKeysEnforced(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	if _, err := CreateInvoice("missing-provider", "missing-client", false); err == nil {
+		t.Error("expected foreign key violation for missing provider and client")
+	}
+
+	if _, err := AddInvoiceItem(999, "Widget", 1, 10); err == nil {
+		t.Error("expected foreign key violation for missing invoice")
+	}
+}
+
+// TestDeleteInvoiceCascadesItems tests that deleting an invoice removes its items
+func TestDeleteInvoiceCascadesItems(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+	invoiceID, _ := CreateInvoice(providerID, clientID, false)
+	_, _ = AddInvoiceItem(invoiceID, "Item 1", 1, 10)
+	_, _ = AddInvoiceItem(invoiceID, "Item 2", 1, 10)
+
+	deleteInvoice(t, invoiceID)
+
+	if n := countRows(t, "invoice_item"); n != 0 {
+		t.Errorf("expected items to be cascaded, got %d rows", n)
+	}
+}
+
+// TestDeleteEntityRestrictedByForeignKey tests that the schema itself refuses to
+// delete a client that invoices still reference
+func TestDeleteEntityRestrictedByForeignKey(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+	_, _ = CreateInvoice(providerID, clientID, false)
+
+	if _, err := db.Exec("DELETE FROM client WHERE id = ?", clientID); err == nil {
+		t.Error("expected foreign key violation when deleting referenced client")
+	}
+}
+
+// TestMigrateLegacySchema tests upgrading a database created before foreign key actions existed
+func TestMigrateLegacySchema(t *testing.T) {
+	var err error
+	db, err = openDB(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	defer teardownTestDB(t)
+
+	legacy := []string{
+		`CREATE TABLE provider (id TEXT PRIMARY KEY, name TEXT NOT NULL, address TEXT, email TEXT, phone TEXT)`,
+		`CREATE TABLE client (id TEXT PRIMARY KEY, name TEXT NOT NULL, address TEXT, email TEXT, phone TEXT)`,
+		`CREATE TABLE invoice (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			provider_id TEXT NOT NULL,
+			client_id TEXT NOT NULL,
+			paid BOOLEAN DEFAULT FALSE,
+			date_created TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (provider_id) REFERENCES provider (id),
+			FOREIGN KEY (client_id) REFERENCES client (id)
+		)`,
+		`CREATE TABLE invoice_item (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			invoice_id INTEGER NOT NULL,
+			item_name TEXT NOT NULL,
+			amount REAL NOT NULL,
+			cost_per_unit REAL NOT NULL,
+			FOREIGN KEY (invoice_id) REFERENCES invoice (id)
+		)`,
+		`INSERT INTO provider (id, name) VALUES ('p1', 'Provider')`,
+		`INSERT INTO client (id, name, address) VALUES ('c1', 'Client', '1 Old Road, Springfield')`,
+		`INSERT INTO invoice (id, provider_id, client_id, paid) VALUES (7, 'p1', 'c1', TRUE)`,
+		`INSERT INTO invoice_item (invoice_id, item_name, amount, cost_per_unit) VALUES (7, 'Legacy item', 2, 50)`,
+		`INSERT INTO invoice_item (invoice_id, item_name, amount, cost_per_unit) VALUES (7, 'Later item', 1, 10)`,
+	}
+	for _, stmt := range legacy {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("failed to build legacy schema: %v", err)
+		}
+	}
+
+	if err := createTables(); err != nil {
+		t.Fatalf("createTables failed on legacy database: %v", err)
+	}
+
+	version, err := schemaVersion()
+	if err != nil {
+		t.Fatalf("schemaVersion failed: %v", err)
+	}
+	if version != len(migrations) {
+		t.Errorf("expected schema version %d, got %d", len(migrations), version)
+	}
+
+	// Existing data survives the table rebuild
+	data, err := GetInvoiceData(7)
+	if err != nil {
+		t.Fatalf("GetInvoiceData failed: %v", err)
+	}
+	if !data.Paid || len(data.Items) != 2 || data.Items[0].ItemName != "Legacy item" {
+		t.Errorf("expected legacy invoice to be preserved, got %+v", data)
+	}
+	// Items keep the order they were added in
+	if data.Items[0].Position != 0 || data.Items[1].Position != 1 {
+		t.Errorf("expected items to be numbered in the order they were added, got %+v", data.Items)
+	}
+	if data.IsDraft() || data.DisplayNumber() != "#7" {
+		t.Errorf("expected legacy invoice to be issued under its ID, got %q (draft %v)", data.DisplayNumber(), data.IsDraft())
+	}
+	if data.ClientDetails.BillingAddress.Line1 != "1 Old Road, Springfield" {
+		t.Errorf("expected free-text address to become the billing address, got %+v", data.ClientDetails.BillingAddress)
+	}
+
+	// Existing records are searchable
+	results, err := Search("legacy", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].InvoiceID != 7 {
+		t.Errorf("expected legacy item to be indexed, got %+v", results)
+	}
+
+	// The rebuilt tables carry the new ON DELETE actions
+	deleteInvoice(t, 7)
+	if n := countRows(t, "invoice_item"); n != 0 {
+		t.Errorf("expected items to be cascaded after migration, got %d rows", n)
+	}
+
+	// Running again is a no-op
+	if err := createTables(); err != nil {
+		t.Fatalf("createTables failed on migrated database: %v", err)
+	}
+}
+
+// insertOrphans writes rows that violate the foreign keys by temporarily disabling enforcement
+func insertOrphans(t *testing.T, statements ...string) {
+	if _, err := db.Exec("PRAGMA foreign_keys = OFF"); err != nil {
+		t.Fatalf("failed to disable foreign keys: %v", err)
+	}
+	defer db.Exec("PRAGMA foreign_keys = ON")
+
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("failed to insert orphan: %v", err)
+		}
+	}
+}
+
+// TestCheckIntegrity tests detection of orphaned items and invoices
+func TestCheckIntegrity(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+	invoiceID, _ := CreateInvoice(providerID, clientID, false)
+	_, _ = AddInvoiceItem(invoiceID, "Healthy", 1, 10)
+
+	report, err := CheckIntegrity()
+	if err != nil {
+		t.Fatalf("CheckIntegrity failed: %v", err)
+	}
+	if !report.OK() {
+		t.Fatalf("expected healthy database, got %+v", report)
+	}
+
+	insertOrphans(t,
+		`INSERT INTO invoice_item (invoice_id, item_name, amount, cost_per_unit) VALUES (999, 'Orphan', 1, 10)`,
+		`INSERT INTO invoice (id, provider_id, client_id) VALUES (50, '`+providerID+`', 'gone-client')`,
+	)
+
+	report, err = CheckIntegrity()
+	if err != nil {
+		t.Fatalf("CheckIntegrity failed: %v", err)
+	}
+	if len(report.OrphanedItems) != 1 || report.OrphanedItems[0].InvoiceID != 999 {
+		t.Errorf("expected one orphaned item for invoice 999, got %+v", report.OrphanedItems)
+	}
+	if len(report.OrphanedInvoices) != 1 {
+		t.Fatalf("expected one orphaned invoice, got %+v", report.OrphanedInvoices)
+	}
+	orphan := report.OrphanedInvoices[0]
+	if orphan.InvoiceID != 50 || orphan.MissingClientID != "gone-client" || orphan.MissingProviderID != "" {
+		t.Errorf("unexpected orphaned invoice %+v", orphan)
+	}
+}
+
+// TestRepairIntegrity tests that repair removes orphaned items and restores missing entities
+func TestRepairIntegrity(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	insertOrphans(t,
+		`INSERT INTO invoice_item (invoice_id, item_name, amount, cost_per_unit) VALUES (999, 'Orphan', 1, 10)`,
+		`INSERT INTO invoice (id, provider_id, client_id) VALUES (50, 'gone-provider', 'gone-client')`,
+		`INSERT INTO invoice (id, provider_id, client_id) VALUES (51, 'gone-provider', 'gone-client')`,
+		`INSERT INTO invoice_item (invoice_id, item_name, amount, cost_per_unit) VALUES (50, 'Kept', 1, 10)`,
+	)
+
+	repaired, err := RepairIntegrity()
+	if err != nil {
+		t.Fatalf("RepairIntegrity failed: %v", err)
+	}
+	if len(repaired.OrphanedItems) != 1 || len(repaired.OrphanedInvoices) != 2 {
+		t.Errorf("unexpected repair report %+v", repaired)
+	}
+
+	report, err := CheckIntegrity()
+	if err != nil {
+		t.Fatalf("CheckIntegrity failed: %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("expected healthy database after repair, got %+v", report)
+	}
+
+	// Invoices and their items are kept; placeholders stand in for the missing entities
+	data, err := GetInvoiceData(50)
+	if err != nil {
+		t.Fatalf("GetInvoiceData failed: %v", err)
+	}
+	if len(data.Items) != 1 {
+		t.Errorf("expected invoice items to be kept, got %d", len(data.Items))
+	}
+	if data.Client.Name == "" || data.Provider.Name == "" {
+		t.Error("expected placeholder client and provider names")
+	}
+}
+
+// TestTrashAndRestoreInvoice tests soft-deleting an invoice and restoring it
+func TestTrashAndRestoreInvoice(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+	invoiceID, _ := CreateInvoice(providerID, clientID, false)
+	_, _ = AddInvoiceItem(invoiceID, "Item", 1, 10)
+
+	if err := TrashInvoice(invoiceID); err != nil {
+		t.Fatalf("TrashInvoice failed: %v", err)
+	}
+
+	invoices, _ := ListInvoices()
+	if len(invoices) != 0 {
+		t.Errorf("expected trashed invoice to be hidden from list, got %d", len(invoices))
+	}
+
+	trashed, err := ListTrashedInvoices()
+	if err != nil {
+		t.Fatalf("ListTrashedInvoices failed: %v", err)
+	}
+	if len(trashed) != 1 || trashed[0].ID != invoiceID || trashed[0].DeletedAt == nil {
+		t.Fatalf("expected invoice in trash with deletion time, got %+v", trashed)
+	}
+
+	// Trashing twice is an error
+	if err := TrashInvoice(invoiceID); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows when trashing twice, got %v", err)
+	}
+
+	if err := RestoreInvoice(invoiceID); err != nil {
+		t.Fatalf("RestoreInvoice failed: %v", err)
+	}
+
+	invoices, _ = ListInvoices()
+	if len(invoices) != 1 {
+		t.Errorf("expected restored invoice in list, got %d", len(invoices))
+	}
+	if n := countRows(t, "invoice_item"); n != 1 {
+		t.Errorf("expected items to survive the trash, got %d", n)
+	}
+}
+
+// TestPurgeInvoice tests that only trashed invoices can be purged
+func TestPurgeInvoice(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+	invoiceID, _ := CreateInvoice(providerID, clientID, false)
+	_, _ = AddInvoiceItem(invoiceID, "Item", 1, 10)
+
+	if err := PurgeInvoice(invoiceID); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows when purging a live invoice, got %v", err)
+	}
+
+	_ = TrashInvoice(invoiceID)
+	if err := PurgeInvoice(invoiceID); err != nil {
+		t.Fatalf("PurgeInvoice failed: %v", err)
+	}
+
+	if n := countRows(t, "invoice"); n != 0 {
+		t.Errorf("expected invoice to be purged, got %d rows", n)
+	}
+	if n := countRows(t, "invoice_item"); n != 0 {
+		t.Errorf("expected items to be purged, got %d rows", n)
+	}
+
+	issuedID, _ := CreateInvoice(providerID, clientID, false)
+	_, _ = IssueInvoice(issuedID)
+	_ = TrashInvoice(issuedID)
+	if err := PurgeInvoice(issuedID); !errors.Is(err, ErrIssuedPurge) {
+		t.Errorf("expected ErrIssuedPurge when purging an issued invoice, got %v", err)
+	}
+	if n := countRows(t, "invoice"); n != 1 {
+		t.Errorf("expected the issued invoice to be kept, got %d rows", n)
+	}
+}
+
+// TestEmptyTrash tests purging every trashed invoice at once
+func TestEmptyTrash(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+	kept, _ := CreateInvoice(providerID, clientID, false)
+	first, _ := CreateInvoice(providerID, clientID, false)
+	second, _ := CreateInvoice(providerID, clientID, false)
+	issued, _ := CreateInvoice(providerID, clientID, false)
+	_, _ = IssueInvoice(issued)
+	_ = TrashInvoice(first)
+	_ = TrashInvoice(second)
+	_ = TrashInvoice(issued)
+
+	count, err := EmptyTrash()
+	if err != nil {
+		t.Fatalf("EmptyTrash failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 purged invoices, got %d", count)
+	}
+
+	invoices, _ := ListInvoices()
+	if len(invoices) != 1 || invoices[0].ID != kept {
+		t.Errorf("expected only the live invoice to remain, got %+v", invoices)
+	}
+	trashed, _ := ListTrashedInvoices()
+	if len(trashed) != 1 || trashed[0].ID != issued {
+		t.Errorf("expected the issued invoice to stay in the trash, got %+v", trashed)
+	}
+}
+
+// TestSaveIssuedInvoiceProvider tests that an issued invoice keeps its provider,
+// whose numbering its number belongs to
+func TestSaveIssuedInvoiceProvider(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	otherID, _ := CreateProvider("Other", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+	items := []models.InvoiceItem{{ItemName: "Item", Amount: 1, CostPerUnit: 10}}
+
+	draftID, _ := SaveInvoiceWithItems(0, providerID, clientID, false, items)
+	if _, err := SaveInvoiceWithItems(draftID, otherID, clientID, false, items); err != nil {
+		t.Errorf("expected a draft to move to another provider, got %v", err)
+	}
+
+	issuedID, _ := SaveInvoiceWithItems(0, providerID, clientID, false, items)
+	_, _ = IssueInvoice(issuedID)
+	if _, err := SaveInvoiceWithItems(issuedID, otherID, clientID, false, items); !errors.Is(err, ErrIssuedProviderChange) {
+		t.Errorf("expected ErrIssuedProviderChange, got %v", err)
+	}
+	if _, err := SaveInvoiceWithItems(issuedID, providerID, clientID, true, items); err != nil {
+		t.Errorf("expected an issued invoice to save under its own provider, got %v", err)
+	}
+
+	data, _ := GetInvoiceData(issuedID)
+	if data.Provider.ID != providerID {
+		t.Errorf("expected provider %s to be kept, got %s", providerID, data.Provider.ID)
+	}
+}
+
+// TestArchiveAndRestoreClient tests hiding a client from lists without deleting it
+func TestArchiveAndRestoreClient(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	activeID, _ := CreateClient("Active Client", nil, nil, nil)
+	archivedID, _ := CreateClient("Old Client", nil, nil, nil)
+
+	if err := ArchiveClient(archivedID); err != nil {
+		t.Fatalf("ArchiveClient failed: %v", err)
+	}
+
+	clients, err := ListClients()
+	if err != nil {
+		t.Fatalf("ListClients failed: %v", err)
+	}
+	if len(clients) != 1 || clients[0].ID != activeID {
+		t.Errorf("expected only the active client, got %+v", clients)
+	}
+
+	all, err := ListAllClients()
+	if err != nil {
+		t.Fatalf("ListAllClients failed: %v", err)
+	}
+	if len(all) != 2 {
+		t.Errorf("expected 2 clients including archived, got %d", len(all))
+	}
+
+	client, err := GetClient(archivedID)
+	if err != nil {
+		t.Fatalf("GetClient failed: %v", err)
+	}
+	if !client.Archived {
+		t.Error("expected client to be archived")
+	}
+
+	if err := RestoreClient(archivedID); err != nil {
+		t.Fatalf("RestoreClient failed: %v", err)
+	}
+	clients, _ = ListClients()
+	if len(clients) != 2 {
+		t.Errorf("expected restored client in list, got %d clients", len(clients))
+	}
+}
+
+// TestArchiveProviderWithInvoices tests that providers with invoices can be archived
+// even though they can't be deleted
+func TestArchiveProviderWithInvoices(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+	invoiceID, _ := CreateInvoice(providerID, clientID, false)
+
+	if err := DeleteProvider(providerID); err == nil {
+		t.Fatal("expected delete to be refused")
+	}
+
+	if err := ArchiveProvider(providerID); err != nil {
+		t.Fatalf("ArchiveProvider failed: %v", err)
+	}
+
+	providers, _ := ListProviders()
+	if len(providers) != 0 {
+		t.Errorf("expected archived provider to be hidden, got %d", len(providers))
+	}
+
+	// Invoices keep showing the archived provider
+	data, err := GetInvoiceData(invoiceID)
+	if err != nil {
+		t.Fatalf("GetInvoiceData failed: %v", err)
+	}
+	if data.Provider.ID != providerID || data.Provider.Name != "Provider" {
+		t.Errorf("expected invoice to reference archived provider, got %+v", data.Provider)
+	}
+}
+
+// TestArchiveNonExistent tests archiving a missing entity
+func TestArchiveNonExistent(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	if err := ArchiveClient("non-existent-id"); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows, got %v", err)
+	}
+}
+
+// TestIssueInvoiceSequence tests that issued invoices get consecutive numbers per provider
+func TestIssueInvoiceSequence(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	acme, _ := CreateProvider("Acme", nil, nil, nil)
+	other, _ := CreateProvider("Other", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+
+	if err := SetNumberingScheme(acme, models.NumberingScheme{Format: "ACME-{YYYY}-{seq:04}", Reset: numbering.ResetYearly}); err != nil {
+		t.Fatalf("SetNumberingScheme failed: %v", err)
+	}
+
+	issued := time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC)
+	first, _ := CreateInvoice(acme, clientID, false)
+	second, _ := CreateInvoice(acme, clientID, false)
+	otherInvoice, _ := CreateInvoice(other, clientID, false)
+
+	expected := []struct {
+		invoiceID int
+		number    string
+	}{
+		{first, "ACME-2026-0001"},
+		{otherInvoice, "INV-0001"},
+		{second, "ACME-2026-0002"},
+	}
+	for _, e := range expected {
+		number, err := issueInvoiceAt(e.invoiceID, issued)
+		if err != nil {
+			t.Fatalf("issueInvoiceAt(%d) failed: %v", e.invoiceID, err)
+		}
+		if number != e.number {
+			t.Errorf("expected number %q, got %q", e.number, number)
+		}
+	}
+
+	data, _ := GetInvoiceData(first)
+	if data.IsDraft() || data.DisplayNumber() != "ACME-2026-0001" {
+		t.Errorf("expected issued invoice ACME-2026-0001, got %q (draft %v)", data.DisplayNumber(), data.IsDraft())
+	}
+
+	if _, err := issueInvoiceAt(first, issued); err != ErrAlreadyIssued {
+		t.Errorf("expected ErrAlreadyIssued, got %v", err)
+	}
+}
+
+// TestIssueInvoiceYearlyReset tests that yearly schemes restart the sequence each year
+func TestIssueInvoiceYearlyReset(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+	_ = SetNumberingScheme(providerID, models.NumberingScheme{Format: "{YYYY}/{seq}", Reset: numbering.ResetYearly})
+
+	dates := []time.Time{
+		time.Date(2025, time.December, 30, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, time.December, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}
+	expected := []string{"2025/1", "2025/2", "2026/1"}
+
+	for i, date := range dates {
+		invoiceID, _ := CreateInvoice(providerID, clientID, false)
+		number, err := issueInvoiceAt(invoiceID, date)
+		if err != nil {
+			t.Fatalf("issueInvoiceAt failed: %v", err)
+		}
+		if number != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], number)
+		}
+	}
+}
+
+// TestIssueInvoiceYearlyResetNeedsYear tests that numbers restarting every year must say the
+// year, since they would otherwise repeat from the second year on
+func TestIssueInvoiceYearlyResetNeedsYear(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+
+	for _, format := range []string{"", "INV-{seq:04}"} {
+		err := SetNumberingScheme(providerID, models.NumberingScheme{Format: format, Reset: numbering.ResetYearly})
+		if err == nil {
+			t.Errorf("expected yearly reset without a year in %q to be rejected", format)
+		}
+	}
+
+	// A scheme saved before the check can't issue numbers that are already taken
+	if _, err := db.Exec("UPDATE provider SET number_reset = 'yearly' WHERE id = ?", providerID); err != nil {
+		t.Fatalf("failed to store legacy scheme: %v", err)
+	}
+	dates := []time.Time{
+		time.Date(2025, time.December, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for _, date := range dates {
+		invoiceID, _ := CreateInvoice(providerID, clientID, false)
+		if _, err := issueInvoiceAt(invoiceID, date); err == nil {
+			t.Errorf("expected issuing on %s under a yearly scheme without a year to fail", date.Format("2006-01-02"))
+		}
+	}
+
+	// With the year in the format, numbers stay unique across the year boundary
+	if err := SetNumberingScheme(providerID, models.NumberingScheme{Format: "INV-{YY}-{seq:04}", Reset: numbering.ResetYearly}); err != nil {
+		t.Fatalf("SetNumberingScheme failed: %v", err)
+	}
+	var numbers []string
+	for _, date := range dates {
+		invoiceID, _ := CreateInvoice(providerID, clientID, false)
+		number, err := issueInvoiceAt(invoiceID, date)
+		if err != nil {
+			t.Fatalf("issueInvoiceAt failed: %v", err)
+		}
+		numbers = append(numbers, number)
+	}
+	if !slices.Equal(numbers, []string{"INV-25-0001", "INV-26-0001"}) {
+		t.Errorf("expected INV-25-0001 then INV-26-0001, got %v", numbers)
+	}
+}
+
+// TestIssueInvoiceAfterSchemeChange tests that numbers issued under an earlier scheme are
+// skipped rather than blocking issuing for good
+func TestIssueInvoiceAfterSchemeChange(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+	issued := time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC)
+
+	issue := func() string {
+		t.Helper()
+		invoiceID, _ := CreateInvoice(providerID, clientID, false)
+		number, err := issueInvoiceAt(invoiceID, issued)
+		if err != nil {
+			t.Fatalf("issueInvoiceAt failed: %v", err)
+		}
+		return number
+	}
+
+	_ = SetNumberingScheme(providerID, models.NumberingScheme{Format: "{YYYY}-{seq}", Reset: numbering.ResetYearly})
+	numbers := []string{issue(), issue()}
+
+	// Dropping the yearly reset starts a new sequence, whose first numbers are taken
+	if err := SetNumberingScheme(providerID, models.NumberingScheme{Format: "{YYYY}-{seq}", Reset: numbering.ResetNever}); err != nil {
+		t.Fatalf("SetNumberingScheme failed: %v", err)
+	}
+	numbers = append(numbers, issue(), issue())
+
+	if !slices.Equal(numbers, []string{"2026-1", "2026-2", "2026-3", "2026-4"}) {
+		t.Errorf("expected taken numbers to be skipped, got %v", numbers)
+	}
+}
+
+// TestIssueInvoiceRollbackIsGapFree tests that a failed issue doesn't consume a sequence number
+func TestIssueInvoiceRollbackIsGapFree(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+	issued := time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	first, _ := CreateInvoice(providerID, clientID, false)
+	second, _ := CreateInvoice(providerID, clientID, false)
+
+	_, err := db.Exec(`CREATE TRIGGER fail_issue BEFORE UPDATE OF number ON invoice
+		WHEN NEW.id = ` + fmt.Sprint(first) + `
+		BEGIN SELECT RAISE(ABORT, 'injected failure'); END`)
+	if err != nil {
+		t.Fatalf("failed to install failure trigger: %v", err)
+	}
+
+	if _, err := issueInvoiceAt(first, issued); err == nil {
+		t.Fatal("expected injected failure")
+	}
+
+	number, err := issueInvoiceAt(second, issued)
+	if err != nil {
+		t.Fatalf("issueInvoiceAt failed: %v", err)
+	}
+	if number != "INV-0001" {
+		t.Errorf("expected failed issue to leave no gap, got %q", number)
+	}
+
+	data, _ := GetInvoiceData(first)
+	if !data.IsDraft() {
+		t.Error("expected failed invoice to remain a draft")
+	}
+}
+
+// TestSetNumberingSchemeValidation tests that invalid schemes are rejected
+func TestSetNumberingSchemeValidation(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+
+	if err := SetNumberingScheme(providerID, models.NumberingScheme{Format: "NO-SEQ"}); err == nil {
+		t.Error("expected error for format without {seq}")
+	}
+	if err := SetNumberingScheme(providerID, models.NumberingScheme{Format: "{seq}", Reset: "monthly"}); err == nil {
+		t.Error("expected error for unknown reset policy")
+	}
+
+	if err := SetNumberingScheme(providerID, models.NumberingScheme{}); err != nil {
+		t.Fatalf("expected empty scheme to reset to defaults, got %v", err)
+	}
+	scheme, err := GetNumberingScheme(providerID)
+	if err != nil {
+		t.Fatalf("GetNumberingScheme failed: %v", err)
+	}
+	if scheme.Format != "" || scheme.Reset != numbering.ResetNever {
+		t.Errorf("expected default scheme, got %+v", scheme)
+	}
+}
+
+// TestProviderProfile tests saving, loading and replacing a provider's business details
+func TestProviderProfile(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Acme", nil, nil, nil)
+
+	profile, err := GetProviderProfile(providerID)
+	if err != nil {
+		t.Fatalf("GetProviderProfile failed: %v", err)
+	}
+	if profile != (models.ProviderProfile{}) {
+		t.Errorf("expected empty profile for new provider, got %+v", profile)
+	}
+
+	err = SetProviderProfile(providerID, models.ProviderProfile{
+		LegalName: "Acme Consulting Ltd",
+		TaxID:     "GB123456789",
+		IBAN:      "gb29 nwbk 6016 1331 9268 19",
+		BIC:       "nwbkgb2l",
+	})
+	if err != nil {
+		t.Fatalf("SetProviderProfile failed: %v", err)
+	}
+
+	profile, _ = GetProviderProfile(providerID)
+	if profile.LegalName != "Acme Consulting Ltd" || profile.TaxID != "GB123456789" {
+		t.Errorf("unexpected profile: %+v", profile)
+	}
+	if profile.IBAN != "GB29NWBK60161331926819" || profile.BIC != "NWBKGB2L" {
+		t.Errorf("expected normalized bank codes, got IBAN %q BIC %q", profile.IBAN, profile.BIC)
+	}
+
+	// Saving again replaces the whole profile
+	if err := SetProviderProfile(providerID, models.ProviderProfile{Website: "acme.example"}); err != nil {
+		t.Fatalf("SetProviderProfile failed: %v", err)
+	}
+	profile, _ = GetProviderProfile(providerID)
+	if profile != (models.ProviderProfile{Website: "acme.example"}) {
+		t.Errorf("expected profile to be replaced, got %+v", profile)
+	}
+
+	// Invoices carry the provider's profile
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+	invoiceID, _ := CreateInvoice(providerID, clientID, false)
+	data, err := GetInvoiceData(invoiceID)
+	if err != nil {
+		t.Fatalf("GetInvoiceData failed: %v", err)
+	}
+	if data.ProviderProfile.Website != "acme.example" {
+		t.Errorf("expected invoice data to include provider profile, got %+v", data.ProviderProfile)
+	}
+
+	// Deleting the provider removes its profile
+	otherID, _ := CreateProvider("Other", nil, nil, nil)
+	_ = SetProviderProfile(otherID, models.ProviderProfile{TaxID: "X"})
+	if err := DeleteProvider(otherID); err != nil {
+		t.Fatalf("DeleteProvider failed: %v", err)
+	}
+	if n := countRows(t, "provider_profile"); n != 1 {
+		t.Errorf("expected only Acme's profile to remain, found %d", n)
+	}
+}
+
+// TestClientWithDetails tests saving a client with structured addresses and contacts
+func TestClientWithDetails(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	email := "office@acme.example"
+	details := models.ClientDetails{
+		BillingAddress: models.Address{
+			Line1:      "1 Main St",
+			City:       "Springfield",
+			Region:     "IL",
+			PostalCode: "62701",
+			Country:    "United States",
+		},
+		ShippingAddress: models.Address{Line1: "2 Dock Rd", City: "Springfield"},
+		Contacts: []models.Contact{
+			{Name: "Ann", Email: "ann@acme.example"},
+			{Name: "Bob", Email: "bob@acme.example", Billing: true},
+		},
+	}
+
+	clientID, err := CreateClientWithDetails("Acme", &email, nil, details)
+	if err != nil {
+		t.Fatalf("CreateClientWithDetails failed: %v", err)
+	}
+
+	client, _ := GetClient(clientID)
+	if client.Address == nil || *client.Address != "1 Main St, Springfield, IL 62701, United States" {
+		t.Errorf("expected one-line billing address on the client, got %v", client.Address)
+	}
+
+	got, err := GetClientDetails(clientID)
+	if err != nil {
+		t.Fatalf("GetClientDetails failed: %v", err)
+	}
+	if got.BillingAddress != details.BillingAddress || got.ShippingAddress != details.ShippingAddress {
+		t.Errorf("addresses not preserved: %+v", got)
+	}
+	if len(got.Contacts) != 2 || got.Contacts[0].Name != "Bob" {
+		t.Fatalf("expected billing contact first, got %+v", got.Contacts)
+	}
+	if got.InvoiceEmail(*client) != "bob@acme.example" {
+		t.Errorf("expected invoices to go to the billing contact, got %q", got.InvoiceEmail(*client))
+	}
+
+	// Move the billing designation, edit a contact and drop the other
+	bob, ann := got.Contacts[0], got.Contacts[1]
+	bob.Billing = false
+	ann.Billing = true
+	ann.Phone = "555-0101"
+	updated := models.ClientDetails{
+		BillingAddress: details.BillingAddress,
+		Contacts:       []models.Contact{ann, {Name: "Cat"}},
+	}
+	if err := UpdateClientWithDetails(clientID, "Acme", &email, nil, updated); err != nil {
+		t.Fatalf("UpdateClientWithDetails failed: %v", err)
+	}
+
+	got, _ = GetClientDetails(clientID)
+	if !got.ShippingAddress.IsEmpty() {
+		t.Errorf("expected shipping address to be cleared, got %+v", got.ShippingAddress)
+	}
+	if len(got.Contacts) != 2 {
+		t.Fatalf("expected 2 contacts, got %+v", got.Contacts)
+	}
+	if got.Contacts[0].ID != ann.ID || !got.Contacts[0].Billing || got.Contacts[0].Phone != "555-0101" {
+		t.Errorf("expected Ann to keep her ID and become the billing contact, got %+v", got.Contacts[0])
+	}
+	if got.Contacts[1].Name != "Cat" {
+		t.Errorf("expected new contact Cat, got %+v", got.Contacts[1])
+	}
+}
+
+// TestSaveClient tests saving a client with its details, tags and custom fields in one call
+func TestSaveClient(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	if _, err := CreateCustomField(models.CustomField{EntityType: models.EntityClient, Key: "hours", Label: "Hours", Kind: models.FieldNumber}); err != nil {
+		t.Fatalf("CreateCustomField failed: %v", err)
+	}
+
+	draft := models.ClientDraft{
+		Name:         "Acme",
+		Details:      models.ClientDetails{BillingAddress: models.Address{Line1: "1 Main St", City: "Springfield"}},
+		Tags:         []string{"agency"},
+		CustomValues: map[string]string{"hours": "7.5"},
+	}
+	clientID, err := SaveClient(draft)
+	if err != nil {
+		t.Fatalf("SaveClient failed: %v", err)
+	}
+	details, _ := GetClientDetails(clientID)
+	tags, _ := GetClientTags(clientID)
+	values, _ := GetCustomValues(models.EntityClient, clientID)
+	if details.BillingAddress.Line1 != "1 Main St" || len(tags) != 1 || tags[0] != "agency" || values["hours"] != "7.5" {
+		t.Errorf("unexpected details %+v, tags %v or custom values %v", details, tags, values)
+	}
+
+	// An invalid custom value rolls back the whole save, new client or not
+	bad := draft
+	bad.Name = "Acme Renamed"
+	bad.Tags = []string{"retainer"}
+	bad.CustomValues = map[string]string{"hours": "lots"}
+	if _, err := SaveClient(bad); err == nil {
+		t.Error("expected error for an invalid custom value")
+	}
+	bad.ID = clientID
+	if _, err := SaveClient(bad); err == nil {
+		t.Error("expected error for an invalid custom value")
+	}
+	if n := countRows(t, "client"); n != 1 {
+		t.Errorf("expected failed saves to create no client, found %d", n)
+	}
+	if c, _ := GetClient(clientID); c.Name != "Acme" {
+		t.Errorf("expected failed saves to keep the name, got %q", c.Name)
+	}
+	if tags, _ := GetClientTags(clientID); len(tags) != 1 || tags[0] != "agency" {
+		t.Errorf("expected failed saves to keep the tags, got %v", tags)
+	}
+}
+
+// TestClientWithDetailsValidation tests that invalid contacts are rejected without saving anything
+func TestClientWithDetailsValidation(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	twoBilling := models.ClientDetails{Contacts: []models.Contact{
+		{Name: "Ann", Billing: true},
+		{Name: "Bob", Billing: true},
+	}}
+	if _, err := CreateClientWithDetails("Acme", nil, nil, twoBilling); err == nil {
+		t.Error("expected error for two billing contacts")
+	}
+
+	unnamed := models.ClientDetails{Contacts: []models.Contact{{Email: "x@example.com"}}}
+	if _, err := CreateClientWithDetails("Acme", nil, nil, unnamed); err == nil {
+		t.Error("expected error for unnamed contact")
+	}
+
+	if n := countRows(t, "client"); n != 0 {
+		t.Errorf("expected no clients to be created, got %d", n)
+	}
+}
+
+// TestEntityContactValidation tests that entity emails and phones are validated and phones normalised
+func TestEntityContactValidation(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	badEmail := "not-an-email"
+	if _, err := CreateClient("Acme", nil, &badEmail, nil); err == nil {
+		t.Error("expected error for invalid email")
+	}
+
+	badPhone := "call me"
+	if _, err := CreateProvider("Acme", nil, nil, &badPhone); err == nil {
+		t.Error("expected error for invalid phone")
+	}
+
+	phone := "0044 (20) 7946-0958"
+	id, err := CreateClient("Acme", nil, nil, &phone)
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	client, _ := GetClient(id)
+	if client.Phone == nil || *client.Phone != "+442079460958" {
+		t.Errorf("expected phone normalised to E.164, got %v", client.Phone)
+	}
+
+	if err := UpdateClient(id, "Acme", nil, &badEmail, nil); err == nil {
+		t.Error("expected error updating to an invalid email")
+	}
+}
+
+// TestClientDetailsValidation tests postal code and contact validation of client details
+func TestClientDetailsValidation(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	badPostal := models.ClientDetails{BillingAddress: models.Address{Country: "United States", PostalCode: "ABC"}}
+	if _, err := CreateClientWithDetails("Acme", nil, nil, badPostal); err == nil {
+		t.Error("expected error for invalid US postal code")
+	}
+
+	badContact := models.ClientDetails{Contacts: []models.Contact{{Name: "Ann", Email: "ann"}}}
+	if _, err := CreateClientWithDetails("Acme", nil, nil, badContact); err == nil {
+		t.Error("expected error for invalid contact email")
+	}
+
+	details := models.ClientDetails{
+		BillingAddress: models.Address{Country: "GB", PostalCode: "sw1a 1aa"},
+		Contacts:       []models.Contact{{Name: "Ann", Phone: "+44 20 7946 0958"}},
+	}
+	id, err := CreateClientWithDetails("Acme", nil, nil, details)
+	if err != nil {
+		t.Fatalf("CreateClientWithDetails failed: %v", err)
+	}
+	got, _ := GetClientDetails(id)
+	if got.BillingAddress.PostalCode != "SW1A 1AA" || got.Contacts[0].Phone != "+442079460958" {
+		t.Errorf("expected normalised postal code and phone, got %+v", got)
+	}
+	if details.Contacts[0].Phone != "+44 20 7946 0958" {
+		t.Error("normalising should not modify the caller's contacts")
+	}
+}
+
+// TestProviderProfileTaxID tests that EU VAT numbers are checked and compacted
+func TestProviderProfileTaxID(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Acme", nil, nil, nil)
+
+	if err := SetProviderProfile(providerID, models.ProviderProfile{TaxID: "DE136695977"}); err == nil {
+		t.Error("expected error for VAT number with a bad check digit")
+	}
+
+	if err := SetProviderProfile(providerID, models.ProviderProfile{TaxID: "de 136 695 976"}); err != nil {
+		t.Fatalf("SetProviderProfile failed: %v", err)
+	}
+	profile, _ := GetProviderProfile(providerID)
+	if profile.TaxID != "DE136695976" {
+		t.Errorf("expected compacted VAT number, got %q", profile.TaxID)
+	}
+}
+
+// TestSaveProvider tests saving a provider with its profile, numbering and custom fields in one call
+func TestSaveProvider(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	if _, err := CreateCustomField(models.CustomField{EntityType: models.EntityProvider, Key: "hours", Label: "Hours", Kind: models.FieldNumber}); err != nil {
+		t.Fatalf("CreateCustomField failed: %v", err)
+	}
+
+	draft := models.ProviderDraft{
+		Name:         "Acme",
+		Profile:      models.ProviderProfile{LegalName: "Acme Ltd"},
+		Numbering:    models.NumberingScheme{Format: "ACME-{seq:03}"},
+		CustomValues: map[string]string{"hours": "7.5"},
+	}
+	providerID, err := SaveProvider(draft)
+	if err != nil {
+		t.Fatalf("SaveProvider failed: %v", err)
+	}
+	profile, _ := GetProviderProfile(providerID)
+	scheme, _ := GetNumberingScheme(providerID)
+	values, _ := GetCustomValues(models.EntityProvider, providerID)
+	if profile.LegalName != "Acme Ltd" || scheme.Format != "ACME-{seq:03}" || values["hours"] != "7.5" {
+		t.Errorf("unexpected profile %+v, scheme %+v or custom values %v", profile, scheme, values)
+	}
+
+	// A failed step rolls back the whole save, new provider or not
+	bad := draft
+	bad.Name = "Acme Renamed"
+	bad.Profile.TaxID = "DE136695977"
+	if _, err := SaveProvider(bad); err == nil {
+		t.Error("expected error for VAT number with a bad check digit")
+	}
+	bad.ID = providerID
+	if _, err := SaveProvider(bad); err == nil {
+		t.Error("expected error for VAT number with a bad check digit")
+	}
+	bad.Profile.TaxID = ""
+	bad.Numbering = models.NumberingScheme{Format: "{seq}", Reset: numbering.ResetYearly}
+	if _, err := SaveProvider(bad); err == nil {
+		t.Error("expected error for a yearly reset without a year")
+	}
+	if n := countRows(t, "provider"); n != 1 {
+		t.Errorf("expected failed saves to create no provider, found %d", n)
+	}
+	if p, _ := GetProvider(providerID); p.Name != "Acme" {
+		t.Errorf("expected failed saves to keep the name, got %q", p.Name)
+	}
+}
+
+// TestCustomFields tests defining custom fields and storing values for clients and invoices
+func TestCustomFields(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	fields := []models.CustomField{
+		{EntityType: models.EntityClient, Key: "supplier_id", Label: "Supplier ID", Kind: models.FieldText},
+		{EntityType: models.EntityInvoice, Key: "po_number", Label: "PO Number", Kind: models.FieldText},
+		{EntityType: models.EntityInvoice, Key: "hours", Label: "Hours", Kind: models.FieldNumber},
+		{EntityType: models.EntityInvoice, Key: "due", Label: "Due", Kind: models.FieldDate},
+		{EntityType: models.EntityInvoice, Key: "cost_centre", Label: "Cost Centre", Kind: models.FieldSelect, Options: []string{"R&D", "Sales"}},
+	}
+	for _, f := range fields {
+		if _, err := CreateCustomField(f); err != nil {
+			t.Fatalf("CreateCustomField(%s) failed: %v", f.Key, err)
+		}
+	}
+
+	if _, err := CreateCustomField(fields[0]); err == nil {
+		t.Error("expected error for duplicate field key")
+	}
+	if _, err := CreateCustomField(models.CustomField{EntityType: models.EntityClient, Key: "Bad Key", Label: "x", Kind: models.FieldText}); err == nil {
+		t.Error("expected error for invalid field key")
+	}
+	if _, err := CreateCustomField(models.CustomField{EntityType: models.EntityInvoice, Key: "choice", Label: "Choice", Kind: models.FieldSelect}); err == nil {
+		t.Error("expected error for select field without options")
+	}
+
+	invoiceFields, err := ListCustomFields(models.EntityInvoice)
+	if err != nil {
+		t.Fatalf("ListCustomFields failed: %v", err)
+	}
+	if len(invoiceFields) != 4 || invoiceFields[3].Options[1] != "Sales" {
+		t.Fatalf("unexpected invoice fields: %+v", invoiceFields)
+	}
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	clientID, _ := CreateClient("Client", nil, nil, nil)
+	invoiceID, _ := CreateInvoice(providerID, clientID, false)
+	invoiceKey := fmt.Sprint(invoiceID)
+
+	invalid := []map[string]string{
+		{"hours": "lots"},
+		{"due": "31/01/2026"},
+		{"cost_centre": "Marketing"},
+		{"unknown": "x"},
+	}
+	saveValues := func(values map[string]string) error {
+		_, err := SaveInvoice(models.InvoiceDraft{ID: invoiceID, ProviderID: providerID, ClientID: clientID, CustomValues: values})
+		return err
+	}
+	for _, values := range invalid {
+		if err := saveValues(values); err == nil {
+			t.Errorf("expected error for %v", values)
+		}
+	}
+
+	err = saveValues(map[string]string{
+		"po_number":   "PO-991",
+		"hours":       "12.5",
+		"due":         "2026-01-31",
+		"cost_centre": "R&D",
+	})
+	if err != nil {
+		t.Fatalf("SaveInvoice failed: %v", err)
+	}
+	if _, err := SaveClient(models.ClientDraft{ID: clientID, Name: "Client", CustomValues: map[string]string{"supplier_id": "SUP-7"}}); err != nil {
+		t.Fatalf("SaveClient failed: %v", err)
+	}
+
+	// Clearing a value removes it
+	if err := saveValues(map[string]string{"po_number": "PO-991", "hours": ""}); err != nil {
+		t.Fatalf("SaveInvoice failed: %v", err)
+	}
+	values, _ := GetCustomValues(models.EntityInvoice, invoiceKey)
+	if len(values) != 1 || values["po_number"] != "PO-991" {
+		t.Errorf("expected only po_number to remain, got %v", values)
+	}
+
+	data, err := GetInvoiceData(invoiceID)
+	if err != nil {
+		t.Fatalf("GetInvoiceData failed: %v", err)
+	}
+	vars := data.TemplateVariables()
+	if vars["invoice.po_number"] != "PO-991" || vars["client.supplier_id"] != "SUP-7" {
+		t.Errorf("expected custom fields in template variables, got %v", vars)
+	}
+
+	// Values go away with their entity and with their field
+	deleteInvoice(t, invoiceID)
+	if err := DeleteCustomField(models.EntityClient, "supplier_id"); err != nil {
+		t.Fatalf("DeleteCustomField failed: %v", err)
+	}
+	if n := countRows(t, "custom_field_value"); n != 0 {
+		t.Errorf("expected custom values to be cleaned up, found %d", n)
+	}
+}
+
+func TestTags(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Provider", nil, nil, nil)
+	agencyID, _ := CreateClient("Agency Client", nil, nil, nil)
+	directID, _ := CreateClient("Direct Client", nil, nil, nil)
+	first, _ := CreateInvoice(providerID, agencyID, false)
+	second, _ := CreateInvoice(providerID, directID, false)
+
+	if _, err := SaveClient(models.ClientDraft{ID: agencyID, Name: "Agency Client", Tags: []string{"agency", "retainer"}}); err != nil {
+		t.Fatalf("SaveClient failed: %v", err)
+	}
+	// Tags are reused ignoring case
+	if _, err := SaveClient(models.ClientDraft{ID: directID, Name: "Direct Client", Tags: []string{"Retainer"}}); err != nil {
+		t.Fatalf("SaveClient failed: %v", err)
+	}
+	if _, err := SaveInvoice(models.InvoiceDraft{ID: first, ProviderID: providerID, ClientID: agencyID, Tags: []string{"retainer", "2026-Q3"}}); err != nil {
+		t.Fatalf("SaveInvoice failed: %v", err)
+	}
+	if _, err := SaveInvoice(models.InvoiceDraft{ID: second, ProviderID: providerID, ClientID: directID, Tags: []string{"2026-q3"}}); err != nil {
+		t.Fatalf("SaveInvoice failed: %v", err)
+	}
+
+	tags, err := ListTags()
+	if err != nil {
+		t.Fatalf("ListTags failed: %v", err)
+	}
+	want := []models.Tag{{Name: "2026-Q3", Count: 2}, {Name: "agency", Count: 1}, {Name: "retainer", Count: 3}}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("expected %+v, got %+v", want, tags)
+	}
+
+	clients, err := ListEntitiesMatching("client", EntityFilter{Tags: []string{"RETAINER", "agency"}})
+	if err != nil {
+		t.Fatalf("ListEntitiesMatching failed: %v", err)
+	}
+	if len(clients) != 1 || clients[0].ID != agencyID {
+		t.Errorf("expected only the agency client, got %+v", clients)
+	} else if !reflect.DeepEqual(clients[0].Tags, []string{"agency", "retainer"}) {
+		t.Errorf("expected client tags to be listed, got %v", clients[0].Tags)
+	}
+
+	invoices, err := ListInvoicesMatching(InvoiceFilter{Tags: []string{"2026-Q3"}})
+	if err != nil {
+		t.Fatalf("ListInvoicesMatching failed: %v", err)
+	}
+	if len(invoices) != 2 {
+		t.Errorf("expected 2 invoices tagged 2026-Q3, got %d", len(invoices))
+	}
+	invoices, _ = ListInvoicesMatching(InvoiceFilter{Tags: []string{"2026-Q3", "retainer"}})
+	if len(invoices) != 1 || invoices[0].ID != first {
+		t.Errorf("expected only the first invoice, got %+v", invoices)
+	}
+
+	if _, err := ListEntitiesMatching("provider", EntityFilter{Tags: []string{"agency"}}); err == nil {
+		t.Error("expected error filtering providers by tag")
+	}
+
+	// Unused tags are removed
+	if _, err := SaveClient(models.ClientDraft{ID: agencyID, Name: "Agency Client", Tags: []string{"retainer"}}); err != nil {
+		t.Fatalf("SaveClient failed: %v", err)
+	}
+	tags, _ = ListTags()
+	if len(tags) != 2 {
+		t.Errorf("expected the agency tag to be pruned, got %+v", tags)
+	}
+
+	data, err := GetInvoiceData(first)
+	if err != nil {
+		t.Fatalf("GetInvoiceData failed: %v", err)
+	}
+	if !reflect.DeepEqual(data.Tags, []string{"2026-Q3", "retainer"}) {
+		t.Errorf("expected invoice tags in invoice data, got %v", data.Tags)
+	}
+}
+
+func TestListInvoicesMatching(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	acmeID, _ := CreateProvider("Acme Studio", nil, nil, nil)
+	otherID, _ := CreateProvider("Other Ltd", nil, nil, nil)
+	clientID, _ := CreateClient("Globex", nil, nil, nil)
+
+	draft, _ := SaveInvoiceWithItems(0, acmeID, clientID, false, []models.InvoiceItem{
+		{ItemName: "Logo design", Amount: 1, CostPerUnit: 500},
+	})
+	issued, _ := SaveInvoiceWithItems(0, acmeID, clientID, false, []models.InvoiceItem{
+		{ItemName: "Hosting 100%", Amount: 12, CostPerUnit: 10},
+	})
+	paid, _ := SaveInvoiceWithItems(0, otherID, clientID, true, []models.InvoiceItem{
+		{ItemName: "Consulting", Amount: 10, CostPerUnit: 150},
+	})
+	number, err := IssueInvoice(issued)
+	if err != nil {
+		t.Fatalf("IssueInvoice failed: %v", err)
+	}
+	if _, err := db.Exec("UPDATE invoice SET date_created = '2026-01-15 10:00:00' WHERE id = ?", draft); err != nil {
+		t.Fatalf("failed to backdate invoice: %v", err)
+	}
+
+	ids := func(filter InvoiceFilter) []int {
+		t.Helper()
+		invoices, err := ListInvoicesMatching(filter)
+		if err != nil {
+			t.Fatalf("ListInvoicesMatching(%+v) failed: %v", filter, err)
+		}
+		var ids []int
+		for _, inv := range invoices {
+			ids = append(ids, inv.ID)
+		}
+		slices.Sort(ids)
+		return ids
+	}
+
+	amount := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name   string
+		filter InvoiceFilter
+		want   []int
+	}{
+		{"everything", InvoiceFilter{}, []int{draft, issued, paid}},
+		{"item name", InvoiceFilter{Search: "logo"}, []int{draft}},
+		{"provider name", InvoiceFilter{Search: "ACME"}, []int{draft, issued}},
+		{"invoice number", InvoiceFilter{Search: number}, []int{issued}},
+		{"wildcards are literal", InvoiceFilter{Search: "100%"}, []int{issued}},
+		{"draft", InvoiceFilter{Status: models.StatusDraft}, []int{draft}},
+		{"issued", InvoiceFilter{Status: models.StatusIssued}, []int{issued}},
+		{"paid", InvoiceFilter{Status: models.StatusPaid}, []int{paid}},
+		{"provider", InvoiceFilter{ProviderID: otherID}, []int{paid}},
+		{"client", InvoiceFilter{ClientID: clientID}, []int{draft, issued, paid}},
+		{"until", InvoiceFilter{To: time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)}, []int{draft}},
+		{"from", InvoiceFilter{From: time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC)}, []int{issued, paid}},
+		{"min total", InvoiceFilter{MinTotal: amount(500)}, []int{draft, paid}},
+		{"total range", InvoiceFilter{MinTotal: amount(100), MaxTotal: amount(500)}, []int{draft, issued}},
+		{"combined", InvoiceFilter{Search: "acme", Status: models.StatusDraft, MaxTotal: amount(500)}, []int{draft}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(tt.filter); !slices.Equal(got, tt.want) {
+				t.Errorf("expected invoices %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := ListInvoicesMatching(InvoiceFilter{Status: "overdue"}); err == nil {
+		t.Error("expected error for unknown status")
+	}
+}
+
+func TestListInvoicesSorted(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	zedID, _ := CreateProvider("Zed", nil, nil, nil)
+	acmeID, _ := CreateProvider("acme", nil, nil, nil)
+	midID, _ := CreateProvider("Mid", nil, nil, nil)
+	betaID, _ := CreateClient("beta", nil, nil, nil)
+	alphaID, _ := CreateClient("Alpha", nil, nil, nil)
+	gammaID, _ := CreateClient("gamma", nil, nil, nil)
+
+	draft, _ := SaveInvoiceWithItems(0, zedID, betaID, false, []models.InvoiceItem{
+		{ItemName: "Design", Amount: 1, CostPerUnit: 500},
+	})
+	issued, _ := SaveInvoiceWithItems(0, acmeID, alphaID, false, []models.InvoiceItem{
+		{ItemName: "Hosting", Amount: 12, CostPerUnit: 10},
+	})
+	paid, _ := SaveInvoiceWithItems(0, midID, gammaID, true, []models.InvoiceItem{
+		{ItemName: "Consulting", Amount: 10, CostPerUnit: 150},
+	})
+	if _, err := IssueInvoice(issued); err != nil {
+		t.Fatalf("IssueInvoice failed: %v", err)
+	}
+	for id, created := range map[int]string{draft: "2026-01-15", issued: "2026-01-20", paid: "2026-01-10"} {
+		if _, err := db.Exec("UPDATE invoice SET date_created = ? WHERE id = ?", created+" 10:00:00", id); err != nil {
+			t.Fatalf("failed to backdate invoice: %v", err)
+		}
+	}
+	draftDue := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	paidDue := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	if err := SetInvoiceDueDate(draft, &draftDue); err != nil {
+		t.Fatalf("SetInvoiceDueDate failed: %v", err)
+	}
+	if err := SetInvoiceDueDate(paid, &paidDue); err != nil {
+		t.Fatalf("SetInvoiceDueDate failed: %v", err)
+	}
+
+	tests := []struct {
+		sort InvoiceSort
+		want []int
+	}{
+		{InvoiceSort{}, []int{issued, draft, paid}},
+		{InvoiceSort{Column: SortByNumber}, []int{issued, draft, paid}},
+		{InvoiceSort{Column: SortByNumber, Desc: true}, []int{issued, paid, draft}},
+		{InvoiceSort{Column: SortByDate}, []int{paid, draft, issued}},
+		{InvoiceSort{Column: SortByClient}, []int{issued, draft, paid}},
+		{InvoiceSort{Column: SortByProvider}, []int{issued, paid, draft}},
+		{InvoiceSort{Column: SortByTotal}, []int{issued, draft, paid}},
+		{InvoiceSort{Column: SortByTotal, Desc: true}, []int{paid, draft, issued}},
+		{InvoiceSort{Column: SortByStatus}, []int{draft, issued, paid}},
+		{InvoiceSort{Column: SortByDueDate}, []int{paid, draft, issued}},
+		{InvoiceSort{Column: SortByDueDate, Desc: true}, []int{draft, paid, issued}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%+v", tt.sort), func(t *testing.T) {
+			invoices, err := ListInvoicesSorted(InvoiceFilter{}, tt.sort)
+			if err != nil {
+				t.Fatalf("ListInvoicesSorted failed: %v", err)
+			}
+			var got []int
+			for _, inv := range invoices {
+				got = append(got, inv.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected invoices %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	invoices, _ := ListInvoicesSorted(InvoiceFilter{}, InvoiceSort{Column: SortByTotal})
+	if invoices[2].Total != 1500 {
+		t.Errorf("expected total 1500, got %v", invoices[2].Total)
+	}
+	if invoices[2].DueDate == nil || !invoices[2].DueDate.Equal(paidDue) {
+		t.Errorf("expected due date %v, got %v", paidDue, invoices[2].DueDate)
+	}
+
+	if _, err := ListInvoicesSorted(InvoiceFilter{}, InvoiceSort{Column: "colour"}); err == nil {
+		t.Error("expected error for unknown sort column")
+	}
+}
+
+func TestListEntitiesMatchingSearch(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	email := "billing@globex.example"
+	globexID, _ := CreateClient("Globex", nil, &email, nil)
+	CreateClient("Initech", nil, nil, nil)
+	archivedID, _ := CreateClient("Globex Archive", nil, nil, nil)
+	ArchiveClient(archivedID)
+
+	clients, err := ListEntitiesMatching("client", EntityFilter{Search: "GLOBEX"})
+	if err != nil {
+		t.Fatalf("ListEntitiesMatching failed: %v", err)
+	}
+	if len(clients) != 1 || clients[0].ID != globexID {
+		t.Errorf("expected only Globex, got %+v", clients)
+	}
+
+	clients, _ = ListEntitiesMatching("client", EntityFilter{Search: "globex", IncludeArchived: true})
+	if len(clients) != 2 {
+		t.Errorf("expected archived match to be included, got %d clients", len(clients))
+	}
+
+	clients, _ = ListEntitiesMatching("client", EntityFilter{Search: "billing@"})
+	if len(clients) != 1 || clients[0].ID != globexID {
+		t.Errorf("expected email match, got %+v", clients)
+	}
+
+	clients, _ = ListEntitiesMatching("client", EntityFilter{Search: "_"})
+	if len(clients) != 0 {
+		t.Errorf("expected '_' to match literally, got %+v", clients)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	email := "accounts@globex.example"
+	providerID, _ := CreateProvider("Acme Studio", nil, nil, nil)
+	clientID, _ := CreateClientWithDetails("Globex", &email, nil, models.ClientDetails{
+		Contacts: []models.Contact{{Name: "Hank Scorpio", Email: "hank@globex.example", Billing: true}},
+	})
+	logoID, _ := SaveInvoiceWithItems(0, providerID, clientID, false, []models.InvoiceItem{
+		{ItemName: "Logo redesign", Amount: 1, CostPerUnit: 900},
+	})
+	hostingID, _ := SaveInvoiceWithItems(0, providerID, clientID, false, []models.InvoiceItem{
+		{ItemName: "Hosting", Amount: 12, CostPerUnit: 10},
+	})
+	if err := SetInvoiceNotes(hostingID, "Includes the redesigned landing page"); err != nil {
+		t.Fatalf("SetInvoiceNotes failed: %v", err)
+	}
+
+	results, err := Search("redesign", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected an item and an invoice note to match, got %+v", results)
+	}
+	// Matches in titles rank above matches in the rest of the text
+	if results[0].Kind != models.SearchItem || results[0].InvoiceID != logoID || results[0].ClientName != "Globex" {
+		t.Errorf("expected the item first, got %+v", results[0])
+	}
+	if results[1].Kind != models.SearchInvoice || results[1].InvoiceID != hostingID || results[1].Snippet == "" {
+		t.Errorf("expected the invoice note second, got %+v", results[1])
+	}
+
+	results, _ = Search("glob", 10)
+	kinds := map[models.SearchKind]string{}
+	for _, r := range results {
+		kinds[r.Kind] = r.EntityID
+	}
+	if kinds[models.SearchClient] != clientID || kinds[models.SearchContact] != clientID {
+		t.Errorf("expected client and contact matches pointing at the client, got %+v", results)
+	}
+
+	// Query syntax in the input is treated as plain text
+	if _, err := Search(`logo" OR (`, 10); err != nil {
+		t.Errorf("expected punctuation to be ignored, got %v", err)
+	}
+	if results, _ := Search("   ", 10); results != nil {
+		t.Errorf("expected no results for a blank query, got %+v", results)
+	}
+
+	// The index follows updates, deletes and the trash
+	if _, err := SaveInvoiceWithItems(logoID, providerID, clientID, false, []models.InvoiceItem{
+		{ItemName: "Brand guidelines", Amount: 1, CostPerUnit: 900},
+	}); err != nil {
+		t.Fatalf("SaveInvoiceWithItems failed: %v", err)
+	}
+	if results, _ := Search("logo", 10); len(results) != 0 {
+		t.Errorf("expected renamed item to be gone from the index, got %+v", results)
+	}
+	if err := TrashInvoice(hostingID); err != nil {
+		t.Fatalf("TrashInvoice failed: %v", err)
+	}
+	if results, _ := Search("landing", 10); len(results) != 0 {
+		t.Errorf("expected trashed invoice to be left out, got %+v", results)
+	}
+	if err := UpdateClient(clientID, "Initech", nil, &email, nil); err != nil {
+		t.Fatalf("UpdateClient failed: %v", err)
+	}
+	if results, _ := Search("initech", 10); len(results) != 1 || results[0].Kind != models.SearchClient {
+		t.Errorf("expected renamed client in the index, got %+v", results)
+	}
+}
+
+func TestSearchAddressesAndCustomValues(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	if _, err := CreateCustomField(models.CustomField{EntityType: models.EntityClient, Key: "region", Label: "Sales region", Kind: models.FieldText}); err != nil {
+		t.Fatalf("CreateCustomField failed: %v", err)
+	}
+	if _, err := CreateCustomField(models.CustomField{EntityType: models.EntityInvoice, Key: "po", Label: "PO", Kind: models.FieldText}); err != nil {
+		t.Fatalf("CreateCustomField failed: %v", err)
+	}
+	providerID, _ := CreateProvider("Acme Studio", nil, nil, nil)
+	clientID, err := SaveClient(models.ClientDraft{
+		Name: "Globex",
+		Details: models.ClientDetails{
+			BillingAddress:  models.Address{Line1: "1 Cypress Creek Road", City: "Springfield"},
+			ShippingAddress: models.Address{Line1: "Volcano Lair", Country: "Lilliput"},
+		},
+		CustomValues: map[string]string{"region": "Northwest"},
+	})
+	if err != nil {
+		t.Fatalf("SaveClient failed: %v", err)
+	}
+	invoiceID, err := SaveInvoice(models.InvoiceDraft{ProviderID: providerID, ClientID: clientID, CustomValues: map[string]string{"po": "PO-7781"}})
+	if err != nil {
+		t.Fatalf("SaveInvoice failed: %v", err)
+	}
+
+	// Address and custom value matches point at their record, titled with its name
+	tests := []struct {
+		query string
+		kind  models.SearchKind
+		title string
+	}{
+		{"cypress creek", models.SearchClient, "Globex"},
+		{"lilliput", models.SearchClient, "Globex"},
+		{"northwest", models.SearchClient, "Globex"},
+		{"7781", models.SearchInvoice, fmt.Sprintf("#%d", invoiceID)},
+	}
+	for _, tt := range tests {
+		results, err := Search(tt.query, 10)
+		if err != nil {
+			t.Fatalf("Search(%q) failed: %v", tt.query, err)
+		}
+		if len(results) != 1 || results[0].Kind != tt.kind || results[0].Title != tt.title || results[0].Snippet == "" {
+			t.Errorf("Search(%q): expected one %s match titled %q, got %+v", tt.query, tt.kind, tt.title, results)
+			continue
+		}
+		if tt.kind == models.SearchInvoice && results[0].InvoiceID != invoiceID {
+			t.Errorf("Search(%q): expected invoice %d, got %+v", tt.query, invoiceID, results[0])
+		}
+		if tt.kind == models.SearchClient && results[0].EntityID != clientID {
+			t.Errorf("Search(%q): expected client %s, got %+v", tt.query, clientID, results[0])
+		}
+	}
+
+	// Changed addresses and values replace the old ones in the index
+	if _, err := SaveClient(models.ClientDraft{
+		ID:      clientID,
+		Name:    "Initech",
+		Details: models.ClientDetails{BillingAddress: models.Address{Line1: "4120 Freidrich Lane"}},
+	}); err != nil {
+		t.Fatalf("SaveClient failed: %v", err)
+	}
+	for _, query := range []string{"cypress", "lilliput", "northwest"} {
+		if results, _ := Search(query, 10); len(results) != 0 {
+			t.Errorf("Search(%q): expected the old address and value to be gone, got %+v", query, results)
+		}
+	}
+	if results, _ := Search("freidrich", 10); len(results) != 1 || results[0].Title != "Initech" {
+		t.Errorf("expected the new address under the client's new name, got %+v", results)
+	}
+}
+
+func TestListInvoicesPage(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Acme", nil, nil, nil)
+	var clients []string
+	for _, name := range []string{"Delta", "alpha", "Charlie"} {
+		id, _ := CreateClient(name, nil, nil, nil)
+		clients = append(clients, id)
+	}
+	for i := range 8 {
+		id, err := SaveInvoiceWithItems(0, providerID, clients[i%3], i%4 == 0, []models.InvoiceItem{
+			{ItemName: "Work", Amount: float64(i%3 + 1), CostPerUnit: 100},
+		})
+		if err != nil {
+			t.Fatalf("SaveInvoiceWithItems failed: %v", err)
+		}
+		if i%2 == 0 {
+			if _, err := IssueInvoice(id); err != nil {
+				t.Fatalf("IssueInvoice failed: %v", err)
+			}
+		}
+	}
+
+	ids := func(invoices []models.InvoiceSummary) []int {
+		var ids []int
+		for _, inv := range invoices {
+			ids = append(ids, inv.ID)
+		}
+		return ids
+	}
+
+	for _, column := range append([]InvoiceSortColumn{""}, SortByNumber, SortByClient, SortByTotal, SortByStatus) {
+		for _, desc := range []bool{false, true} {
+			sort := InvoiceSort{Column: column, Desc: desc}
+			t.Run(fmt.Sprintf("%+v", sort), func(t *testing.T) {
+				all, err := ListInvoicesSorted(InvoiceFilter{}, sort)
+				if err != nil {
+					t.Fatalf("ListInvoicesSorted failed: %v", err)
+				}
+				want := ids(all)
+
+				// Forward from the start
+				var forward []int
+				cursor := PageCursor{}
+				for {
+					page, err := ListInvoicesPage(InvoiceFilter{}, sort, cursor, 3)
+					if err != nil {
+						t.Fatalf("ListInvoicesPage failed: %v", err)
+					}
+					if len(page) == 0 {
+						break
+					}
+					forward = append(forward, ids(page)...)
+					cursor.ID = strconv.Itoa(page[len(page)-1].ID)
+				}
+				if !slices.Equal(forward, want) {
+					t.Errorf("paging forward gave %v, want %v", forward, want)
+				}
+
+				// Backward from the end
+				var backward []int
+				cursor = PageCursor{Backward: true}
+				for {
+					page, err := ListInvoicesPage(InvoiceFilter{}, sort, cursor, 3)
+					if err != nil {
+						t.Fatalf("ListInvoicesPage failed: %v", err)
+					}
+					if len(page) == 0 {
+						break
+					}
+					backward = append(ids(page), backward...)
+					cursor.ID = strconv.Itoa(page[0].ID)
+				}
+				if !slices.Equal(backward, want) {
+					t.Errorf("paging backward gave %v, want %v", backward, want)
+				}
+			})
+		}
+	}
+
+	// A page after a filtered-out invoice starts where that invoice would have been
+	all, _ := ListInvoicesSorted(InvoiceFilter{}, InvoiceSort{})
+	paid, _ := ListInvoicesSorted(InvoiceFilter{Status: models.StatusPaid}, InvoiceSort{})
+	page, err := ListInvoicesPage(InvoiceFilter{Status: models.StatusPaid}, InvoiceSort{}, PageCursor{ID: strconv.Itoa(all[0].ID)}, 10)
+	if err != nil {
+		t.Fatalf("ListInvoicesPage failed: %v", err)
+	}
+	if want := ids(paid); all[0].Paid || !slices.Equal(ids(page), want) {
+		t.Errorf("expected the paid invoices %v after an unpaid cursor, got %v", want, ids(page))
+	}
+
+	count, err := CountInvoices(InvoiceFilter{Search: "alpha"})
+	if err != nil {
+		t.Fatalf("CountInvoices failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 invoices for alpha, got %d", count)
+	}
+}
+
+func TestListEntitiesPage(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	var want []string
+	for i := range 5 {
+		id, err := CreateClient(fmt.Sprintf("Client %d", i), nil, nil, nil)
+		if err != nil {
+			t.Fatalf("CreateClient failed: %v", err)
+		}
+		want = append(want, id)
+	}
+	if err := ArchiveEntity("client", want[2]); err != nil {
+		t.Fatalf("ArchiveEntity failed: %v", err)
+	}
+
+	page, err := ListEntitiesPage("client", EntityFilter{}, PageCursor{ID: want[0]}, 2)
+	if err != nil {
+		t.Fatalf("ListEntitiesPage failed: %v", err)
+	}
+	if len(page) != 2 || page[0].ID != want[1] || page[1].ID != want[3] {
+		t.Errorf("expected clients 1 and 3 after client 0, got %+v", page)
+	}
+
+	page, err = ListEntitiesPage("client", EntityFilter{IncludeArchived: true}, PageCursor{Backward: true}, 2)
+	if err != nil {
+		t.Fatalf("ListEntitiesPage failed: %v", err)
+	}
+	if len(page) != 2 || page[0].ID != want[3] || page[1].ID != want[4] {
+		t.Errorf("expected the last two clients in order, got %+v", page)
+	}
+
+	count, err := CountEntities("client", EntityFilter{})
+	if err != nil {
+		t.Fatalf("CountEntities failed: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("expected 4 clients that are not archived, got %d", count)
+	}
+}
+
+func TestFindInvoicesByNumber(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Acme Studio", nil, nil, nil)
+	clientID, _ := CreateClient("Globex", nil, nil, nil)
+	if err := SetNumberingScheme(providerID, models.NumberingScheme{Format: "INV-{seq:04}", Reset: numbering.ResetNever}); err != nil {
+		t.Fatalf("SetNumberingScheme failed: %v", err)
+	}
+	items := []models.InvoiceItem{{ItemName: "Work", Amount: 1, CostPerUnit: 100}}
+	issuedID, _ := SaveInvoiceWithItems(0, providerID, clientID, false, items)
+	if _, err := IssueInvoice(issuedID); err != nil {
+		t.Fatalf("IssueInvoice failed: %v", err)
+	}
+	draftID, _ := SaveInvoiceWithItems(0, providerID, clientID, false, items)
+
+	invoices, err := FindInvoicesByNumber("0001", 5)
+	if err != nil {
+		t.Fatalf("FindInvoicesByNumber failed: %v", err)
+	}
+	if len(invoices) != 1 || invoices[0].ID != issuedID {
+		t.Errorf("expected the issued invoice to match part of its number, got %+v", invoices)
+	}
+
+	invoices, _ = FindInvoicesByNumber(fmt.Sprintf("#%d", draftID), 5)
+	if len(invoices) == 0 || invoices[0].ID != draftID {
+		t.Errorf("expected the draft first for its internal ID, got %+v", invoices)
+	}
+
+	if err := TrashInvoice(issuedID); err != nil {
+		t.Fatalf("TrashInvoice failed: %v", err)
+	}
+	if invoices, _ := FindInvoicesByNumber("INV", 5); len(invoices) != 0 {
+		t.Errorf("expected trashed invoices to be left out, got %+v", invoices)
+	}
+}
+
+func TestSetInvoicePaid(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Acme Studio", nil, nil, nil)
+	clientID, _ := CreateClient("Globex", nil, nil, nil)
+	invoiceID, _ := SaveInvoiceWithItems(0, providerID, clientID, false, nil)
+
+	if err := SetInvoicePaid(invoiceID, true); err != nil {
+		t.Fatalf("SetInvoicePaid failed: %v", err)
+	}
+	data, _ := GetInvoiceData(invoiceID)
+	if !data.Paid {
+		t.Error("expected the invoice to be paid")
+	}
+	if err := SetInvoicePaid(invoiceID+1, true); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows for a missing invoice, got %v", err)
+	}
+}
+
+func TestRecentEntities(t *testing.T) {
+	setupTestDB(t)
+	defer teardownTestDB(t)
+
+	providerID, _ := CreateProvider("Acme Studio", nil, nil, nil)
+	clientID, _ := CreateClient("Globex", nil, nil, nil)
+	invoiceID, _ := SaveInvoiceWithItems(0, providerID, clientID, true, nil)
+
+	for _, r := range []struct {
+		kind models.SearchKind
+		id   string
+	}{
+		{models.SearchProvider, providerID},
+		{models.SearchInvoice, strconv.Itoa(invoiceID)},
+		{models.SearchClient, clientID},
+		// Using the provider again makes it the most recent
+		{models.SearchProvider, providerID},
+	} {
+		if err := RecordRecent(r.kind, r.id); err != nil {
+			t.Fatalf("RecordRecent failed: %v", err)
+		}
+	}
+
+	recent, err := ListRecent(10)
+	if err != nil {
+		t.Fatalf("ListRecent failed: %v", err)
+	}
+	if len(recent) != 3 {
+		t.Fatalf("expected three recent records, got %+v", recent)
+	}
+	if recent[0].Kind != models.SearchProvider || recent[0].EntityID != providerID || recent[0].Name != "Acme Studio" {
+		t.Errorf("expected the provider first, got %+v", recent[0])
+	}
+	if recent[1].Kind != models.SearchClient || recent[1].Name != "Globex" {
+		t.Errorf("expected the client second, got %+v", recent[1])
+	}
+	if recent[2].Kind != models.SearchInvoice || recent[2].InvoiceID != invoiceID || recent[2].Name != "Globex" || !recent[2].Paid {
+		t.Errorf("expected the paid invoice for Globex last, got %+v", recent[2])
+	}
+
+	if err := TrashInvoice(invoiceID); err != nil {
+		t.Fatalf("TrashInvoice failed: %v", err)
+	}
+	if recent, _ := ListRecent(10); len(recent) != 2 {
+		t.Errorf("expected the trashed invoice to be left out, got %+v", recent)
+	}
+	if err := RecordRecent("item", "1"); err == nil {
+		t.Error("expected an error remembering an item")
+	}
+}
@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/GVPproj/termsheet/models"
+)
+
+// tagLink describes a many-to-many table linking tags to clients or invoices
+type tagLink struct {
+	table  string
+	column string
+}
+
+var (
+	clientTags  = tagLink{table: "client_tag", column: "client_id"}
+	invoiceTags = tagLink{table: "invoice_tag", column: "invoice_id"}
+)
+
+// ListTags returns every tag in use with how many clients and invoices carry it, by name
+func ListTags() ([]models.Tag, error) {
+	rows, err := db.Query(`
+		SELECT t.name, COUNT(*)
+		FROM tag t
+		JOIN (
+			SELECT tag_id FROM client_tag
+			UNION ALL
+			SELECT tag_id FROM invoice_tag
+		) l ON l.tag_id = t.id
+		GROUP BY t.id
+		ORDER BY t.name COLLATE NOCASE
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []models.Tag
+	for rows.Next() {
+		var t models.Tag
+		if err := rows.Scan(&t.Name, &t.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+
+	return tags, rows.Err()
+}
+
+// GetClientTags returns a client's tags by name
+func GetClientTags(clientID string) ([]string, error) {
+	return getTags(clientTags, clientID)
+}
+
+// GetInvoiceTags returns an invoice's tags by name
+func GetInvoiceTags(invoiceID int) ([]string, error) {
+	return getTags(invoiceTags, invoiceID)
+}
+
+// getTags returns the tags linked to ownerID by name
+func getTags(link tagLink, ownerID any) ([]string, error) {
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT t.name
+		FROM %s l
+		JOIN tag t ON t.id = l.tag_id
+		WHERE l.%s = ?
+		ORDER BY t.name COLLATE NOCASE
+	`, link.table, link.column), ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tags = append(tags, name)
+	}
+
+	return tags, rows.Err()
+}
+
+// setTags replaces the tags linked to ownerID as part of saving it, creating new tags
+// as needed and removing tags that are no longer used anywhere
+func setTags(tx *sql.Tx, link tagLink, ownerID any, tags []string) error {
+	if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s = ?", link.table, link.column), ownerID); err != nil {
+		return err
+	}
+
+	for _, name := range tags {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		// Tag names are unique ignoring case, so "Agency" reuses an existing "agency"
+		if _, err := tx.Exec("INSERT INTO tag (name) VALUES (?) ON CONFLICT (name) DO NOTHING", name); err != nil {
+			return err
+		}
+		_, err := tx.Exec(fmt.Sprintf(
+			"INSERT OR IGNORE INTO %s (%s, tag_id) SELECT ?, id FROM tag WHERE name = ?",
+			link.table, link.column,
+		), ownerID, name)
+		if err != nil {
+			return fmt.Errorf("tagging with %s: %w", name, err)
+		}
+	}
+
+	_, err := tx.Exec(`
+		DELETE FROM tag
+		WHERE id NOT IN (SELECT tag_id FROM client_tag)
+			AND id NOT IN (SELECT tag_id FROM invoice_tag)
+	`)
+	return err
+}
+
+// tagsColumn returns a select expression listing the tags of the row whose ID is idExpr,
+// newline-separated; convert it with splitTags
+func tagsColumn(link tagLink, idExpr string) string {
+	return fmt.Sprintf(`(
+		SELECT GROUP_CONCAT(t.name, char(10))
+		FROM %s l JOIN tag t ON t.id = l.tag_id
+		WHERE l.%s = %s
+	)`, link.table, link.column, idExpr)
+}
+
+// splitTags turns the value of a tagsColumn into sorted tag names
+func splitTags(concatenated sql.NullString) []string {
+	if !concatenated.Valid || concatenated.String == "" {
+		return nil
+	}
+	tags := strings.Split(concatenated.String, "\n")
+	slices.SortFunc(tags, func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+	return tags
+}
+
+// tagFilter returns a condition matching rows whose ID (idExpr) carries every one of tags
+func tagFilter(link tagLink, idExpr string, tags []string) (string, []any) {
+	placeholders := make([]string, len(tags))
+	args := make([]any, 0, len(tags)+1)
+	for i, t := range tags {
+		placeholders[i] = "?"
+		args = append(args, t)
+	}
+	args = append(args, len(tags))
+
+	return fmt.Sprintf(`%s IN (
+		SELECT l.%s
+		FROM %s l JOIN tag t ON t.id = l.tag_id
+		WHERE t.name IN (%s)
+		GROUP BY l.%s
+		HAVING COUNT(DISTINCT t.id) = ?
+	)`, idExpr, link.column, link.table, strings.Join(placeholders, ", "), link.column), args
+}
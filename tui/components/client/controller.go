@@ -2,13 +2,20 @@
 package client
 
 import (
-	"log"
+	"fmt"
+	"strconv"
 
 	"github.com/GVPproj/termsheet/models"
 	"github.com/GVPproj/termsheet/storage"
+	"github.com/GVPproj/termsheet/tui/components/palette"
+	"github.com/GVPproj/termsheet/tui/components/status"
+	"github.com/GVPproj/termsheet/tui/components/table"
 	"github.com/GVPproj/termsheet/tui/forms"
+	"github.com/GVPproj/termsheet/tui/keys"
+	"github.com/GVPproj/termsheet/tui/router"
 	"github.com/GVPproj/termsheet/tui/views"
 	"github.com/GVPproj/termsheet/types"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
 )
@@ -16,14 +23,35 @@ import (
 // Controller manages client-related state and behavior
 type Controller struct {
 	// Form state
-	form      *huh.Form
-	selection string
+	form *huh.Form
+
+	// Client list; a table rather than a form so long lists are paged
+	list table.Model
+	// Terminal size last given to Resize
+	windowWidth  int
+	windowHeight int
 
 	// Client form fields
-	name    string
-	address string
-	email   string
-	phone   string
+	name  string
+	email string
+	phone string
+
+	// Structured addresses; the shipping address is only saved when separateShipping is set
+	billing          models.Address
+	shipping         models.Address
+	separateShipping bool
+
+	// Custom field values
+	custom *forms.CustomFieldInputs
+
+	// Comma-separated tags, see models.ParseTags
+	tags string
+
+	// Contacts being edited; saved together with the client
+	contacts         []models.Contact
+	contact          models.Contact
+	contactIndex     int
+	contactSelection string
 
 	// Edit state
 	selectedID string
@@ -31,22 +59,66 @@ type Controller struct {
 	// Delete confirmation
 	deleteConfirmed bool
 	deleteID        string
+
+	// Whether archived entries are listed
+	showArchived bool
+
+	// Free-text search and tags the list is filtered by; empty lists every client
+	search    string
+	tagFilter []string
 }
 
 // NewController creates a new client controller
 func NewController() *Controller {
-	return &Controller{}
+	return &Controller{list: views.NewEntityTable("client")}
 }
 
-// InitListView initializes the client list view
-func (c *Controller) InitListView() (*huh.Form, error) {
-	c.selection = ""
-	clientListForm, err := views.CreateClientListForm(&c.selection)
-	if err != nil {
-		return nil, err
+// InitListView shows the client list with no search or tag filter applied
+func (c *Controller) InitListView() (*types.ViewTransition, tea.Cmd) {
+	c.search = ""
+	c.tagFilter = nil
+	c.list = views.NewEntityTable("client")
+	c.resizeList()
+	return c.showList()
+}
+
+// Resize fits the client list to a terminal of the given size; zeros leave the list at its default size
+func (c *Controller) Resize(width, height int) {
+	c.windowWidth, c.windowHeight = width, height
+	c.resizeList()
+}
+
+// resizeList fits the list to the terminal size last given to Resize
+func (c *Controller) resizeList() {
+	width, height := views.ListTableSize(c.windowWidth, c.windowHeight)
+	c.list.SetWidth(width)
+	if height > 0 {
+		c.list.SetHeight(height)
+	}
+}
+
+// MenuEntry lists the clients in the main menu
+func (c *Controller) MenuEntry() router.MenuEntry {
+	return router.MenuEntry{Key: "Clients", Label: "Clients - Who is paying?", Open: c.InitListView}
+}
+
+// Routes lists the client views and how they are drawn
+func (c *Controller) Routes() []router.Route {
+	return []router.Route{
+		{View: types.ClientsListView, Title: "Clients", Render: c.renderList, Shortcuts: true, Keys: views.ClientListKeys()},
+		{View: types.ClientCreateView, Title: "New client", Render: views.RenderClients, Prompt: true},
+		{View: types.ClientEditView, Title: "Edit client", Render: views.RenderClients, Prompt: true},
+		{View: types.ClientDeleteConfirmView, Title: "Delete", Render: views.RenderDeleteConfirm, Prompt: true},
+		{View: types.ClientContactsView, Title: "Contacts", Render: views.RenderClientContacts},
+		{View: types.ClientContactEditView, Title: "Contact", Render: views.RenderClients, Prompt: true},
+		{View: types.ClientTagFilterView, Title: "Tags", Render: views.RenderClients, Prompt: true},
+		{View: types.ClientFilterView, Title: "Filter", Render: views.RenderClients, Prompt: true},
 	}
-	c.form = clientListForm
-	return c.form, nil
+}
+
+// renderList draws the client list, which is a table rather than a form
+func (c *Controller) renderList(_ *huh.Form, _ int) string {
+	return views.RenderClientList(views.EntityList{Table: c.list, Filter: c.listFilter()})
 }
 
 // Update handles client-related messages and returns view transition if needed
@@ -55,74 +127,106 @@ func (c *Controller) Update(msg tea.Msg, currentView types.View) (*types.ViewTra
 	case types.ClientsListView:
 		return c.handleListView(msg)
 	case types.ClientCreateView, types.ClientEditView:
-		return c.handleFormView(msg, currentView)
+		return c.handleFormView(msg)
 	case types.ClientDeleteConfirmView:
 		return c.handleDeleteConfirmView(msg)
+	case types.ClientContactsView:
+		return c.handleContactsView(msg)
+	case types.ClientContactEditView:
+		return c.handleContactEditView(msg)
+	case types.ClientTagFilterView, types.ClientFilterView:
+		return c.handleFilterView(msg)
 	}
 	return nil, nil
 }
 
 // handleListView manages the client list view logic
 func (c *Controller) handleListView(msg tea.Msg) (*types.ViewTransition, tea.Cmd) {
-	// Handle delete key before passing to form
-	if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "d" {
-		if c.selection != "" && c.selection != "CREATE_NEW" {
-			// Show delete confirmation
-			c.deleteID = c.selection
-			c.deleteConfirmed = false
-			c.form = forms.NewDeleteConfirmForm(&c.deleteConfirmed)
-			return &types.ViewTransition{
-				NewView: types.ClientDeleteConfirmView,
-				Form:    c.form,
-			}, c.form.Init()
+	if handled, err := c.list.Update(msg); handled {
+		if err != nil {
+			return nil, status.Err("loading clients", err)
 		}
+		return nil, nil
 	}
 
-	// Update form
-	form, cmd := c.form.Update(msg)
-	if f, ok := form.(*huh.Form); ok {
-		c.form = f
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil, nil
 	}
 
-	// Check if form is completed
-	if c.form.State == huh.StateCompleted {
-		if c.selection == "CREATE_NEW" {
-			// Navigate to create client view
-			c.resetFormFields()
-			c.form = forms.NewClientForm(&c.name, &c.address, &c.email, &c.phone)
-			return &types.ViewTransition{
-				NewView: types.ClientCreateView,
-				Form:    c.form,
-			}, c.form.Init()
-		} else {
-			// Navigate to edit client view
-			c.selectedID = c.selection
-			// Get client data
-			clients, err := storage.ListClients()
-			if err != nil {
-				log.Printf("Error loading client: %v", err)
-				return nil, nil
-			}
-			var selectedClient *models.Entity
-			for _, p := range clients {
-				if p.ID == c.selectedID {
-					selectedClient = &p
-					break
-				}
-			}
-			if selectedClient == nil {
-				log.Printf("Client not found: %s", c.selectedID)
-				return nil, nil
-			}
-			c.form = forms.NewClientFormWithData(*selectedClient, &c.name, &c.address, &c.email, &c.phone)
-			return &types.ViewTransition{
-				NewView: types.ClientEditView,
-				Form:    c.form,
-			}, c.form.Init()
-		}
+	km := keys.Current()
+	clientID := c.list.SelectedID()
+	selected := clientID != ""
+	switch {
+	case key.Matches(keyMsg, km.Open) && selected:
+		return c.Edit(clientID)
+	case key.Matches(keyMsg, km.New):
+		return c.New()
+	case key.Matches(keyMsg, km.Delete) && selected:
+		// Show delete confirmation
+		c.deleteID = clientID
+		c.deleteConfirmed = false
+		c.form = forms.NewDeleteConfirmForm(&c.deleteConfirmed)
+		return types.FormTransition(types.ClientDeleteConfirmView, c.form)
+	case key.Matches(keyMsg, km.Archive) && selected:
+		return c.toggleArchived(clientID)
+	case key.Matches(keyMsg, km.ShowArchived):
+		c.showArchived = !c.showArchived
+		return c.showList()
+	case key.Matches(keyMsg, km.Filter):
+		c.form = forms.NewSearchFilterForm(&c.search)
+		return types.FormTransition(types.ClientFilterView, c.form)
+	case key.Matches(keyMsg, km.TagFilter):
+		return c.showTagFilter()
 	}
 
-	return nil, cmd
+	return nil, nil
+}
+
+// New opens the form for a new client
+func (c *Controller) New() (*types.ViewTransition, tea.Cmd) {
+	c.resetFormFields()
+	c.selectedID = ""
+	custom, err := forms.LoadCustomFieldInputs(models.EntityClient, "")
+	if err != nil {
+		return nil, status.Err("loading custom fields", err)
+	}
+	c.custom = custom
+	c.form = forms.NewClientForm(&c.name, &c.email, &c.phone, c.extraGroups()...)
+	return types.FormTransition(types.ClientCreateView, c.form)
+}
+
+// Edit opens the edit form for the client with the given ID
+func (c *Controller) Edit(clientID string) (*types.ViewTransition, tea.Cmd) {
+	c.selectedID = clientID
+	// Get client data
+	selectedClient, err := storage.GetClient(c.selectedID)
+	if err != nil {
+		return nil, status.Err("loading client", err)
+	}
+	details, err := storage.GetClientDetails(c.selectedID)
+	if err != nil {
+		return nil, status.Err("loading client details", err)
+	}
+	c.billing = details.BillingAddress
+	c.shipping = details.ShippingAddress
+	c.separateShipping = !details.ShippingAddress.IsEmpty()
+	c.contacts = details.Contacts
+	tags, err := storage.GetClientTags(c.selectedID)
+	if err != nil {
+		return nil, status.Err("loading tags", err)
+	}
+	c.tags = models.FormatTags(tags)
+	c.custom, err = forms.LoadCustomFieldInputs(models.EntityClient, c.selectedID)
+	if err != nil {
+		return nil, status.Err("loading custom fields", err)
+	}
+	c.form = forms.NewClientFormWithData(*selectedClient, &c.name, &c.email, &c.phone, c.extraGroups()...)
+	return &types.ViewTransition{
+		NewView: types.ClientEditView,
+		Form:    c.form,
+		Crumb:   selectedClient.Name,
+	}, tea.Batch(c.form.Init(), palette.Remember(models.SearchClient, c.selectedID))
 }
 
 // handleDeleteConfirmView manages the delete confirmation view
@@ -135,37 +239,28 @@ func (c *Controller) handleDeleteConfirmView(msg tea.Msg) (*types.ViewTransition
 
 	// Check if form is completed
 	if c.form.State == huh.StateCompleted {
-		var errorMsg string
+		var notice tea.Cmd
 		if c.deleteConfirmed {
 			// Delete the client
 			err := storage.DeleteClient(c.deleteID)
 			if err != nil {
-				log.Printf("Error deleting client: %v", err)
-				errorMsg = err.Error()
+				notice = status.Err("deleting client", err)
+			} else {
+				notice = status.Success("Client deleted")
 			}
 		}
 
 		// Refresh the client list
-		c.selection = ""
 		c.deleteID = ""
-		clientForm, err := views.CreateClientListFormWithError(&c.selection, errorMsg)
-		if err != nil {
-			log.Printf("Error refreshing client list: %v", err)
-			return nil, nil
-		}
-
-		c.form = clientForm
-		return &types.ViewTransition{
-			NewView: types.ClientsListView,
-			Form:    c.form,
-		}, c.form.Init()
+		return c.showList(notice)
 	}
 
 	return nil, cmd
 }
 
 // handleFormView manages create and edit form views
-func (c *Controller) handleFormView(msg tea.Msg, currentView types.View) (*types.ViewTransition, tea.Cmd) {
+// Completing the form moves on to the contacts list; the client is saved from there
+func (c *Controller) handleFormView(msg tea.Msg) (*types.ViewTransition, tea.Cmd) {
 	// Update form
 	form, cmd := c.form.Update(msg)
 	if f, ok := form.(*huh.Form); ok {
@@ -174,56 +269,225 @@ func (c *Controller) handleFormView(msg tea.Msg, currentView types.View) (*types
 
 	// Check if form is completed
 	if c.form.State == huh.StateCompleted {
-		// Handle client creation/update
-		// Convert empty strings to nil pointers, otherwise return pointer to value
-		var addressPtr, emailPtr, phonePtr *string
-		if c.address != "" {
-			addressPtr = &c.address
-		}
-		if c.email != "" {
-			emailPtr = &c.email
-		}
-		if c.phone != "" {
-			phonePtr = &c.phone
+		return c.showContacts()
+	}
+
+	return nil, cmd
+}
+
+// handleContactsView manages the contacts list shown before the client is saved
+func (c *Controller) handleContactsView(msg tea.Msg) (*types.ViewTransition, tea.Cmd) {
+	// Handle contact removal before passing to form
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && !keys.Filtering(c.form) && key.Matches(keyMsg, keys.Current().Delete) {
+		if i, err := strconv.Atoi(c.contactSelection); err == nil && i < len(c.contacts) {
+			removed := c.contacts[i].Name
+			c.contacts = append(c.contacts[:i], c.contacts[i+1:]...)
+			transition, cmd := c.showContacts()
+			return transition, tea.Batch(cmd, status.Info(fmt.Sprintf("Removed %s", removed)))
 		}
+	}
 
-		if currentView == types.ClientCreateView {
-			_, err := storage.CreateClient(c.name, addressPtr, emailPtr, phonePtr)
-			if err != nil {
-				log.Printf("Error creating client: %v", err)
-				return nil, nil
-			}
-		} else {
-			err := storage.UpdateClient(c.selectedID, c.name, addressPtr, emailPtr, phonePtr)
-			if err != nil {
-				log.Printf("Error updating client: %v", err)
-				return nil, nil
-			}
+	// Update form
+	form, cmd := c.form.Update(msg)
+	if f, ok := form.(*huh.Form); ok {
+		c.form = f
+	}
+
+	if c.form.State != huh.StateCompleted {
+		return nil, cmd
+	}
+
+	switch c.contactSelection {
+	case "SAVE":
+		return c.saveClient()
+	case "ADD_CONTACT":
+		// The first contact is the billing contact unless the user says otherwise
+		c.contactIndex = -1
+		c.contact = models.Contact{Billing: len(c.contacts) == 0}
+	default:
+		i, err := strconv.Atoi(c.contactSelection)
+		if err != nil || i >= len(c.contacts) {
+			return c.showContacts()
 		}
+		c.contactIndex = i
+		c.contact = c.contacts[i]
+	}
 
-		// Return to client list
-		c.selection = ""
-		clientForm, err := views.CreateClientListForm(&c.selection)
-		if err != nil {
-			log.Printf("Error creating client form: %v", err)
-			return nil, nil
+	c.form = forms.NewContactForm(&c.contact)
+	return types.FormTransition(types.ClientContactEditView, c.form)
+}
+
+// handleContactEditView manages the form for adding or editing a single contact
+func (c *Controller) handleContactEditView(msg tea.Msg) (*types.ViewTransition, tea.Cmd) {
+	// Update form
+	form, cmd := c.form.Update(msg)
+	if f, ok := form.(*huh.Form); ok {
+		c.form = f
+	}
+
+	if c.form.State != huh.StateCompleted {
+		return nil, cmd
+	}
+
+	// Only one contact can be the billing contact
+	if c.contact.Billing {
+		for i := range c.contacts {
+			c.contacts[i].Billing = false
 		}
-		c.form = clientForm
-		return &types.ViewTransition{
-			NewView: types.ClientsListView,
-			Form:    c.form,
-		}, c.form.Init()
+	}
+
+	if c.contactIndex < 0 {
+		c.contacts = append(c.contacts, c.contact)
+	} else {
+		c.contacts[c.contactIndex] = c.contact
+	}
+
+	return c.showContacts()
+}
+
+// showContacts shows the contacts list for the client being edited
+func (c *Controller) showContacts() (*types.ViewTransition, tea.Cmd) {
+	c.contactSelection = ""
+	c.form = views.CreateClientContactsForm(&c.contactSelection, c.name, c.contacts)
+	return types.FormTransition(types.ClientContactsView, c.form)
+}
+
+// saveClient creates or updates the client with its addresses, contacts, tags and custom fields
+func (c *Controller) saveClient() (*types.ViewTransition, tea.Cmd) {
+	// Convert empty strings to nil pointers, otherwise return pointer to value
+	var emailPtr, phonePtr *string
+	if c.email != "" {
+		emailPtr = &c.email
+	}
+	if c.phone != "" {
+		phonePtr = &c.phone
+	}
+
+	draft := models.ClientDraft{
+		ID:    c.selectedID,
+		Name:  c.name,
+		Email: emailPtr,
+		Phone: phonePtr,
+		Details: models.ClientDetails{
+			BillingAddress: c.billing,
+			Contacts:       c.contacts,
+		},
+		Tags:         models.ParseTags(c.tags),
+		CustomValues: c.custom.ValueMap(),
+	}
+	if c.separateShipping {
+		draft.Details.ShippingAddress = c.shipping
+	}
+
+	clientID, err := storage.SaveClient(draft)
+	if err != nil {
+		return nil, status.Err("saving client", err)
+	}
+
+	// Return to client list with the saved client selected
+	c.list.SelectID(clientID)
+	return c.showList(status.Success("Client saved"))
+}
+
+// extraGroups returns the address, tag and custom field pages shown after the client's contact details
+func (c *Controller) extraGroups() []*huh.Group {
+	groups := []*huh.Group{forms.NewAddressGroup("Billing Address", &c.billing)}
+	groups = append(groups, forms.NewShippingGroups(&c.separateShipping, &c.shipping)...)
+	groups = append(groups, forms.NewTagsGroup(&c.tags))
+	return append(groups, c.custom.Groups()...)
+}
+
+// listFilter returns the filter the client list is currently shown with
+func (c *Controller) listFilter() storage.EntityFilter {
+	return storage.EntityFilter{IncludeArchived: c.showArchived, Search: c.search, Tags: c.tagFilter}
+}
+
+// showTagFilter shows the form for choosing the tags the client list is filtered by
+func (c *Controller) showTagFilter() (*types.ViewTransition, tea.Cmd) {
+	tags, err := storage.ListTags()
+	if err != nil {
+		return nil, status.Err("loading tags", err)
+	}
+	if len(tags) == 0 && len(c.tagFilter) == 0 {
+		return nil, status.Info("No tags yet; add some when editing a client")
+	}
+
+	c.form = forms.NewTagFilterForm(&c.tagFilter, tags)
+	return types.FormTransition(types.ClientTagFilterView, c.form)
+}
+
+// handleFilterView applies the search or tags chosen in a filter form to the client list
+func (c *Controller) handleFilterView(msg tea.Msg) (*types.ViewTransition, tea.Cmd) {
+	form, cmd := c.form.Update(msg)
+	if f, ok := form.(*huh.Form); ok {
+		c.form = f
+	}
+
+	if c.form.State == huh.StateCompleted {
+		return c.showList()
 	}
 
 	return nil, cmd
 }
 
+// toggleArchived archives the client with the given ID, or restores it if it is already archived
+func (c *Controller) toggleArchived(clientID string) (*types.ViewTransition, tea.Cmd) {
+	client, err := storage.GetClient(clientID)
+	if err != nil {
+		return nil, status.Err("loading client", err)
+	}
+
+	var notice tea.Cmd
+	if client.Archived {
+		if err := storage.RestoreClient(clientID); err != nil {
+			return nil, status.Err("restoring client", err)
+		}
+		notice = status.Success(fmt.Sprintf("Restored %s", client.Name))
+	} else {
+		if err := storage.ArchiveClient(clientID); err != nil {
+			return nil, status.Err("archiving client", err)
+		}
+		notice = status.Success(fmt.Sprintf("Archived %s", client.Name))
+	}
+
+	return c.showList(notice)
+}
+
+// Resume shows the client list or the contacts of the client being edited again when the
+// user goes back to them, keeping the selection. Forms can't be resumed; it returns nil for them.
+func (c *Controller) Resume(view types.View) (*types.ViewTransition, tea.Cmd) {
+	switch view {
+	case types.ClientsListView:
+		return c.showList()
+	case types.ClientContactsView:
+		return c.showContacts()
+	}
+	return nil, nil
+}
+
+// showList reloads the client list and transitions to it, running any extra commands.
+// The list is a table rather than a form, and keeps the selected client if it is still listed.
+func (c *Controller) showList(cmds ...tea.Cmd) (*types.ViewTransition, tea.Cmd) {
+	if err := views.LoadEntityRows(&c.list, "client", c.listFilter()); err != nil {
+		return nil, status.Err("refreshing client list", err)
+	}
+	c.form = nil
+	return &types.ViewTransition{
+		NewView: types.ClientsListView,
+		Form:    nil,
+	}, tea.Batch(cmds...)
+}
+
 // resetFormFields clears all form field values
 func (c *Controller) resetFormFields() {
 	c.name = ""
-	c.address = ""
 	c.email = ""
 	c.phone = ""
+	c.billing = models.Address{}
+	c.shipping = models.Address{}
+	c.separateShipping = false
+	c.contacts = nil
+	c.tags = ""
 }
 
 // GetForm returns the current form
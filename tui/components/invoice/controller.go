@@ -2,42 +2,54 @@
 package invoice
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"slices"
 	"strconv"
+	"time"
 
 	"github.com/GVPproj/termsheet/models"
 	"github.com/GVPproj/termsheet/storage"
+	"github.com/GVPproj/termsheet/tui/components/palette"
+	"github.com/GVPproj/termsheet/tui/components/status"
+	"github.com/GVPproj/termsheet/tui/components/table"
 	"github.com/GVPproj/termsheet/tui/forms"
+	"github.com/GVPproj/termsheet/tui/keys"
+	"github.com/GVPproj/termsheet/tui/router"
 	"github.com/GVPproj/termsheet/tui/views"
 	"github.com/GVPproj/termsheet/types"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
 )
 
-// InvoiceFormStep represents the current step in the invoice form flow
+// InvoiceFormStep represents the current step of the invoice form, which selects the
+// provider and client before moving on to the item editor
 type InvoiceFormStep int
 
 const (
 	StepSelectProvider InvoiceFormStep = iota
 	StepSelectClient
-	StepAddItem
-	StepAskForMore
-	StepMarkPaid
 )
 
-// InvoiceItem represents a single item being added to an invoice
-type InvoiceItem struct {
-	Name        string
-	Amount      float64
-	CostPerUnit float64
-}
+// undoWindow is how long a deleted invoice can be restored with the undo key
+const undoWindow = 5 * time.Second
 
 // Controller manages invoice-related state and behavior
 type Controller struct {
 	// Form state
-	form      *huh.Form
-	selection string
+	form *huh.Form
+
+	// Invoice list; its rows are loaded in the order given by sort
+	list       table.Model
+	sort       storage.InvoiceSort
+	trashCount int
+	// Size of the terminal, as last given to Resize
+	windowWidth  int
+	windowHeight int
+	// preview is the invoice selected in the list, shown beside it unless hidePreview is set
+	preview     *models.InvoiceData
+	hidePreview bool
 
 	// Invoice form fields
 	providerID      string
@@ -46,38 +58,170 @@ type Controller struct {
 	itemAmount      string
 	itemCostPerUnit string
 	paid            bool
-	addAnother      bool
+
+	// Tags, notes, terms, due date and custom field values, entered on the mark paid step
+	tags    string
+	notes   string
+	terms   string
+	dueDate string
+	custom  *forms.CustomFieldInputs
+
+	// Filters applied to the list; filterInputs is bound to the filter form while it is open
+	filter       storage.InvoiceFilter
+	filterInputs *forms.InvoiceFilterInputs
 
 	// Multi-step flow
 	currentStep InvoiceFormStep
-	items       []InvoiceItem
+
+	// Item editor; items are saved with the invoice, keeping the IDs of existing ones.
+	// itemIndex is the item open in the item form, or -1 for a new one.
+	items     []models.InvoiceItem
+	itemTable table.Model
+	itemIndex int
+
+	// Whether archived providers and clients are offered in the select steps
+	showArchived bool
 
 	// Edit state
-	selectedID    string
-	invoiceID     int
-	existingItems []models.InvoiceItem
-	isEditMode    bool
-	currentItemIndex int
+	selectedID string
+	invoiceID  int
+	isEditMode bool
+	// duplicateOf is the invoice a new one is a copy of; its notes and terms fill in the details
+	duplicateOf *models.InvoiceData
 
 	// Action menu state
 	actionSelection string
 	invoiceData     *models.InvoiceData
+
+	// Delete confirmation; in purge mode the invoice is removed from the trash
+	// permanently, and a purge of invoice 0 empties the whole trash
+	deleteConfirmed bool
+	deleteID        int
+	purgeMode       bool
+
+	// Trash state
+	trashSelection string
+	trashAction    string
+
+	// Most recently trashed invoice, restorable until undoUntil
+	undoID    int
+	undoUntil time.Time
 }
 
 // NewController creates a new invoice controller
 func NewController() *Controller {
-	return &Controller{}
+	return &Controller{
+		list:      views.NewInvoiceTable(),
+		sort:      storage.DefaultInvoiceSort,
+		itemTable: views.NewInvoiceItemTable(),
+	}
+}
+
+// InitListView shows the invoice list with no filters, newest first
+func (c *Controller) InitListView() (*types.ViewTransition, tea.Cmd) {
+	c.filter = storage.InvoiceFilter{}
+	c.sort = storage.DefaultInvoiceSort
+	c.list = views.NewInvoiceTable()
+	c.resizeList()
+	return c.showInvoiceList()
+}
+
+// Resize fits the invoice list to a terminal of the given size; zeros leave the list at its
+// default size. It returns a command reporting any failure to load the preview a wider
+// terminal makes room for.
+func (c *Controller) Resize(width, height int) tea.Cmd {
+	c.windowWidth, c.windowHeight = width, height
+	c.resizeList()
+	return c.loadPreview()
+}
+
+// resizeList fits the list to the terminal size last given to Resize, less the preview beside it
+func (c *Controller) resizeList() {
+	width, height := views.ListTableSize(c.windowWidth, c.windowHeight)
+	if c.showPreview() {
+		width -= views.InvoicePreviewWidth(c.windowWidth)
+	}
+	c.list.SetWidth(width)
+	if height > 0 {
+		c.list.SetHeight(height)
+	}
+	views.FitInvoiceItemTable(&c.itemTable, c.windowWidth, c.windowHeight)
+}
+
+// showPreview reports whether the selected invoice is previewed beside the list:
+// unless the user hid the preview, whenever the terminal is wide enough
+func (c *Controller) showPreview() bool {
+	return !c.hidePreview && views.InvoicePreviewWidth(c.windowWidth) > 0
+}
+
+// togglePreview shows or hides the preview beside the list
+func (c *Controller) togglePreview() (*types.ViewTransition, tea.Cmd) {
+	c.hidePreview = !c.hidePreview
+	c.resizeList()
+	return nil, c.loadPreview()
 }
 
-// InitListView initializes the invoice list view
-func (c *Controller) InitListView() (*huh.Form, error) {
-	c.selection = ""
-	invoiceForm, err := views.CreateInvoiceListForm(&c.selection)
+// loadPreview loads the invoice selected in the list for the preview, if it is shown,
+// returning a command reporting any failure to do so
+func (c *Controller) loadPreview() tea.Cmd {
+	invoiceID, ok := parseInvoiceID(c.list.SelectedID())
+	if !ok || !c.showPreview() {
+		c.preview = nil
+		return nil
+	}
+	if c.preview != nil && c.preview.InvoiceID == invoiceID {
+		return nil
+	}
+
+	data, err := storage.GetInvoiceData(invoiceID)
 	if err != nil {
-		return nil, err
+		c.preview = nil
+		return status.Err("loading invoice preview", err)
 	}
-	c.form = invoiceForm
-	return c.form, nil
+	c.preview = data
+	return nil
+}
+
+// MenuEntry lists the invoices in the main menu
+func (c *Controller) MenuEntry() router.MenuEntry {
+	return router.MenuEntry{Key: "Invoices", Label: "Invoices - Create, Edit, Track", Open: c.InitListView}
+}
+
+// Routes lists the invoice views and how they are drawn
+func (c *Controller) Routes() []router.Route {
+	return []router.Route{
+		{View: types.InvoicesListView, Title: "Invoices", Render: c.renderList, Shortcuts: true, Keys: views.InvoiceListKeys()},
+		{View: types.InvoiceActionMenuView, Title: "Invoice", Render: views.RenderInvoiceActionMenu},
+		{View: types.InvoiceViewView, Title: "View", Render: c.renderInvoice, Shortcuts: true},
+		{View: types.InvoiceCreateView, Title: "New invoice", Render: views.RenderInvoices, Prompt: true},
+		{View: types.InvoiceEditView, Title: "Edit", Render: views.RenderInvoices, Prompt: true},
+		{View: types.InvoiceItemsView, Title: "Items", Render: c.renderItems, Shortcuts: true, Keys: views.InvoiceItemsKeys()},
+		{View: types.InvoiceItemEditView, Title: "Item", Render: views.RenderInvoices, Prompt: true},
+		{View: types.InvoiceDetailsView, Title: "Details", Render: views.RenderInvoices, Prompt: true},
+		{View: types.InvoiceDeleteConfirmView, Title: "Delete", Render: views.RenderDeleteConfirm, Prompt: true},
+		{View: types.InvoiceTrashView, Title: "Trash", Render: views.RenderInvoiceTrash, Shortcuts: true},
+		{View: types.InvoiceTrashActionView, Title: "Trashed invoice", Render: views.RenderInvoiceTrash},
+		{View: types.InvoiceTagFilterView, Title: "Tags", Render: views.RenderInvoices, Prompt: true},
+		{View: types.InvoiceFilterView, Title: "Filter", Render: views.RenderInvoices, Prompt: true},
+	}
+}
+
+// renderList draws the invoice list, which is a table rather than a form
+func (c *Controller) renderList(_ *huh.Form, width int) string {
+	return views.RenderInvoiceList(c.GetInvoiceList(), width)
+}
+
+// renderItems draws the item editor, which is a table rather than a form
+func (c *Controller) renderItems(_ *huh.Form, width int) string {
+	return views.RenderInvoiceItems(views.InvoiceItems{Table: c.itemTable, Items: c.items}, width)
+}
+
+// renderInvoice draws the invoice being displayed
+func (c *Controller) renderInvoice(_ *huh.Form, width int) string {
+	if c.invoiceData == nil {
+		return "Error: No invoice data available\n\nPress ESC to return"
+	}
+	return views.RenderInvoiceView(c.invoiceData, width)
 }
 
 // Update handles invoice-related messages and returns view transition if needed
@@ -90,99 +234,341 @@ func (c *Controller) Update(msg tea.Msg, currentView types.View) (*types.ViewTra
 	case types.InvoiceViewView:
 		return c.handleInvoiceDisplayView(msg)
 	case types.InvoiceCreateView, types.InvoiceEditView:
-		return c.handleFormView(msg, currentView)
+		return c.handleFormView(msg)
+	case types.InvoiceItemsView:
+		return c.handleItemsView(msg)
+	case types.InvoiceItemEditView:
+		return c.handleItemEditView(msg)
+	case types.InvoiceDetailsView:
+		return c.handleDetailsView(msg)
+	case types.InvoiceDeleteConfirmView:
+		return c.handleDeleteConfirmView(msg)
+	case types.InvoiceTrashView:
+		return c.handleTrashView(msg)
+	case types.InvoiceTrashActionView:
+		return c.handleTrashActionView(msg)
+	case types.InvoiceTagFilterView:
+		return c.handleTagFilterView(msg)
+	case types.InvoiceFilterView:
+		return c.handleFilterView(msg)
 	}
 	return nil, nil
 }
 
 // handleListView manages the invoice list view logic
 func (c *Controller) handleListView(msg tea.Msg) (*types.ViewTransition, tea.Cmd) {
-	// Handle delete key before passing to form
-	if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "d" {
-		if c.selection != "" && c.selection != "CREATE_NEW" {
-			// Parse invoice ID
-			var invoiceID int
-			_, err := fmt.Sscanf(c.selection, "%d", &invoiceID)
-			if err != nil {
-				log.Printf("Error parsing invoice ID: %v", err)
-				return nil, nil
-			}
+	if handled, err := c.list.Update(msg); handled {
+		if err != nil {
+			return nil, status.Err("loading invoices", err)
+		}
+		return nil, c.loadPreview()
+	}
 
-			// Delete the invoice
-			err = storage.DeleteInvoice(invoiceID)
-			if err != nil {
-				log.Printf("Error deleting invoice: %v", err)
-				return nil, nil
-			}
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil, nil
+	}
 
-			// Refresh the invoice list
-			c.selection = ""
-			invoiceForm, err := views.CreateInvoiceListForm(&c.selection)
-			if err != nil {
-				log.Printf("Error refreshing invoice list: %v", err)
-				return nil, nil
-			}
-			c.form = invoiceForm
-			return nil, c.form.Init()
+	// Number keys sort by the matching column
+	if pressed := keyMsg.String(); len(pressed) == 1 && pressed[0] >= '1' && int(pressed[0]-'1') < len(views.InvoiceSortColumns) {
+		return c.sortBy(views.InvoiceSortColumns[pressed[0]-'1'])
+	}
+
+	km := keys.Current()
+	invoiceID, selected := parseInvoiceID(c.list.SelectedID())
+	switch {
+	case key.Matches(keyMsg, km.Open) && selected:
+		return c.showActionMenu(invoiceID)
+	case key.Matches(keyMsg, km.View) && selected:
+		return c.Open(invoiceID, false)
+	case key.Matches(keyMsg, km.Edit) && selected:
+		return c.Open(invoiceID, true)
+	case key.Matches(keyMsg, km.Issue) && selected:
+		return c.issue(invoiceID)
+	case key.Matches(keyMsg, km.MarkPaid) && selected:
+		return c.togglePaid(invoiceID)
+	case key.Matches(keyMsg, km.Preview):
+		return c.togglePreview()
+	case key.Matches(keyMsg, km.New):
+		return c.New()
+	case key.Matches(keyMsg, km.Trash):
+		if c.trashCount > 0 {
+			return c.showTrash()
+		}
+		return nil, status.Info("The trash is empty")
+	case key.Matches(keyMsg, km.Delete) && selected:
+		// Show delete confirmation
+		c.deleteID = invoiceID
+		c.purgeMode = false
+		c.deleteConfirmed = false
+		c.form = forms.NewDeleteConfirmForm(&c.deleteConfirmed)
+		return types.FormTransition(types.InvoiceDeleteConfirmView, c.form)
+	case key.Matches(keyMsg, km.Undo):
+		if c.undoID != 0 && time.Now().Before(c.undoUntil) {
+			return c.undoDelete()
 		}
+	case key.Matches(keyMsg, km.Filter):
+		return c.showFilter()
+	case key.Matches(keyMsg, km.TagFilter):
+		return c.showTagFilter()
+	}
+
+	return nil, nil
+}
+
+// New opens the form for a new invoice, starting with the provider selection
+func (c *Controller) New() (*types.ViewTransition, tea.Cmd) {
+	c.resetFormFields()
+	c.currentStep = StepSelectProvider
+	c.isEditMode = false
+	invoiceForm, err := forms.NewProviderSelectForm(&c.providerID, c.showArchived)
+	if err != nil {
+		return nil, status.Err("creating invoice form", err)
 	}
+	c.form = invoiceForm
+	return types.FormTransition(types.InvoiceCreateView, c.form)
+}
 
+// sortBy sorts the invoice list by column, reversing the order if it is already sorted by it
+func (c *Controller) sortBy(column storage.InvoiceSortColumn) (*types.ViewTransition, tea.Cmd) {
+	if c.sort.Column == column {
+		c.sort.Desc = !c.sort.Desc
+	} else {
+		c.sort = storage.InvoiceSort{Column: column}
+	}
+	return c.showInvoiceList()
+}
+
+// showActionMenu loads an invoice and shows the actions available for it
+func (c *Controller) showActionMenu(invoiceID int) (*types.ViewTransition, tea.Cmd) {
+	c.actionSelection = ""
+	return c.actionMenu(invoiceID)
+}
+
+// actionMenu loads an invoice and shows its action menu, keeping the selected action
+func (c *Controller) actionMenu(invoiceID int) (*types.ViewTransition, tea.Cmd) {
+	invoiceData, err := storage.GetInvoiceData(invoiceID)
+	if err != nil {
+		return nil, status.Err("loading invoice", err)
+	}
+	c.selectedID = strconv.Itoa(invoiceID)
+	c.invoiceID = invoiceID
+	c.invoiceData = invoiceData
+
+	c.form = views.CreateInvoiceActionFormWithData(&c.actionSelection, c.invoiceData)
+	return &types.ViewTransition{
+		NewView: types.InvoiceActionMenuView,
+		Form:    c.form,
+		Crumb:   invoiceData.DisplayNumber(),
+	}, tea.Batch(c.form.Init(), palette.Remember(models.SearchInvoice, c.selectedID))
+}
+
+// Resume shows the invoice list, an invoice's action menu or display, the trash or the
+// item editor again when the user goes back to it, reloading what it shows and keeping
+// the selection. Forms can't be resumed; it returns nil for them.
+func (c *Controller) Resume(view types.View) (*types.ViewTransition, tea.Cmd) {
+	switch view {
+	case types.InvoicesListView:
+		return c.showInvoiceList()
+	case types.InvoiceActionMenuView:
+		return c.actionMenu(c.invoiceID)
+	case types.InvoiceViewView:
+		return c.Open(c.invoiceID, false)
+	case types.InvoiceTrashView:
+		return c.trashView()
+	case types.InvoiceItemsView:
+		return c.showItems()
+	}
+	return nil, nil
+}
+
+// handleDeleteConfirmView manages the delete confirmation for both trashing and purging
+func (c *Controller) handleDeleteConfirmView(msg tea.Msg) (*types.ViewTransition, tea.Cmd) {
 	// Update form
 	form, cmd := c.form.Update(msg)
 	if f, ok := form.(*huh.Form); ok {
 		c.form = f
 	}
 
-	// Check if form is completed
-	if c.form.State == huh.StateCompleted {
-		if c.selection == "CREATE_NEW" {
-			// Navigate to create invoice view - start with provider selection
-			c.resetFormFields()
-			c.currentStep = StepSelectProvider
-			c.isEditMode = false
-			invoiceForm, err := forms.NewProviderSelectForm(&c.providerID)
-			if err != nil {
-				log.Printf("Error creating invoice form: %v", err)
-				return nil, nil
-			}
-			c.form = invoiceForm
-			return &types.ViewTransition{
-				NewView: types.InvoiceCreateView,
-				Form:    c.form,
-			}, c.form.Init()
-		} else {
-			// Navigate to action menu
-			c.selectedID = c.selection
+	if c.form.State != huh.StateCompleted {
+		return nil, cmd
+	}
 
-			// Parse invoice ID
-			var invoiceID int
-			_, err := fmt.Sscanf(c.selectedID, "%d", &invoiceID)
-			if err != nil {
-				log.Printf("Error parsing invoice ID: %v", err)
-				return nil, nil
-			}
-			c.invoiceID = invoiceID
+	invoiceID := c.deleteID
+	c.deleteID = 0
 
-			// Get invoice data for later use
-			invoiceData, err := storage.GetInvoiceData(invoiceID)
+	if c.purgeMode {
+		if !c.deleteConfirmed {
+			return c.showTrash()
+		}
+
+		if invoiceID == 0 {
+			count, err := storage.EmptyTrash()
 			if err != nil {
-				log.Printf("Error loading invoice: %v", err)
-				return nil, nil
+				return nil, status.Err("emptying trash", err)
 			}
-			c.invoiceData = invoiceData
+			return c.showTrash(status.Success(fmt.Sprintf("Permanently deleted %d invoice(s)", count)))
+		}
 
-			// Create action menu form
-			c.actionSelection = ""
-			actionForm := views.CreateInvoiceActionForm(&c.actionSelection)
-			c.form = actionForm
-			return &types.ViewTransition{
-				NewView: types.InvoiceActionMenuView,
-				Form:    c.form,
-			}, c.form.Init()
+		label := invoiceLabel(invoiceID)
+		if err := storage.PurgeInvoice(invoiceID); err != nil {
+			return nil, status.Err("deleting invoice", err)
 		}
+		return c.showTrash(status.Success(fmt.Sprintf("Invoice %s permanently deleted", label)))
 	}
 
-	return nil, cmd
+	if !c.deleteConfirmed {
+		return c.showInvoiceList()
+	}
+
+	if err := storage.TrashInvoice(invoiceID); err != nil {
+		return nil, status.Err("deleting invoice", err)
+	}
+
+	c.undoID = invoiceID
+	c.undoUntil = time.Now().Add(undoWindow)
+	return c.showInvoiceList(status.InfoFor(
+		fmt.Sprintf("Invoice %s moved to trash. Press 'u' to undo", invoiceLabel(invoiceID)),
+		undoWindow,
+	))
+}
+
+// undoDelete restores the most recently trashed invoice
+func (c *Controller) undoDelete() (*types.ViewTransition, tea.Cmd) {
+	invoiceID := c.undoID
+	c.undoID = 0
+
+	if err := storage.RestoreInvoice(invoiceID); err != nil {
+		return nil, status.Err("restoring invoice", err)
+	}
+
+	return c.showInvoiceList(status.Success(fmt.Sprintf("Invoice %s restored", invoiceLabel(invoiceID))))
+}
+
+// handleTrashView manages the list of trashed invoices
+func (c *Controller) handleTrashView(msg tea.Msg) (*types.ViewTransition, tea.Cmd) {
+	// Update form
+	form, cmd := c.form.Update(msg)
+	if f, ok := form.(*huh.Form); ok {
+		c.form = f
+	}
+
+	if c.form.State != huh.StateCompleted {
+		return nil, cmd
+	}
+
+	switch c.trashSelection {
+	case "BACK":
+		return c.showInvoiceList()
+	case "EMPTY_TRASH":
+		return c.confirmPurge(0)
+	}
+
+	invoiceID, ok := parseInvoiceID(c.trashSelection)
+	if !ok {
+		return c.showTrash()
+	}
+	c.deleteID = invoiceID
+
+	c.trashAction = ""
+	c.form = views.CreateTrashActionForm(&c.trashAction)
+	return types.FormTransition(types.InvoiceTrashActionView, c.form)
+}
+
+// handleTrashActionView manages the restore/purge choice for a trashed invoice
+func (c *Controller) handleTrashActionView(msg tea.Msg) (*types.ViewTransition, tea.Cmd) {
+	// Update form
+	form, cmd := c.form.Update(msg)
+	if f, ok := form.(*huh.Form); ok {
+		c.form = f
+	}
+
+	if c.form.State != huh.StateCompleted {
+		return nil, cmd
+	}
+
+	switch views.TrashActionOption(c.trashAction) {
+	case views.TrashActionRestore:
+		invoiceID := c.deleteID
+		c.deleteID = 0
+		if err := storage.RestoreInvoice(invoiceID); err != nil {
+			return nil, status.Err("restoring invoice", err)
+		}
+		return c.showTrash(status.Success(fmt.Sprintf("Invoice %s restored", invoiceLabel(invoiceID))))
+	case views.TrashActionPurge:
+		return c.confirmPurge(c.deleteID)
+	}
+
+	c.deleteID = 0
+	return c.showTrash()
+}
+
+// confirmPurge asks for confirmation before permanently deleting a trashed invoice,
+// or the whole trash when invoiceID is 0
+func (c *Controller) confirmPurge(invoiceID int) (*types.ViewTransition, tea.Cmd) {
+	c.deleteID = invoiceID
+	c.purgeMode = true
+	c.deleteConfirmed = false
+	c.form = forms.NewDeleteConfirmForm(&c.deleteConfirmed)
+	return types.FormTransition(types.InvoiceDeleteConfirmView, c.form)
+}
+
+// showInvoiceList reloads the invoice list and transitions to it, running any extra commands.
+// The list is a table rather than a form, and keeps the selected invoice if it is still listed.
+func (c *Controller) showInvoiceList(cmds ...tea.Cmd) (*types.ViewTransition, tea.Cmd) {
+	if err := views.LoadInvoiceRows(&c.list, c.listFilter(), c.sort); err != nil {
+		return nil, status.Err("refreshing invoice list", err)
+	}
+	trashed, err := storage.ListTrashedInvoices()
+	if err != nil {
+		return nil, status.Err("loading trash", err)
+	}
+	c.trashCount = len(trashed)
+
+	// The list may have changed, so the preview is reloaded
+	c.preview = nil
+	cmds = append(cmds, c.loadPreview())
+
+	c.form = nil
+	return &types.ViewTransition{
+		NewView: types.InvoicesListView,
+		Form:    nil,
+	}, tea.Batch(cmds...)
+}
+
+// showTrash rebuilds the trash list and transitions to it, running any extra commands
+func (c *Controller) showTrash(cmds ...tea.Cmd) (*types.ViewTransition, tea.Cmd) {
+	c.trashSelection = ""
+	return c.trashView(cmds...)
+}
+
+// trashView rebuilds the trash list keeping the current selection, running any extra commands
+func (c *Controller) trashView(cmds ...tea.Cmd) (*types.ViewTransition, tea.Cmd) {
+	trashForm, err := views.CreateInvoiceTrashForm(&c.trashSelection)
+	if err != nil {
+		return nil, status.Err("loading trash", err)
+	}
+	c.form = trashForm
+	return types.FormTransition(types.InvoiceTrashView, c.form, cmds...)
+}
+
+// invoiceLabel returns the number users know an invoice by, for notifications
+func invoiceLabel(invoiceID int) string {
+	data, err := storage.GetInvoiceData(invoiceID)
+	if err != nil {
+		return fmt.Sprintf("#%d", invoiceID)
+	}
+	return data.DisplayNumber()
+}
+
+// parseInvoiceID parses a list selection into an invoice ID,
+// reporting false for the non-invoice options such as CREATE_NEW
+func parseInvoiceID(selection string) (int, bool) {
+	invoiceID, err := strconv.Atoi(selection)
+	if err != nil || invoiceID <= 0 {
+		return 0, false
+	}
+	return invoiceID, true
 }
 
 // handleActionMenuView manages the invoice action menu
@@ -205,102 +591,142 @@ func (c *Controller) handleActionMenuView(msg tea.Msg) (*types.ViewTransition, t
 
 		case views.ActionEdit:
 			// Navigate to edit invoice view
-			// Get provider and client IDs by name
-			providers, err := storage.ListProviders()
-			if err != nil {
-				log.Printf("Error loading providers: %v", err)
-				return nil, nil
-			}
-			var providerID string
-			for _, p := range providers {
-				if p.Name == c.invoiceData.Provider.Name {
-					providerID = p.ID
-					break
-				}
-			}
+			return c.startEdit()
 
-			clients, err := storage.ListClients()
-			if err != nil {
-				log.Printf("Error loading clients: %v", err)
-				return nil, nil
-			}
-			var clientID string
-			for _, cl := range clients {
-				if cl.Name == c.invoiceData.Client.Name {
-					clientID = cl.ID
-					break
-				}
-			}
+		case views.ActionDuplicate:
+			return c.duplicate()
 
-			c.existingItems = c.invoiceData.Items
-			c.isEditMode = true
-			c.currentStep = StepSelectProvider
-			c.currentItemIndex = 0
-			c.providerID = providerID
-			c.clientID = clientID
-
-			// Pre-load existing items into items slice
-			c.items = make([]InvoiceItem, 0, len(c.invoiceData.Items))
-			for _, item := range c.invoiceData.Items {
-				c.items = append(c.items, InvoiceItem{
-					Name:        item.ItemName,
-					Amount:      item.Amount,
-					CostPerUnit: item.CostPerUnit,
-				})
-			}
-
-			invoiceForm, err := forms.NewProviderSelectFormWithData(&c.providerID, providerID)
-			if err != nil {
-				log.Printf("Error creating invoice form: %v", err)
-				return nil, nil
-			}
-			c.form = invoiceForm
-			return &types.ViewTransition{
-				NewView: types.InvoiceEditView,
-				Form:    c.form,
-			}, c.form.Init()
-
-		case views.ActionPDF:
-			// PDF output not implemented yet - return to invoice list
-			c.selection = ""
-			invoiceForm, err := views.CreateInvoiceListForm(&c.selection)
-			if err != nil {
-				log.Printf("Error creating invoice form: %v", err)
-				return nil, nil
-			}
-			c.form = invoiceForm
-			return &types.ViewTransition{
-				NewView: types.InvoicesListView,
-				Form:    c.form,
-			}, c.form.Init()
+		case views.ActionIssue:
+			return c.issue(c.invoiceID)
 		}
 	}
 
 	return nil, cmd
 }
 
+// startEdit opens the edit form for the invoice in invoiceData, starting with the provider step
+func (c *Controller) startEdit() (*types.ViewTransition, tea.Cmd) {
+	providerID := c.invoiceData.Provider.ID
+	clientID := c.invoiceData.Client.ID
+
+	c.isEditMode = true
+	c.duplicateOf = nil
+	c.currentStep = StepSelectProvider
+	c.providerID = providerID
+	c.clientID = clientID
+
+	// The item editor starts from the saved items, at the top
+	c.items = slices.Clone(c.invoiceData.Items)
+	c.itemTable = views.NewInvoiceItemTable()
+	views.FitInvoiceItemTable(&c.itemTable, c.windowWidth, c.windowHeight)
+
+	invoiceForm, err := forms.NewProviderSelectFormWithData(&c.providerID, providerID, c.showArchived)
+	if err != nil {
+		return nil, status.Err("creating invoice form", err)
+	}
+	c.form = invoiceForm
+	return types.FormTransition(types.InvoiceEditView, c.form)
+}
+
+// duplicate starts a new invoice copying the provider, client, items, notes and terms of
+// the invoice in invoiceData, opening it in the item editor. Like any new invoice it is
+// a draft dated today, numbered when issued, and nothing is saved until its details are.
+func (c *Controller) duplicate() (*types.ViewTransition, tea.Cmd) {
+	c.resetFormFields()
+	c.providerID = c.invoiceData.Provider.ID
+	c.clientID = c.invoiceData.Client.ID
+	c.duplicateOf = c.invoiceData
+
+	// The copies are new items, without the IDs of the originals
+	for _, item := range c.invoiceData.Items {
+		c.items = append(c.items, models.InvoiceItem{ItemName: item.ItemName, Amount: item.Amount, CostPerUnit: item.CostPerUnit})
+	}
+
+	transition, cmd := c.showItems()
+	transition.Crumb = "Copy of " + c.invoiceData.DisplayNumber()
+	return transition, tea.Batch(cmd, status.Info(fmt.Sprintf("Duplicating invoice %s; press %s to continue", c.invoiceData.DisplayNumber(), keys.Current().Continue.Help().Key)))
+}
+
+// Open shows the invoice with the given ID, or its edit form if edit is set
+func (c *Controller) Open(invoiceID int, edit bool) (*types.ViewTransition, tea.Cmd) {
+	invoiceData, err := storage.GetInvoiceData(invoiceID)
+	if err != nil {
+		return nil, status.Err("loading invoice", err)
+	}
+	c.selectedID = strconv.Itoa(invoiceID)
+	c.invoiceID = invoiceID
+	c.invoiceData = invoiceData
+
+	remember := palette.Remember(models.SearchInvoice, c.selectedID)
+	if edit {
+		transition, cmd := c.startEdit()
+		return transition, tea.Batch(cmd, remember)
+	}
+	return &types.ViewTransition{
+		NewView: types.InvoiceViewView,
+		Form:    nil,
+		Crumb:   invoiceData.DisplayNumber(),
+	}, remember
+}
+
+// MarkPaid marks the invoice with the given ID as paid and shows it
+func (c *Controller) MarkPaid(invoiceID int) (*types.ViewTransition, tea.Cmd) {
+	if err := storage.SetInvoicePaid(invoiceID, true); err != nil {
+		return nil, status.Err("marking invoice paid", err)
+	}
+	transition, cmd := c.Open(invoiceID, false)
+	if transition == nil {
+		return nil, cmd
+	}
+	return transition, tea.Batch(cmd, status.Success(fmt.Sprintf("Invoice %s marked paid", c.invoiceData.DisplayNumber())))
+}
+
+// issue assigns the draft invoice with the given ID its number and returns to the list
+func (c *Controller) issue(invoiceID int) (*types.ViewTransition, tea.Cmd) {
+	number, err := storage.IssueInvoice(invoiceID)
+	if errors.Is(err, storage.ErrAlreadyIssued) {
+		return nil, status.Info(fmt.Sprintf("Invoice %s is already issued", invoiceLabel(invoiceID)))
+	}
+	if err != nil {
+		return nil, status.Err("issuing invoice", err)
+	}
+	return c.showInvoiceList(status.Success(fmt.Sprintf("Issued invoice %s", number)))
+}
+
+// togglePaid marks the invoice with the given ID paid, or unpaid if it is paid, staying on the list
+func (c *Controller) togglePaid(invoiceID int) (*types.ViewTransition, tea.Cmd) {
+	data, err := storage.GetInvoiceData(invoiceID)
+	if err != nil {
+		return nil, status.Err("loading invoice", err)
+	}
+	if err := storage.SetInvoicePaid(invoiceID, !data.Paid); err != nil {
+		return nil, status.Err("marking invoice paid", err)
+	}
+	if data.Paid {
+		return c.showInvoiceList(status.Success(fmt.Sprintf("Invoice %s marked unpaid", data.DisplayNumber())))
+	}
+	return c.showInvoiceList(status.Success(fmt.Sprintf("Invoice %s marked paid", data.DisplayNumber())))
+}
+
 // handleInvoiceDisplayView manages the read-only invoice display
 func (c *Controller) handleInvoiceDisplayView(msg tea.Msg) (*types.ViewTransition, tea.Cmd) {
 	// Check for ESC key to return to invoice list
-	if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.Type == tea.KeyEsc {
-		c.selection = ""
-		invoiceForm, err := views.CreateInvoiceListForm(&c.selection)
-		if err != nil {
-			log.Printf("Error creating invoice form: %v", err)
-			return nil, nil
-		}
-		c.form = invoiceForm
-		return &types.ViewTransition{
-			NewView: types.InvoicesListView,
-			Form:    c.form,
-		}, c.form.Init()
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && key.Matches(keyMsg, keys.Current().Back) {
+		return c.showInvoiceList()
 	}
 
 	return nil, nil
 }
 
-// handleFormView manages create and edit form views
-func (c *Controller) handleFormView(msg tea.Msg, currentView types.View) (*types.ViewTransition, tea.Cmd) {
+// handleFormView manages the provider and client steps of the create and edit views
+func (c *Controller) handleFormView(msg tea.Msg) (*types.ViewTransition, tea.Cmd) {
+	// Toggle archived providers/clients in the select steps
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && !keys.Filtering(c.form) && key.Matches(keyMsg, keys.Current().ShowArchived) {
+		if c.currentStep == StepSelectProvider || c.currentStep == StepSelectClient {
+			return c.toggleShowArchived()
+		}
+	}
+
 	// Update form
 	form, cmd := c.form.Update(msg)
 	if f, ok := form.(*huh.Form); ok {
@@ -309,14 +735,34 @@ func (c *Controller) handleFormView(msg tea.Msg, currentView types.View) (*types
 
 	// Check if form is completed
 	if c.form.State == huh.StateCompleted {
-		return c.handleStepComplete(currentView)
+		return c.handleStepComplete()
 	}
 
 	return nil, cmd
 }
 
-// handleStepComplete handles the completion of each form step
-func (c *Controller) handleStepComplete(currentView types.View) (*types.ViewTransition, tea.Cmd) {
+// toggleShowArchived rebuilds the current provider or client select form
+// with archived entries shown or hidden
+func (c *Controller) toggleShowArchived() (*types.ViewTransition, tea.Cmd) {
+	c.showArchived = !c.showArchived
+
+	var nextForm *huh.Form
+	var err error
+	if c.currentStep == StepSelectProvider {
+		nextForm, err = forms.NewProviderSelectForm(&c.providerID, c.showArchived)
+	} else {
+		nextForm, err = forms.NewClientSelectForm(&c.clientID, c.showArchived)
+	}
+	if err != nil {
+		return nil, status.Err("refreshing selection", err)
+	}
+
+	c.form = nextForm
+	return nil, c.form.Init()
+}
+
+// handleStepComplete handles the completion of the provider and client steps
+func (c *Controller) handleStepComplete() (*types.ViewTransition, tea.Cmd) {
 	switch c.currentStep {
 	case StepSelectProvider:
 		// Move to client selection
@@ -325,183 +771,288 @@ func (c *Controller) handleStepComplete(currentView types.View) (*types.ViewTran
 		var err error
 
 		if c.isEditMode && c.clientID != "" {
-			nextForm, err = forms.NewClientSelectFormWithData(&c.clientID, c.clientID)
+			nextForm, err = forms.NewClientSelectFormWithData(&c.clientID, c.clientID, c.showArchived)
 		} else {
-			nextForm, err = forms.NewClientSelectForm(&c.clientID)
+			nextForm, err = forms.NewClientSelectForm(&c.clientID, c.showArchived)
 		}
 
 		if err != nil {
-			log.Printf("Error creating client form: %v", err)
-			return nil, nil
+			return nil, status.Err("creating client form", err)
 		}
 		c.form = nextForm
 		return nil, c.form.Init()
 
 	case StepSelectClient:
-		// Move to item entry
-		c.currentStep = StepAddItem
-		var nextForm *huh.Form
-
-		// In edit mode, pre-populate with first existing item
-		if c.isEditMode && c.currentItemIndex < len(c.items) {
-			item := c.items[c.currentItemIndex]
-			c.itemName = item.Name
-			c.itemAmount = fmt.Sprintf("%.2f", item.Amount)
-			c.itemCostPerUnit = fmt.Sprintf("%.2f", item.CostPerUnit)
-			nextForm = forms.NewInvoiceItemForm(&c.itemName, &c.itemAmount, &c.itemCostPerUnit)
-		} else {
-			// Clear item fields for new item
-			c.itemName = ""
-			c.itemAmount = ""
-			c.itemCostPerUnit = ""
-			nextForm = forms.NewInvoiceItemForm(&c.itemName, &c.itemAmount, &c.itemCostPerUnit)
-		}
+		return c.showItems()
+	}
 
-		c.form = nextForm
-		return nil, c.form.Init()
+	return nil, nil
+}
 
-	case StepAddItem:
-		// Save the item
-		amount, err := strconv.ParseFloat(c.itemAmount, 64)
-		if err != nil {
-			log.Printf("Error parsing amount: %v", err)
-			return nil, nil
-		}
+// showItems shows the item editor for the invoice being created or edited
+func (c *Controller) showItems() (*types.ViewTransition, tea.Cmd) {
+	c.itemTable.SetRows(views.InvoiceItemRows(c.items))
+	c.form = nil
+	return &types.ViewTransition{
+		NewView: types.InvoiceItemsView,
+		Form:    nil,
+	}, nil
+}
 
-		costPerUnit, err := strconv.ParseFloat(c.itemCostPerUnit, 64)
-		if err != nil {
-			log.Printf("Error parsing cost per unit: %v", err)
-			return nil, nil
-		}
+// handleItemsView manages the item editor, where items are added, edited, removed and
+// reordered before moving on to the invoice details. Nothing is saved until then.
+func (c *Controller) handleItemsView(msg tea.Msg) (*types.ViewTransition, tea.Cmd) {
+	if handled, _ := c.itemTable.Update(msg); handled {
+		return nil, nil
+	}
 
-		// Store or update item in items slice
-		newItem := InvoiceItem{
-			Name:        c.itemName,
-			Amount:      amount,
-			CostPerUnit: costPerUnit,
-		}
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil, nil
+	}
 
-		if c.isEditMode && c.currentItemIndex < len(c.items) {
-			// Update existing item
-			c.items[c.currentItemIndex] = newItem
-			c.currentItemIndex++
-		} else {
-			// Add new item
-			c.items = append(c.items, newItem)
+	km := keys.Current()
+	i, selected := c.selectedItem()
+	switch {
+	case key.Matches(keyMsg, km.New):
+		c.itemIndex = -1
+		c.itemName = ""
+		c.itemAmount = ""
+		c.itemCostPerUnit = ""
+		c.form = forms.NewInvoiceItemForm(&c.itemName, &c.itemAmount, &c.itemCostPerUnit)
+		return types.FormTransition(types.InvoiceItemEditView, c.form)
+	case (key.Matches(keyMsg, km.Open) || key.Matches(keyMsg, km.Edit)) && selected:
+		c.itemIndex = i
+		c.form = forms.NewInvoiceItemFormWithData(&c.itemName, &c.itemAmount, &c.itemCostPerUnit, c.items[i])
+		return types.FormTransition(types.InvoiceItemEditView, c.form)
+	case key.Matches(keyMsg, km.Delete) && selected:
+		removed := c.items[i].ItemName
+		c.items = slices.Delete(c.items, i, i+1)
+		transition, cmd := c.showItems()
+		return transition, tea.Batch(cmd, status.Info(fmt.Sprintf("Removed %s", removed)))
+	case key.Matches(keyMsg, km.MoveUp) && selected && i > 0:
+		return c.swapItems(i, i-1)
+	case key.Matches(keyMsg, km.MoveDown) && selected && i < len(c.items)-1:
+		return c.swapItems(i, i+1)
+	case key.Matches(keyMsg, km.Continue):
+		if len(c.items) == 0 {
+			return nil, status.Info(fmt.Sprintf("An invoice needs an item; press %s to add one", km.New.Help().Key))
 		}
+		return c.showDetails()
+	}
 
-		// Check if there are more existing items to edit, or ask if user wants to add more
-		if c.isEditMode && c.currentItemIndex < len(c.existingItems) {
-			// Move to next existing item
-			c.currentStep = StepAddItem
-			item := c.items[c.currentItemIndex]
-			c.itemName = item.Name
-			c.itemAmount = fmt.Sprintf("%.2f", item.Amount)
-			c.itemCostPerUnit = fmt.Sprintf("%.2f", item.CostPerUnit)
-			nextForm := forms.NewInvoiceItemForm(&c.itemName, &c.itemAmount, &c.itemCostPerUnit)
-			c.form = nextForm
-			return nil, c.form.Init()
-		}
+	return nil, nil
+}
 
-		// Ask if user wants to add another item
-		c.currentStep = StepAskForMore
-		c.addAnother = false
-		nextForm := forms.NewAddAnotherItemForm(&c.addAnother)
-		c.form = nextForm
-		return nil, c.form.Init()
+// selectedItem returns the index of the item selected in the item editor,
+// reporting false if there are no items
+func (c *Controller) selectedItem() (int, bool) {
+	i, err := strconv.Atoi(c.itemTable.SelectedID())
+	if err != nil || i < 0 || i >= len(c.items) {
+		return 0, false
+	}
+	return i, true
+}
 
-	case StepAskForMore:
-		if c.addAnother {
-			// Add another item
-			c.currentStep = StepAddItem
-			c.itemName = ""
-			c.itemAmount = ""
-			c.itemCostPerUnit = ""
-			nextForm := forms.NewInvoiceItemForm(&c.itemName, &c.itemAmount, &c.itemCostPerUnit)
-			c.form = nextForm
-			return nil, c.form.Init()
-		}
+// swapItems swaps the items at i and j in the item editor, moving the selection along with the item at i
+func (c *Controller) swapItems(i, j int) (*types.ViewTransition, tea.Cmd) {
+	c.items[i], c.items[j] = c.items[j], c.items[i]
+	c.itemTable.SetRows(views.InvoiceItemRows(c.items))
+	c.itemTable.SelectID(strconv.Itoa(j))
+	return nil, nil
+}
 
-		// Move to mark paid
-		c.currentStep = StepMarkPaid
-		var nextForm *huh.Form
+// handleItemEditView manages the form for adding or editing a single item,
+// returning to the item editor once it is completed
+func (c *Controller) handleItemEditView(msg tea.Msg) (*types.ViewTransition, tea.Cmd) {
+	form, cmd := c.form.Update(msg)
+	if f, ok := form.(*huh.Form); ok {
+		c.form = f
+	}
 
-		if c.isEditMode {
-			nextForm = forms.NewMarkPaidFormWithData(&c.paid, c.paid)
-		} else {
-			c.paid = false
-			nextForm = forms.NewMarkPaidForm(&c.paid)
-		}
+	if c.form.State != huh.StateCompleted {
+		return nil, cmd
+	}
 
-		c.form = nextForm
-		return nil, c.form.Init()
+	amount, err := strconv.ParseFloat(c.itemAmount, 64)
+	if err != nil {
+		return nil, status.Err("parsing amount", err)
+	}
+	costPerUnit, err := strconv.ParseFloat(c.itemCostPerUnit, 64)
+	if err != nil {
+		return nil, status.Err("parsing cost per unit", err)
+	}
 
-	case StepMarkPaid:
-		// Save the invoice
-		return c.saveInvoice(currentView)
+	if c.itemIndex < 0 {
+		// New items go at the end, with the selection on them
+		c.items = append(c.items, models.InvoiceItem{ItemName: c.itemName, Amount: amount, CostPerUnit: costPerUnit})
+		c.itemIndex = len(c.items) - 1
+	} else {
+		// Edited items keep their ID, so the saved row is updated in place
+		item := &c.items[c.itemIndex]
+		item.ItemName, item.Amount, item.CostPerUnit = c.itemName, amount, costPerUnit
 	}
 
-	return nil, nil
+	transition, cmd := c.showItems()
+	c.itemTable.SelectID(strconv.Itoa(c.itemIndex))
+	return transition, cmd
 }
 
-// saveInvoice saves the invoice and all items to the database
-func (c *Controller) saveInvoice(currentView types.View) (*types.ViewTransition, tea.Cmd) {
-	if currentView == types.InvoiceCreateView {
-		// Create invoice
-		invoiceID, err := storage.CreateInvoice(c.providerID, c.clientID, c.paid)
-		if err != nil {
-			log.Printf("Error creating invoice: %v", err)
-			return nil, nil
-		}
+// showDetails loads the custom fields, tags, notes and due date of the invoice being
+// created or edited and asks for them along with whether it is paid
+func (c *Controller) showDetails() (*types.ViewTransition, tea.Cmd) {
+	invoiceID := ""
+	if c.isEditMode {
+		invoiceID = strconv.Itoa(c.invoiceID)
+	}
+	custom, err := forms.LoadCustomFieldInputs(models.EntityInvoice, invoiceID)
+	if err != nil {
+		return nil, status.Err("loading custom fields", err)
+	}
+	c.custom = custom
 
-		// Add all invoice items
-		for _, item := range c.items {
-			_, err = storage.AddInvoiceItem(invoiceID, item.Name, item.Amount, item.CostPerUnit)
-			if err != nil {
-				log.Printf("Error adding invoice item: %v", err)
-				return nil, nil
-			}
+	c.tags = ""
+	c.notes = ""
+	c.terms = ""
+	c.dueDate = ""
+	if c.isEditMode {
+		tags, err := storage.GetInvoiceTags(c.invoiceID)
+		if err != nil {
+			return nil, status.Err("loading tags", err)
 		}
+		c.tags = models.FormatTags(tags)
+		c.notes = c.invoiceData.Notes
+		c.terms = c.invoiceData.Terms
+		c.dueDate = forms.FormatDueDate(c.invoiceData.DueDate)
+		c.form = forms.NewMarkPaidFormWithData(&c.paid, c.invoiceData.Paid, c.markPaidGroups()...)
 	} else {
-		// Update invoice
-		err := storage.UpdateInvoice(c.invoiceID, c.providerID, c.clientID, c.paid)
-		if err != nil {
-			log.Printf("Error updating invoice: %v", err)
-			return nil, nil
+		if c.duplicateOf != nil {
+			c.notes = c.duplicateOf.Notes
+			c.terms = c.duplicateOf.Terms
 		}
+		c.paid = false
+		c.form = forms.NewMarkPaidForm(&c.paid, c.markPaidGroups()...)
+	}
 
-		// Delete all existing items and re-add
-		// This is simpler than trying to diff and update individual items
-		for _, existingItem := range c.existingItems {
-			err = storage.DeleteInvoiceItem(existingItem.ID)
-			if err != nil {
-				log.Printf("Error deleting invoice item: %v", err)
-				// Continue anyway
-			}
-		}
+	return types.FormTransition(types.InvoiceDetailsView, c.form)
+}
 
-		// Add all items
-		for _, item := range c.items {
-			_, err = storage.AddInvoiceItem(c.invoiceID, item.Name, item.Amount, item.CostPerUnit)
-			if err != nil {
-				log.Printf("Error adding invoice item: %v", err)
-				return nil, nil
-			}
-		}
+// handleDetailsView manages the paid, due date, tags, notes and custom field pages,
+// saving the invoice once they are completed
+func (c *Controller) handleDetailsView(msg tea.Msg) (*types.ViewTransition, tea.Cmd) {
+	form, cmd := c.form.Update(msg)
+	if f, ok := form.(*huh.Form); ok {
+		c.form = f
 	}
 
-	// Return to invoice list
-	c.selection = ""
-	invoiceForm, err := views.CreateInvoiceListForm(&c.selection)
+	if c.form.State == huh.StateCompleted {
+		return c.saveInvoice()
+	}
+
+	return nil, cmd
+}
+
+// saveInvoice saves the invoice with its items, notes, terms, due date, tags and custom field
+// values in a single transaction
+func (c *Controller) saveInvoice() (*types.ViewTransition, tea.Cmd) {
+	dueDate, err := forms.ParseDueDate(c.dueDate)
 	if err != nil {
-		log.Printf("Error creating invoice form: %v", err)
-		return nil, nil
+		return nil, status.Err("parsing due date", err)
 	}
-	c.form = invoiceForm
-	return &types.ViewTransition{
-		NewView: types.InvoicesListView,
-		Form:    c.form,
-	}, c.form.Init()
+
+	draft := models.InvoiceDraft{
+		ProviderID:   c.providerID,
+		ClientID:     c.clientID,
+		Paid:         c.paid,
+		Items:        c.items,
+		Tags:         models.ParseTags(c.tags),
+		Notes:        c.notes,
+		Terms:        c.terms,
+		DueDate:      dueDate,
+		CustomValues: c.custom.ValueMap(),
+	}
+	if c.isEditMode {
+		draft.ID = c.invoiceID
+	}
+
+	savedID, err := storage.SaveInvoice(draft)
+	if err != nil {
+		return nil, status.Err("saving invoice", err)
+	}
+	// The form stays completed if the list fails to reload, and saving again must
+	// update the invoice just created rather than create another
+	c.invoiceID = savedID
+	c.isEditMode = true
+
+	// Return to invoice list with the saved invoice selected
+	c.list.SelectID(strconv.Itoa(savedID))
+	return c.showInvoiceList(status.Success("Invoice saved"))
+}
+
+// markPaidGroups returns the due date, terms, tag, notes and custom field pages shown after the mark paid question
+func (c *Controller) markPaidGroups() []*huh.Group {
+	groups := []*huh.Group{forms.NewDueDateGroup(&c.dueDate), forms.NewTermsGroup(&c.terms), forms.NewTagsGroup(&c.tags), forms.NewNotesGroup(&c.notes)}
+	return append(groups, c.custom.Groups()...)
+}
+
+// listFilter returns the filter the invoice list is currently shown with
+func (c *Controller) listFilter() storage.InvoiceFilter {
+	return c.filter
+}
+
+// showFilter shows the form for filtering the invoice list by text, status, provider, client, date and total
+func (c *Controller) showFilter() (*types.ViewTransition, tea.Cmd) {
+	c.filterInputs = forms.NewInvoiceFilterInputs(c.filter)
+	form, err := c.filterInputs.Form()
+	if err != nil {
+		return nil, status.Err("creating filter form", err)
+	}
+	c.form = form
+	return types.FormTransition(types.InvoiceFilterView, c.form)
+}
+
+// handleFilterView applies the filter form to the invoice list once it is completed
+func (c *Controller) handleFilterView(msg tea.Msg) (*types.ViewTransition, tea.Cmd) {
+	form, cmd := c.form.Update(msg)
+	if f, ok := form.(*huh.Form); ok {
+		c.form = f
+	}
+
+	if c.form.State == huh.StateCompleted {
+		c.filter = c.filterInputs.Filter()
+		c.filterInputs = nil
+		return c.showInvoiceList()
+	}
+
+	return nil, cmd
+}
+
+// showTagFilter shows the form for choosing the tags the invoice list is filtered by
+func (c *Controller) showTagFilter() (*types.ViewTransition, tea.Cmd) {
+	tags, err := storage.ListTags()
+	if err != nil {
+		return nil, status.Err("loading tags", err)
+	}
+	if len(tags) == 0 && len(c.filter.Tags) == 0 {
+		return nil, status.Info("No tags yet; add some when editing an invoice")
+	}
+
+	c.form = forms.NewTagFilterForm(&c.filter.Tags, tags)
+	return types.FormTransition(types.InvoiceTagFilterView, c.form)
+}
+
+// handleTagFilterView applies the chosen tag filter to the invoice list
+func (c *Controller) handleTagFilterView(msg tea.Msg) (*types.ViewTransition, tea.Cmd) {
+	form, cmd := c.form.Update(msg)
+	if f, ok := form.(*huh.Form); ok {
+		c.form = f
+	}
+
+	if c.form.State == huh.StateCompleted {
+		return c.showInvoiceList()
+	}
+
+	return nil, cmd
 }
 
 // resetFormFields clears all form field values
@@ -512,12 +1063,18 @@ func (c *Controller) resetFormFields() {
 	c.itemAmount = ""
 	c.itemCostPerUnit = ""
 	c.paid = false
-	c.addAnother = false
-	c.existingItems = nil
 	c.items = nil
+	c.itemTable = views.NewInvoiceItemTable()
+	views.FitInvoiceItemTable(&c.itemTable, c.windowWidth, c.windowHeight)
+	c.itemIndex = -1
+	c.tags = ""
+	c.notes = ""
+	c.terms = ""
+	c.dueDate = ""
+	c.custom = nil
+	c.duplicateOf = nil
 	c.currentStep = StepSelectProvider
 	c.isEditMode = false
-	c.currentItemIndex = 0
 }
 
 // GetForm returns the current form
@@ -525,6 +1082,16 @@ func (c *Controller) GetForm() *huh.Form {
 	return c.form
 }
 
+// GetInvoiceList returns what the invoice list view shows
+func (c *Controller) GetInvoiceList() views.InvoiceList {
+	return views.InvoiceList{
+		Table:      c.list,
+		Filter:     c.filter,
+		TrashCount: c.trashCount,
+		Preview:    c.preview,
+	}
+}
+
 // GetInvoiceData returns the current invoice data for display
 func (c *Controller) GetInvoiceData() *models.InvoiceData {
 	return c.invoiceData
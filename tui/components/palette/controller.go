@@ -0,0 +1,222 @@
+// Package palette implements the command palette: a single place to start common actions,
+// such as creating an invoice or marking one paid, and to reopen recently used records
+package palette
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/GVPproj/termsheet/models"
+	"github.com/GVPproj/termsheet/storage"
+	"github.com/GVPproj/termsheet/tui/components/status"
+	"github.com/GVPproj/termsheet/tui/router"
+	"github.com/GVPproj/termsheet/tui/views"
+	"github.com/GVPproj/termsheet/types"
+	"github.com/GVPproj/termsheet/utils"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
+
+const (
+	// recentLimit is how many recently used records the palette offers
+	recentLimit = 10
+	// invoiceMatchLimit is how many invoices a number in the query finds
+	invoiceMatchLimit = 3
+	// commandLimit is how many commands the palette lists
+	commandLimit = 30
+)
+
+// RunMsg asks the root model to run a command picked in the palette
+type RunMsg struct {
+	Command views.PaletteCommand
+}
+
+// Controller manages the command palette view
+type Controller struct {
+	form      *huh.Form
+	query     string
+	selection views.PaletteCommand
+}
+
+// NewController creates a new command palette controller
+func NewController() *Controller {
+	return &Controller{}
+}
+
+// Open shows the palette with an empty query
+func (c *Controller) Open() (*types.ViewTransition, tea.Cmd) {
+	c.query = ""
+	return c.showForm()
+}
+
+// Routes lists the palette view and how it is drawn
+func (c *Controller) Routes() []router.Route {
+	return []router.Route{
+		{View: types.PaletteView, Title: "Commands", Render: views.RenderPalette},
+	}
+}
+
+// Update handles palette messages; picking a command returns a command emitting a RunMsg
+func (c *Controller) Update(msg tea.Msg, currentView types.View) (*types.ViewTransition, tea.Cmd) {
+	if currentView != types.PaletteView {
+		return nil, nil
+	}
+
+	form, cmd := c.form.Update(msg)
+	if f, ok := form.(*huh.Form); ok {
+		c.form = f
+	}
+
+	if c.form.State == huh.StateCompleted {
+		if c.selection.Action == "" {
+			// Nothing matched the query; keep typing
+			return c.showForm()
+		}
+		run := RunMsg{Command: c.selection}
+		return nil, func() tea.Msg { return run }
+	}
+
+	return nil, cmd
+}
+
+// Resume returns nil: going back skips the palette, to where it was opened from
+func (c *Controller) Resume(types.View) (*types.ViewTransition, tea.Cmd) {
+	return nil, nil
+}
+
+// showForm shows the palette form, keeping the current query
+func (c *Controller) showForm() (*types.ViewTransition, tea.Cmd) {
+	c.selection = views.PaletteCommand{}
+	c.form = views.CreatePaletteForm(&c.query, &c.selection, commandOptions)
+	return types.FormTransition(types.PaletteView, c.form)
+}
+
+// GetForm returns the current form
+func (c *Controller) GetForm() *huh.Form {
+	return c.form
+}
+
+// Remember records that the user opened the invoice, client or provider with the given ID,
+// so the palette offers it again. It returns a command reporting any failure to do so.
+func Remember(kind models.SearchKind, id string) tea.Cmd {
+	if err := storage.RecordRecent(kind, id); err != nil {
+		return status.Err("remembering recently used "+string(kind), err)
+	}
+	return nil
+}
+
+// commandOptions returns the commands matching query, or a note saying why there are none
+func commandOptions(query string) []views.PaletteCommand {
+	commands, err := Commands(query)
+	if err != nil {
+		return []views.PaletteCommand{{Label: "Loading commands failed: " + err.Error()}}
+	}
+	return commands
+}
+
+// Commands returns the commands matching query, best match first. Alongside the actions
+// that need no record, they open recently used records, and open or mark paid the recent
+// invoices and those whose number is in the query. There are no commands to export a PDF
+// or open a report: termsheet has neither yet.
+func Commands(query string) ([]views.PaletteCommand, error) {
+	commands := []views.PaletteCommand{
+		{Action: views.PaletteNewInvoice, Label: "New invoice"},
+		{Action: views.PaletteNewClient, Label: "New client"},
+		{Action: views.PaletteNewProvider, Label: "New provider"},
+	}
+
+	recent, err := storage.ListRecent(recentLimit)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int]bool)
+	for _, r := range recent {
+		switch r.Kind {
+		case models.SearchInvoice:
+			// Recent invoices can be opened straight away; the other
+			// invoice commands wait for a query to choose between them
+			if strings.TrimSpace(query) == "" {
+				commands = append(commands, openInvoice(r.InvoiceID, r.DisplayNumber(), r.Name))
+			} else {
+				commands = append(commands, invoiceCommands(r.InvoiceID, r.DisplayNumber(), r.Name, r.Paid)...)
+			}
+			seen[r.InvoiceID] = true
+		case models.SearchClient:
+			commands = append(commands, views.PaletteCommand{Action: views.PaletteOpenClient, ID: r.EntityID, Label: "Open client " + r.Name})
+		case models.SearchProvider:
+			commands = append(commands, views.PaletteCommand{Action: views.PaletteOpenProvider, ID: r.EntityID, Label: "Open provider " + r.Name})
+		}
+	}
+
+	for _, ref := range invoiceRefs(query) {
+		invoices, err := storage.FindInvoicesByNumber(ref, invoiceMatchLimit)
+		if err != nil {
+			return nil, err
+		}
+		for _, inv := range invoices {
+			if !seen[inv.ID] {
+				commands = append(commands, invoiceCommands(inv.ID, inv.DisplayNumber(), inv.ClientName, inv.Paid)...)
+				seen[inv.ID] = true
+			}
+		}
+	}
+
+	return rank(query, commands), nil
+}
+
+// invoiceCommands returns the commands for an invoice: open it, and mark it paid unless it is
+func invoiceCommands(invoiceID int, number, client string, paid bool) []views.PaletteCommand {
+	commands := []views.PaletteCommand{openInvoice(invoiceID, number, client)}
+	if !paid {
+		commands = append(commands, views.PaletteCommand{
+			Action: views.PaletteMarkPaid, ID: strconv.Itoa(invoiceID), Label: fmt.Sprintf("Mark invoice %s paid · %s", number, client),
+		})
+	}
+	return commands
+}
+
+// openInvoice returns the command opening an invoice
+func openInvoice(invoiceID int, number, client string) views.PaletteCommand {
+	return views.PaletteCommand{
+		Action: views.PaletteOpenInvoice, ID: strconv.Itoa(invoiceID), Label: fmt.Sprintf("Open invoice %s · %s", number, client),
+	}
+}
+
+// invoiceRefs returns the words in query that may be invoice numbers, i.e. those with a digit in them
+func invoiceRefs(query string) []string {
+	var refs []string
+	for _, word := range strings.Fields(query) {
+		if strings.ContainsFunc(word, unicode.IsDigit) {
+			refs = append(refs, word)
+		}
+	}
+	return refs
+}
+
+// rank returns the commands whose label fuzzily matches query, best match first,
+// keeping the order of commands that match equally well
+func rank(query string, commands []views.PaletteCommand) []views.PaletteCommand {
+	type match struct {
+		command views.PaletteCommand
+		score   int
+	}
+	var matches []match
+	for _, cmd := range commands {
+		if score, ok := utils.FuzzyMatch(query, cmd.Label); ok {
+			matches = append(matches, match{cmd, score})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b match) int {
+		return cmp.Compare(b.score, a.score)
+	})
+
+	ranked := make([]views.PaletteCommand, 0, min(len(matches), commandLimit))
+	for _, m := range matches[:min(len(matches), commandLimit)] {
+		ranked = append(ranked, m.command)
+	}
+	return ranked
+}
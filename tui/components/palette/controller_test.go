@@ -0,0 +1,63 @@
+package palette
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/GVPproj/termsheet/tui/views"
+)
+
+func TestRank(t *testing.T) {
+	commands := []views.PaletteCommand{
+		{Action: views.PaletteNewInvoice, Label: "New invoice"},
+		{Action: views.PaletteNewClient, Label: "New client"},
+		{Action: views.PaletteOpenInvoice, ID: "7", Label: "Open invoice INV-0142 · Globex"},
+		{Action: views.PaletteMarkPaid, ID: "7", Label: "Mark invoice INV-0142 paid · Globex"},
+	}
+
+	tests := []struct {
+		query string
+		first views.PaletteAction
+		count int
+	}{
+		{"", views.PaletteNewInvoice, 4},
+		{"new cl", views.PaletteNewClient, 1},
+		{"mark 142 paid", views.PaletteMarkPaid, 1},
+		{"inv", views.PaletteNewInvoice, 3},
+	}
+
+	for _, tt := range tests {
+		ranked := rank(tt.query, commands)
+		if len(ranked) != tt.count || ranked[0].Action != tt.first {
+			t.Errorf("rank(%q): expected %d commands starting with %s, got %+v", tt.query, tt.count, tt.first, ranked)
+		}
+	}
+}
+
+func TestInvoiceRefs(t *testing.T) {
+	if refs := invoiceRefs("mark INV-0142 paid #7"); !slices.Equal(refs, []string{"INV-0142", "#7"}) {
+		t.Errorf("expected the words with digits, got %q", refs)
+	}
+	if refs := invoiceRefs("new invoice"); refs != nil {
+		t.Errorf("expected no invoice numbers, got %q", refs)
+	}
+}
+
+func TestInvoiceCommands(t *testing.T) {
+	actions := func(commands []views.PaletteCommand) []views.PaletteAction {
+		var actions []views.PaletteAction
+		for _, command := range commands {
+			actions = append(actions, command.Action)
+		}
+		return actions
+	}
+
+	unpaid := actions(invoiceCommands(7, "INV-0142", "Globex", false))
+	if !slices.Equal(unpaid, []views.PaletteAction{views.PaletteOpenInvoice, views.PaletteMarkPaid}) {
+		t.Errorf("expected open and mark paid for an unpaid invoice, got %v", unpaid)
+	}
+	paid := actions(invoiceCommands(7, "INV-0142", "Globex", true))
+	if !slices.Equal(paid, []views.PaletteAction{views.PaletteOpenInvoice}) {
+		t.Errorf("expected only open for a paid invoice, got %v", paid)
+	}
+}
@@ -2,13 +2,20 @@
 package provider
 
 import (
-	"log"
+	"fmt"
 
 	"github.com/GVPproj/termsheet/models"
+	"github.com/GVPproj/termsheet/numbering"
 	"github.com/GVPproj/termsheet/storage"
+	"github.com/GVPproj/termsheet/tui/components/palette"
+	"github.com/GVPproj/termsheet/tui/components/status"
+	"github.com/GVPproj/termsheet/tui/components/table"
 	"github.com/GVPproj/termsheet/tui/forms"
+	"github.com/GVPproj/termsheet/tui/keys"
+	"github.com/GVPproj/termsheet/tui/router"
 	"github.com/GVPproj/termsheet/tui/views"
 	"github.com/GVPproj/termsheet/types"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
 )
@@ -16,8 +23,13 @@ import (
 // Controller manages provider-related state and behavior
 type Controller struct {
 	// Form state
-	form      *huh.Form
-	selection string
+	form *huh.Form
+
+	// Provider list; a table rather than a form so long lists are paged
+	list table.Model
+	// Terminal size last given to Resize
+	windowWidth  int
+	windowHeight int
 
 	// Provider form fields
 	name    string
@@ -25,28 +37,77 @@ type Controller struct {
 	email   string
 	phone   string
 
+	// Business and payment details
+	profile models.ProviderProfile
+
+	// Custom field values
+	custom *forms.CustomFieldInputs
+
+	// Invoice numbering scheme fields
+	numberFormat string
+	numberReset  string
+
 	// Edit state
 	selectedID string
 
 	// Delete confirmation
 	deleteConfirmed bool
 	deleteID        string
+
+	// Whether archived entries are listed
+	showArchived bool
+
+	// Free-text search the list is filtered by
+	search string
 }
 
 // NewController creates a new provider controller
 func NewController() *Controller {
-	return &Controller{}
+	return &Controller{list: views.NewEntityTable("provider")}
 }
 
-// InitListView initializes the provider list view
-func (c *Controller) InitListView() (*huh.Form, error) {
-	c.selection = ""
-	providerForm, err := views.CreateProviderListForm(&c.selection)
-	if err != nil {
-		return nil, err
+// InitListView shows the provider list with no search applied
+func (c *Controller) InitListView() (*types.ViewTransition, tea.Cmd) {
+	c.search = ""
+	c.list = views.NewEntityTable("provider")
+	c.resizeList()
+	return c.showList()
+}
+
+// Resize fits the provider list to a terminal of the given size; zeros leave the list at its default size
+func (c *Controller) Resize(width, height int) {
+	c.windowWidth, c.windowHeight = width, height
+	c.resizeList()
+}
+
+// resizeList fits the list to the terminal size last given to Resize
+func (c *Controller) resizeList() {
+	width, height := views.ListTableSize(c.windowWidth, c.windowHeight)
+	c.list.SetWidth(width)
+	if height > 0 {
+		c.list.SetHeight(height)
 	}
-	c.form = providerForm
-	return c.form, nil
+}
+
+// MenuEntry lists the providers in the main menu
+func (c *Controller) MenuEntry() router.MenuEntry {
+	return router.MenuEntry{Key: "Providers", Label: "Providers - Who is invoicing?", Open: c.InitListView}
+}
+
+// Routes lists the provider views and how they are drawn
+func (c *Controller) Routes() []router.Route {
+	return []router.Route{
+		{View: types.ProvidersListView, Title: "Providers", Render: c.renderList, Shortcuts: true, Keys: views.ProviderListKeys()},
+		{View: types.ProviderCreateView, Title: "New provider", Render: views.RenderProviders, Prompt: true},
+		{View: types.ProviderEditView, Title: "Edit provider", Render: views.RenderProviders, Prompt: true},
+		{View: types.ProviderDeleteConfirmView, Title: "Delete", Render: views.RenderDeleteConfirm, Prompt: true},
+		{View: types.ProviderFilterView, Title: "Filter", Render: views.RenderProviders, Prompt: true},
+	}
+}
+
+// renderList draws the provider list, which is a table rather than a form
+func (c *Controller) renderList(_ *huh.Form, _ int) string {
+	return views.RenderProviderList(views.EntityList{Table: c.list, Filter: c.listFilter()})
 }
 
 // Update handles provider-related messages and returns view transition if needed
@@ -55,74 +116,97 @@ func (c *Controller) Update(msg tea.Msg, currentView types.View) (*types.ViewTra
 	case types.ProvidersListView:
 		return c.handleListView(msg)
 	case types.ProviderCreateView, types.ProviderEditView:
-		return c.handleFormView(msg, currentView)
+		return c.handleFormView(msg)
 	case types.ProviderDeleteConfirmView:
 		return c.handleDeleteConfirmView(msg)
+	case types.ProviderFilterView:
+		return c.handleFilterView(msg)
 	}
 	return nil, nil
 }
 
 // handleListView manages the provider list view logic
 func (c *Controller) handleListView(msg tea.Msg) (*types.ViewTransition, tea.Cmd) {
-	// Handle delete key before passing to form
-	if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "d" {
-		if c.selection != "" && c.selection != "CREATE_NEW" {
-			// Show delete confirmation
-			c.deleteID = c.selection
-			c.deleteConfirmed = false
-			c.form = forms.NewDeleteConfirmForm(&c.deleteConfirmed)
-			return &types.ViewTransition{
-				NewView: types.ProviderDeleteConfirmView,
-				Form:    c.form,
-			}, c.form.Init()
+	if handled, err := c.list.Update(msg); handled {
+		if err != nil {
+			return nil, status.Err("loading providers", err)
 		}
+		return nil, nil
 	}
 
-	// Update form
-	form, cmd := c.form.Update(msg)
-	if f, ok := form.(*huh.Form); ok {
-		c.form = f
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil, nil
 	}
 
-	// Check if form is completed
-	if c.form.State == huh.StateCompleted {
-		if c.selection == "CREATE_NEW" {
-			// Navigate to create provider view
-			c.resetFormFields()
-			c.form = forms.NewProviderForm(&c.name, &c.address, &c.email, &c.phone)
-			return &types.ViewTransition{
-				NewView: types.ProviderCreateView,
-				Form:    c.form,
-			}, c.form.Init()
-		} else {
-			// Navigate to edit provider view
-			c.selectedID = c.selection
-			// Get provider data
-			providers, err := storage.ListProviders()
-			if err != nil {
-				log.Printf("Error loading provider: %v", err)
-				return nil, nil
-			}
-			var selectedProvider *models.Entity
-			for _, p := range providers {
-				if p.ID == c.selectedID {
-					selectedProvider = &p
-					break
-				}
-			}
-			if selectedProvider == nil {
-				log.Printf("Provider not found: %s", c.selectedID)
-				return nil, nil
-			}
-			c.form = forms.NewProviderFormWithData(*selectedProvider, &c.name, &c.address, &c.email, &c.phone)
-			return &types.ViewTransition{
-				NewView: types.ProviderEditView,
-				Form:    c.form,
-			}, c.form.Init()
-		}
+	km := keys.Current()
+	providerID := c.list.SelectedID()
+	selected := providerID != ""
+	switch {
+	case key.Matches(keyMsg, km.Open) && selected:
+		return c.Edit(providerID)
+	case key.Matches(keyMsg, km.New):
+		return c.New()
+	case key.Matches(keyMsg, km.Delete) && selected:
+		// Show delete confirmation
+		c.deleteID = providerID
+		c.deleteConfirmed = false
+		c.form = forms.NewDeleteConfirmForm(&c.deleteConfirmed)
+		return types.FormTransition(types.ProviderDeleteConfirmView, c.form)
+	case key.Matches(keyMsg, km.Archive) && selected:
+		return c.toggleArchived(providerID)
+	case key.Matches(keyMsg, km.ShowArchived):
+		c.showArchived = !c.showArchived
+		return c.showList()
+	case key.Matches(keyMsg, km.Filter):
+		c.form = forms.NewSearchFilterForm(&c.search)
+		return types.FormTransition(types.ProviderFilterView, c.form)
 	}
 
-	return nil, cmd
+	return nil, nil
+}
+
+// New opens the form for a new provider
+func (c *Controller) New() (*types.ViewTransition, tea.Cmd) {
+	c.resetFormFields()
+	c.selectedID = ""
+	custom, err := forms.LoadCustomFieldInputs(models.EntityProvider, "")
+	if err != nil {
+		return nil, status.Err("loading custom fields", err)
+	}
+	c.custom = custom
+	c.form = forms.NewProviderForm(&c.name, &c.address, &c.email, &c.phone, c.extraGroups()...)
+	return types.FormTransition(types.ProviderCreateView, c.form)
+}
+
+// Edit opens the edit form for the provider with the given ID
+func (c *Controller) Edit(providerID string) (*types.ViewTransition, tea.Cmd) {
+	c.selectedID = providerID
+	// Get provider data
+	selectedProvider, err := storage.GetProvider(c.selectedID)
+	if err != nil {
+		return nil, status.Err("loading provider", err)
+	}
+	scheme, err := storage.GetNumberingScheme(c.selectedID)
+	if err != nil {
+		return nil, status.Err("loading numbering scheme", err)
+	}
+	c.numberFormat = scheme.Format
+	c.numberReset = string(scheme.Reset)
+	c.profile, err = storage.GetProviderProfile(c.selectedID)
+	if err != nil {
+		return nil, status.Err("loading provider profile", err)
+	}
+	c.custom, err = forms.LoadCustomFieldInputs(models.EntityProvider, c.selectedID)
+	if err != nil {
+		return nil, status.Err("loading custom fields", err)
+	}
+	c.form = forms.NewProviderFormWithData(*selectedProvider, &c.name, &c.address, &c.email, &c.phone, c.extraGroups()...)
+	return &types.ViewTransition{
+		NewView: types.ProviderEditView,
+		Form:    c.form,
+		Crumb:   selectedProvider.Name,
+	}, tea.Batch(c.form.Init(), palette.Remember(models.SearchProvider, c.selectedID))
 }
 
 // handleDeleteConfirmView manages the delete confirmation view
@@ -135,37 +219,27 @@ func (c *Controller) handleDeleteConfirmView(msg tea.Msg) (*types.ViewTransition
 
 	// Check if form is completed
 	if c.form.State == huh.StateCompleted {
-		var errorMsg string
+		var notice tea.Cmd
 		if c.deleteConfirmed {
 			// Delete the provider
 			err := storage.DeleteProvider(c.deleteID)
 			if err != nil {
-				log.Printf("Error deleting provider: %v", err)
-				errorMsg = err.Error()
+				notice = status.Err("deleting provider", err)
+			} else {
+				notice = status.Success("Provider deleted")
 			}
 		}
 
 		// Refresh the provider list
-		c.selection = ""
 		c.deleteID = ""
-		providerForm, err := views.CreateProviderListFormWithError(&c.selection, errorMsg)
-		if err != nil {
-			log.Printf("Error refreshing provider list: %v", err)
-			return nil, nil
-		}
-
-		c.form = providerForm
-		return &types.ViewTransition{
-			NewView: types.ProvidersListView,
-			Form:    c.form,
-		}, c.form.Init()
+		return c.showList(notice)
 	}
 
 	return nil, cmd
 }
 
 // handleFormView manages create and edit form views
-func (c *Controller) handleFormView(msg tea.Msg, currentView types.View) (*types.ViewTransition, tea.Cmd) {
+func (c *Controller) handleFormView(msg tea.Msg) (*types.ViewTransition, tea.Cmd) {
 	// Update form
 	form, cmd := c.form.Update(msg)
 	if f, ok := form.(*huh.Form); ok {
@@ -187,43 +261,112 @@ func (c *Controller) handleFormView(msg tea.Msg, currentView types.View) (*types
 			phonePtr = &c.phone
 		}
 
-		if currentView == types.ProviderCreateView {
-			_, err := storage.CreateProvider(c.name, addressPtr, emailPtr, phonePtr)
-			if err != nil {
-				log.Printf("Error creating provider: %v", err)
-				return nil, nil
-			}
-		} else {
-			err := storage.UpdateProvider(c.selectedID, c.name, addressPtr, emailPtr, phonePtr)
-			if err != nil {
-				log.Printf("Error updating provider: %v", err)
-				return nil, nil
-			}
+		draft := models.ProviderDraft{
+			ID:      c.selectedID,
+			Name:    c.name,
+			Address: addressPtr,
+			Email:   emailPtr,
+			Phone:   phonePtr,
+			Profile: c.profile,
+			Numbering: models.NumberingScheme{
+				Format: c.numberFormat,
+				Reset:  numbering.Reset(c.numberReset),
+			},
+			CustomValues: c.custom.ValueMap(),
 		}
-
-		// Return to provider list
-		c.selection = ""
-		providerForm, err := views.CreateProviderListForm(&c.selection)
+		providerID, err := storage.SaveProvider(draft)
 		if err != nil {
-			log.Printf("Error creating provider form: %v", err)
-			return nil, nil
+			return nil, status.Err("saving provider", err)
+		}
+
+		// Return to provider list with the saved provider selected
+		c.list.SelectID(providerID)
+		return c.showList(status.Success("Provider saved"))
+	}
+
+	return nil, cmd
+}
+
+// extraGroups returns the form pages shown after the provider's contact details
+func (c *Controller) extraGroups() []*huh.Group {
+	groups := forms.NewProviderProfileGroups(&c.profile)
+	groups = append(groups, c.custom.Groups()...)
+	return append(groups, forms.NewNumberingGroup(&c.numberFormat, &c.numberReset))
+}
+
+// toggleArchived archives the provider with the given ID, or restores it if it is already archived
+func (c *Controller) toggleArchived(providerID string) (*types.ViewTransition, tea.Cmd) {
+	provider, err := storage.GetProvider(providerID)
+	if err != nil {
+		return nil, status.Err("loading provider", err)
+	}
+
+	var notice tea.Cmd
+	if provider.Archived {
+		if err := storage.RestoreProvider(providerID); err != nil {
+			return nil, status.Err("restoring provider", err)
+		}
+		notice = status.Success(fmt.Sprintf("Restored %s", provider.Name))
+	} else {
+		if err := storage.ArchiveProvider(providerID); err != nil {
+			return nil, status.Err("archiving provider", err)
 		}
-		c.form = providerForm
-		return &types.ViewTransition{
-			NewView: types.ProvidersListView,
-			Form:    c.form,
-		}, c.form.Init()
+		notice = status.Success(fmt.Sprintf("Archived %s", provider.Name))
+	}
+
+	return c.showList(notice)
+}
+
+// handleFilterView applies the search entered in the filter form to the provider list
+func (c *Controller) handleFilterView(msg tea.Msg) (*types.ViewTransition, tea.Cmd) {
+	form, cmd := c.form.Update(msg)
+	if f, ok := form.(*huh.Form); ok {
+		c.form = f
+	}
+
+	if c.form.State == huh.StateCompleted {
+		return c.showList()
 	}
 
 	return nil, cmd
 }
 
+// listFilter returns the filter the provider list is currently shown with
+func (c *Controller) listFilter() storage.EntityFilter {
+	return storage.EntityFilter{IncludeArchived: c.showArchived, Search: c.search}
+}
+
+// Resume shows the provider list again when the user goes back to it, with the
+// provider they last picked still selected. Forms can't be resumed; it returns nil for them.
+func (c *Controller) Resume(view types.View) (*types.ViewTransition, tea.Cmd) {
+	if view != types.ProvidersListView {
+		return nil, nil
+	}
+	return c.showList()
+}
+
+// showList reloads the provider list and transitions to it, running any extra commands.
+// The list is a table rather than a form, and keeps the selected provider if it is still listed.
+func (c *Controller) showList(cmds ...tea.Cmd) (*types.ViewTransition, tea.Cmd) {
+	if err := views.LoadEntityRows(&c.list, "provider", c.listFilter()); err != nil {
+		return nil, status.Err("refreshing provider list", err)
+	}
+	c.form = nil
+	return &types.ViewTransition{
+		NewView: types.ProvidersListView,
+		Form:    nil,
+	}, tea.Batch(cmds...)
+}
+
 // resetFormFields clears all form field values
 func (c *Controller) resetFormFields() {
 	c.name = ""
 	c.address = ""
 	c.email = ""
 	c.phone = ""
+	c.profile = models.ProviderProfile{}
+	c.numberFormat = ""
+	c.numberReset = string(numbering.ResetNever)
 }
 
 // GetForm returns the current form
@@ -0,0 +1,113 @@
+// Package search implements the global search view
+package search
+
+import (
+	"github.com/GVPproj/termsheet/models"
+	"github.com/GVPproj/termsheet/tui/keys"
+	"github.com/GVPproj/termsheet/tui/router"
+	"github.com/GVPproj/termsheet/tui/views"
+	"github.com/GVPproj/termsheet/types"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
+
+// OpenMsg asks the root model to open a search result in the view that owns it
+type OpenMsg struct {
+	// Kind is SearchInvoice, SearchClient or SearchProvider
+	Kind models.SearchKind
+	ID   string
+	// Edit opens the edit form instead of the read-only view where there is one
+	Edit bool
+}
+
+// Controller manages the global search view
+type Controller struct {
+	form      *huh.Form
+	query     string
+	selection string
+}
+
+// NewController creates a new search controller
+func NewController() *Controller {
+	return &Controller{}
+}
+
+// Open starts a new search
+func (c *Controller) Open() (*types.ViewTransition, tea.Cmd) {
+	c.query = ""
+	return c.showForm()
+}
+
+// Routes lists the search view and how it is drawn
+func (c *Controller) Routes() []router.Route {
+	return []router.Route{
+		{View: types.SearchView, Title: "Search", Render: views.RenderSearch},
+	}
+}
+
+// Update handles search messages; picking a result returns a command emitting an OpenMsg
+func (c *Controller) Update(msg tea.Msg, currentView types.View) (*types.ViewTransition, tea.Cmd) {
+	if currentView != types.SearchView {
+		return nil, nil
+	}
+
+	// ctrl+e, unless rebound, opens the highlighted result for editing
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && key.Matches(keyMsg, keys.Current().EditResult) {
+		if cmd := c.open(true); cmd != nil {
+			return nil, cmd
+		}
+	}
+
+	form, cmd := c.form.Update(msg)
+	if f, ok := form.(*huh.Form); ok {
+		c.form = f
+	}
+
+	if c.form.State == huh.StateCompleted {
+		if open := c.open(false); open != nil {
+			return nil, open
+		}
+		// Nothing was selected, e.g. there were no results; keep searching
+		return c.showForm()
+	}
+
+	return nil, cmd
+}
+
+// open returns a command opening the selected result, or nil if there is none
+func (c *Controller) open(edit bool) tea.Cmd {
+	kind, id, ok := views.ParseSearchTarget(c.selection)
+	if !ok {
+		return nil
+	}
+	return func() tea.Msg {
+		return OpenMsg{Kind: kind, ID: id, Edit: edit}
+	}
+}
+
+// Resume shows the search again when the user goes back to it from a result,
+// with the same query and the result they opened still selected
+func (c *Controller) Resume(view types.View) (*types.ViewTransition, tea.Cmd) {
+	if view != types.SearchView {
+		return nil, nil
+	}
+	return c.searchView()
+}
+
+// showForm shows the search form, keeping the current query
+func (c *Controller) showForm() (*types.ViewTransition, tea.Cmd) {
+	c.selection = ""
+	return c.searchView()
+}
+
+// searchView builds the search form for the current query and selection
+func (c *Controller) searchView() (*types.ViewTransition, tea.Cmd) {
+	c.form = views.CreateSearchForm(&c.query, &c.selection)
+	return types.FormTransition(types.SearchView, c.form)
+}
+
+// GetForm returns the current form
+func (c *Controller) GetForm() *huh.Form {
+	return c.form
+}
@@ -0,0 +1,185 @@
+// Package status implements the notification bar shown beneath every view.
+// Controllers report outcomes by returning the commands built by Success, Warn,
+// Info and Err; the root model feeds the resulting messages into a Model.
+package status
+
+import (
+	"log"
+	"time"
+
+	"github.com/GVPproj/termsheet/tui/theme"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Level is the severity of a notification
+type Level int
+
+const (
+	LevelInfo Level = iota
+	LevelSuccess
+	LevelWarning
+	LevelError
+)
+
+// How long each level stays on screen before being dismissed
+var durations = map[Level]time.Duration{
+	LevelInfo:    3 * time.Second,
+	LevelSuccess: 3 * time.Second,
+	LevelWarning: 5 * time.Second,
+	LevelError:   8 * time.Second,
+}
+
+// Msg asks the status bar to show a notification
+type Msg struct {
+	Level Level
+	Text  string
+	// Duration overrides how long the message stays on screen; zero uses the level's default
+	Duration time.Duration
+}
+
+// Error is a failed operation reported by a controller.
+// It is delivered to the root model as a tea.Msg and shown as an error notification.
+type Error struct {
+	Op  string // what was being attempted, e.g. "deleting invoice"
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Op + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// clearMsg dismisses the notification with the given id once its time is up
+type clearMsg struct {
+	id int
+}
+
+// Err returns a command reporting that op failed with err.
+// The failure is also written to the log file for later inspection.
+func Err(op string, err error) tea.Cmd {
+	log.Printf("Error %s: %v", op, err)
+	return func() tea.Msg {
+		return &Error{Op: op, Err: err}
+	}
+}
+
+// Success returns a command showing a success notification
+func Success(text string) tea.Cmd {
+	return notify(LevelSuccess, text)
+}
+
+// Warn returns a command showing a warning notification
+func Warn(text string) tea.Cmd {
+	return notify(LevelWarning, text)
+}
+
+// Info returns a command showing an informational notification
+func Info(text string) tea.Cmd {
+	return notify(LevelInfo, text)
+}
+
+// InfoFor returns a command showing an informational notification for d,
+// for messages such as undo prompts whose lifetime matters
+func InfoFor(text string, d time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		return Msg{Level: LevelInfo, Text: text, Duration: d}
+	}
+}
+
+func notify(level Level, text string) tea.Cmd {
+	return func() tea.Msg {
+		return Msg{Level: level, Text: text}
+	}
+}
+
+var (
+	baseStyle = lipgloss.NewStyle().Padding(0, 1)
+
+	// levelStyles colour each level with the current theme
+	levelStyles = map[Level]lipgloss.Style{}
+
+	levelIcons = map[Level]string{
+		LevelInfo:    "ℹ",
+		LevelSuccess: "✓",
+		LevelWarning: "⚠",
+		LevelError:   "✗",
+	}
+)
+
+func init() {
+	theme.OnChange(func(t theme.Theme) {
+		levelStyles[LevelInfo] = baseStyle.Foreground(t.Info)
+		levelStyles[LevelSuccess] = baseStyle.Foreground(t.Success)
+		levelStyles[LevelWarning] = baseStyle.Foreground(t.Warning)
+		levelStyles[LevelError] = baseStyle.Foreground(t.Error).Bold(true)
+	})
+}
+
+// Model holds the notification currently on screen, if any
+type Model struct {
+	level Level
+	text  string
+	// id increases with every notification so a stale dismiss timer
+	// can't clear a newer message
+	id int
+}
+
+// New creates an empty status bar
+func New() Model {
+	return Model{}
+}
+
+// Update handles notification messages. handled reports whether msg belonged to
+// the status bar, in which case it should not be passed on to the views.
+func (m *Model) Update(msg tea.Msg) (handled bool, cmd tea.Cmd) {
+	switch msg := msg.(type) {
+	case Msg:
+		d := msg.Duration
+		if d == 0 {
+			d = durations[msg.Level]
+		}
+		return true, m.show(msg.Level, msg.Text, d)
+	case *Error:
+		return true, m.show(LevelError, "Error "+msg.Error(), durations[LevelError])
+	case clearMsg:
+		if msg.id == m.id {
+			m.text = ""
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// show displays text and schedules its dismissal after d
+func (m *Model) show(level Level, text string, d time.Duration) tea.Cmd {
+	m.id++
+	m.level = level
+	m.text = text
+
+	id := m.id
+	return tea.Tick(d, func(time.Time) tea.Msg {
+		return clearMsg{id: id}
+	})
+}
+
+// Text returns the notification currently shown, or "" if there is none
+func (m Model) Text() string {
+	return m.text
+}
+
+// Level returns the level of the notification currently shown
+func (m Model) Level() Level {
+	return m.level
+}
+
+// View renders the status bar, or an empty string if there is nothing to show
+func (m Model) View() string {
+	if m.text == "" {
+		return ""
+	}
+	return levelStyles[m.level].Render(levelIcons[m.level] + " " + m.text)
+}
@@ -0,0 +1,72 @@
+package status
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestErrCommandProducesTypedError(t *testing.T) {
+	cause := errors.New("disk full")
+
+	msg := Err("saving invoice", cause)()
+
+	statusErr, ok := msg.(*Error)
+	if !ok {
+		t.Fatalf("expected *Error, got %T", msg)
+	}
+	if statusErr.Op != "saving invoice" {
+		t.Errorf("expected op 'saving invoice', got %q", statusErr.Op)
+	}
+	if !errors.Is(statusErr, cause) {
+		t.Error("expected error to unwrap to its cause")
+	}
+}
+
+func TestModelShowsAndDismissesNotifications(t *testing.T) {
+	m := New()
+
+	handled, cmd := m.Update(Success("Invoice saved")())
+	if !handled {
+		t.Fatal("expected status message to be handled")
+	}
+	if cmd == nil {
+		t.Fatal("expected a dismiss timer command")
+	}
+	if !strings.Contains(m.View(), "Invoice saved") {
+		t.Errorf("expected view to contain the message, got %q", m.View())
+	}
+
+	// A newer message must not be cleared by the first message's timer
+	m.Update(Warn("Something odd")())
+	m.Update(clearMsg{id: 1})
+	if m.Text() != "Something odd" {
+		t.Errorf("stale timer cleared newer message, text is %q", m.Text())
+	}
+
+	m.Update(clearMsg{id: 2})
+	if m.View() != "" {
+		t.Errorf("expected empty view after dismissal, got %q", m.View())
+	}
+}
+
+func TestModelIgnoresOtherMessages(t *testing.T) {
+	m := New()
+
+	if handled, _ := m.Update("unrelated"); handled {
+		t.Error("expected unrelated messages to be passed through")
+	}
+}
+
+func TestModelShowsErrors(t *testing.T) {
+	m := New()
+
+	m.Update(Err("deleting invoice", errors.New("not found"))())
+
+	if m.Level() != LevelError {
+		t.Errorf("expected error level, got %v", m.Level())
+	}
+	if !strings.Contains(m.Text(), "deleting invoice: not found") {
+		t.Errorf("unexpected text %q", m.Text())
+	}
+}
@@ -0,0 +1,558 @@
+// Package table implements a scrolling table with a sticky header.
+// The table only displays rows; sorting and filtering are left to the caller,
+// which either passes all rows to SetRows or, for large lists, a Source that
+// the table pages through as the cursor moves. Only the rows on screen are rendered.
+package table
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/GVPproj/termsheet/tui/theme"
+	"github.com/GVPproj/termsheet/utils"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DefaultHeight is how many rows are shown when no height is set
+const DefaultHeight = 15
+
+// minPageSize is the fewest rows loaded from a Source at a time; the table keeps
+// at most three pages in memory
+const minPageSize = 100
+
+// Source loads the rows of a table a page at a time, by keyset: pages are positioned
+// relative to a row ID rather than an offset
+type Source interface {
+	// Count returns how many rows there are
+	Count() (int, error)
+	// Page returns up to limit rows following the row with the given ID, or preceding it
+	// when backward is set, in table order. With no ID, the page is the start of the
+	// table, or its end when backward is set.
+	Page(id string, backward bool, limit int) ([]Row, error)
+}
+
+// Column describes a table column
+type Column struct {
+	Title string
+	// Width is the column's width in cells, not counting the gap between columns
+	Width int
+	// AlignRight right-aligns the column, e.g. for amounts
+	AlignRight bool
+	// Flex columns shrink when the table is too narrow for them, down to minFlexWidth
+	Flex bool
+	// Optional columns are hidden, rightmost first, when the table is too narrow
+	// for them even with its flex columns shrunk
+	Optional bool
+}
+
+// minFlexWidth is the narrowest a flex column gets
+const minFlexWidth = 8
+
+// Row is a table row; ID identifies the record it shows, so the cursor can
+// follow it when the rows are reloaded in a different order
+type Row struct {
+	ID    string
+	Cells []string
+}
+
+// columnGap separates columns
+const columnGap = "  "
+
+var (
+	headerStyle   = lipgloss.NewStyle().Bold(true)
+	selectedStyle = lipgloss.NewStyle().Bold(true)
+	emptyStyle    = lipgloss.NewStyle()
+)
+
+func init() {
+	theme.OnChange(func(t theme.Theme) {
+		headerStyle = headerStyle.Foreground(t.Primary)
+		selectedStyle = selectedStyle.Foreground(t.Accent)
+		emptyStyle = emptyStyle.Foreground(t.Muted)
+	})
+}
+
+// KeyMap holds the keys that move the cursor
+type KeyMap struct {
+	Up       key.Binding
+	Down     key.Binding
+	PageUp   key.Binding
+	PageDown key.Binding
+	Top      key.Binding
+	Bottom   key.Binding
+}
+
+// DefaultKeyMap returns the arrow and paging keys, along with vim's j, k, g and G
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Up:       key.NewBinding(key.WithKeys("up", "k")),
+		Down:     key.NewBinding(key.WithKeys("down", "j")),
+		PageUp:   key.NewBinding(key.WithKeys("pgup", "ctrl+u")),
+		PageDown: key.NewBinding(key.WithKeys("pgdown", "ctrl+d")),
+		Top:      key.NewBinding(key.WithKeys("home", "g")),
+		Bottom:   key.NewBinding(key.WithKeys("end", "G")),
+	}
+}
+
+// Model holds the rows of a table and which of them is selected
+type Model struct {
+	columns []Column
+
+	// rows are the loaded rows: all of them, or a window onto source.
+	// start is the position of rows[0] among all rows, and total the number of rows.
+	// atStart and atEnd report whether the window reaches the first and last row.
+	rows    []Row
+	source  Source
+	start   int
+	total   int
+	atStart bool
+	atEnd   bool
+
+	// cursor is the index in rows of the selected row; offset is the index of the first row shown
+	cursor int
+	offset int
+	height int
+	// width is the most cells a line may take, or 0 for no limit
+	width int
+
+	// sortColumn is the index of the column the rows are sorted by, or -1 if none
+	sortColumn int
+	sortDesc   bool
+
+	// Empty is shown in place of the rows when there are none
+	Empty string
+	// KeyMap is the keys that move the cursor
+	KeyMap KeyMap
+}
+
+// New creates an empty table with the given columns
+func New(columns []Column) Model {
+	return Model{
+		columns:    columns,
+		height:     DefaultHeight,
+		atStart:    true,
+		atEnd:      true,
+		sortColumn: -1,
+		Empty:      "Nothing to show",
+		KeyMap:     DefaultKeyMap(),
+	}
+}
+
+// SetRows replaces the rows with all the rows of the table,
+// keeping the cursor on the same record if it is still there
+func (m *Model) SetRows(rows []Row) {
+	selected := m.SelectedID()
+	m.source = nil
+	m.rows = rows
+	m.start, m.total = 0, len(rows)
+	m.atStart, m.atEnd = true, true
+	if selected == "" || !m.SelectID(selected) {
+		m.setCursor(m.cursor)
+	}
+}
+
+// SetSource makes the table page through source and loads the rows around the selected one
+func (m *Model) SetSource(source Source) error {
+	m.source = source
+	return m.Reload()
+}
+
+// Reload reloads the rows from the source, keeping the cursor on the same record.
+// If that record is gone, the cursor moves to the row that took its place.
+func (m *Model) Reload() error {
+	if m.source == nil {
+		return nil
+	}
+
+	total, err := m.source.Count()
+	if err != nil {
+		return err
+	}
+
+	selected := m.SelectedID()
+	position := m.start + m.cursor
+	screenRow := m.cursor - m.offset
+	size := m.pageSize()
+
+	var before []Row
+	if selected != "" {
+		if before, err = m.source.Page(selected, true, size); err != nil {
+			return err
+		}
+	}
+	anchor := ""
+	if len(before) > 0 {
+		anchor = before[len(before)-1].ID
+	}
+	after, err := m.source.Page(anchor, false, 2*size)
+	if err != nil {
+		return err
+	}
+
+	m.rows = append(before, after...)
+	m.total = total
+	m.atStart = len(before) < size
+	m.atEnd = len(after) < 2*size
+
+	// The position of the window is only known for certain at either end;
+	// elsewhere it is where the selected record was
+	switch {
+	case m.atStart:
+		m.start = 0
+	case m.atEnd:
+		m.start = total - len(m.rows)
+	default:
+		m.start = max(min(position-len(before), total-len(m.rows)), 0)
+	}
+
+	m.cursor = len(before)
+	m.offset = m.cursor - screenRow
+	m.setCursor(m.cursor)
+	return nil
+}
+
+// Rows returns the loaded rows of the table
+func (m Model) Rows() []Row {
+	return m.rows
+}
+
+// Total returns how many rows the table has, loaded or not
+func (m Model) Total() int {
+	return m.total
+}
+
+// Height returns how many rows are shown below the header
+func (m Model) Height() int {
+	return m.height
+}
+
+// SetHeight sets how many rows are shown below the header
+func (m *Model) SetHeight(height int) {
+	m.height = max(height, 1)
+	m.setCursor(m.cursor)
+}
+
+// SetWidth sets the most cells a line of the table may take, not counting the cursor;
+// 0 lets every column take its full width
+func (m *Model) SetWidth(width int) {
+	m.width = max(width, 0)
+}
+
+// SetSort marks the column the rows are sorted by, shown with an arrow in the header
+func (m *Model) SetSort(column int, desc bool) {
+	m.sortColumn = column
+	m.sortDesc = desc
+}
+
+// Cursor returns the position of the selected row among all rows
+func (m Model) Cursor() int {
+	return m.start + m.cursor
+}
+
+// SelectedID returns the ID of the selected row, or "" if the table is empty
+func (m Model) SelectedID() string {
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return ""
+	}
+	return m.rows[m.cursor].ID
+}
+
+// SelectID moves the cursor to the row with the given ID, reporting whether it is loaded
+func (m *Model) SelectID(id string) bool {
+	for i, r := range m.rows {
+		if r.ID == id {
+			m.setCursor(i)
+			return true
+		}
+	}
+	return false
+}
+
+// Update moves the cursor for the navigation keys and reports whether msg was one of them,
+// along with any error loading more rows from the source
+func (m *Model) Update(msg tea.Msg) (bool, error) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return false, nil
+	}
+
+	var err error
+	switch {
+	case key.Matches(keyMsg, m.KeyMap.Up):
+		err = m.moveTo(m.cursor - 1)
+	case key.Matches(keyMsg, m.KeyMap.Down):
+		err = m.moveTo(m.cursor + 1)
+	case key.Matches(keyMsg, m.KeyMap.PageUp):
+		err = m.moveTo(m.cursor - m.height)
+	case key.Matches(keyMsg, m.KeyMap.PageDown):
+		err = m.moveTo(m.cursor + m.height)
+	case key.Matches(keyMsg, m.KeyMap.Top):
+		err = m.jump(false)
+	case key.Matches(keyMsg, m.KeyMap.Bottom):
+		err = m.jump(true)
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// moveTo moves the cursor to index in rows, loading rows from the source past either
+// end of the window, and the next page once the cursor gets close to an end
+func (m *Model) moveTo(index int) error {
+	for index >= len(m.rows) && !m.atEnd {
+		if loaded, err := m.loadAfter(); err != nil || loaded == 0 {
+			return err
+		}
+	}
+	for index < 0 && !m.atStart {
+		loaded, err := m.loadBefore()
+		if err != nil || loaded == 0 {
+			return err
+		}
+		index += loaded
+	}
+	m.setCursor(index)
+
+	if m.cursor+m.height >= len(m.rows) && !m.atEnd {
+		if _, err := m.loadAfter(); err != nil {
+			return err
+		}
+	}
+	if m.cursor < m.height && !m.atStart {
+		if _, err := m.loadBefore(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jump moves the cursor to the first row, or the last if toEnd is set,
+// loading that end of the table if it isn't loaded
+func (m *Model) jump(toEnd bool) error {
+	loaded := m.atStart
+	if toEnd {
+		loaded = m.atEnd
+	}
+	if !loaded {
+		rows, err := m.source.Page("", toEnd, m.pageSize())
+		if err != nil {
+			return err
+		}
+		m.rows = rows
+		m.atStart, m.atEnd = !toEnd || len(rows) < m.pageSize(), toEnd || len(rows) < m.pageSize()
+		m.start = 0
+		if toEnd {
+			m.start = max(m.total-len(rows), 0)
+		}
+		m.offset = 0
+	}
+
+	if toEnd {
+		m.setCursor(len(m.rows) - 1)
+	} else {
+		m.setCursor(0)
+	}
+	return nil
+}
+
+// loadAfter appends the page following the window, dropping rows from the
+// start of the window if it gets too big, and returns how many rows it loaded
+func (m *Model) loadAfter() (int, error) {
+	after := ""
+	if len(m.rows) > 0 {
+		after = m.rows[len(m.rows)-1].ID
+	}
+	page, err := m.source.Page(after, false, m.pageSize())
+	if err != nil {
+		return 0, err
+	}
+	m.atEnd = len(page) < m.pageSize()
+	m.rows = append(m.rows, page...)
+	if m.atEnd {
+		m.start = max(m.total-len(m.rows), 0)
+	}
+
+	if drop := len(m.rows) - m.maxRows(); drop > 0 {
+		m.rows = slices.Clone(m.rows[drop:])
+		m.start += drop
+		m.cursor -= drop
+		m.offset -= drop
+		m.atStart = false
+	}
+	return len(page), nil
+}
+
+// loadBefore prepends the page preceding the window, dropping rows from the
+// end of the window if it gets too big, and returns how many rows it loaded
+func (m *Model) loadBefore() (int, error) {
+	before := ""
+	if len(m.rows) > 0 {
+		before = m.rows[0].ID
+	}
+	page, err := m.source.Page(before, true, m.pageSize())
+	if err != nil {
+		return 0, err
+	}
+	m.atStart = len(page) < m.pageSize()
+	m.rows = append(page, m.rows...)
+	m.start = max(m.start-len(page), 0)
+	if m.atStart {
+		m.start = 0
+	}
+	m.cursor += len(page)
+	m.offset += len(page)
+
+	if drop := len(m.rows) - m.maxRows(); drop > 0 {
+		m.rows = m.rows[:len(m.rows)-drop]
+		m.atEnd = false
+	}
+	return len(page), nil
+}
+
+// pageSize is how many rows are loaded from the source at a time
+func (m Model) pageSize() int {
+	return max(minPageSize, 2*m.height)
+}
+
+// maxRows is how many rows the table keeps loaded from the source
+func (m Model) maxRows() int {
+	return 3 * m.pageSize()
+}
+
+// setCursor moves the cursor to index, clamped to the rows, and scrolls it into view
+func (m *Model) setCursor(index int) {
+	m.cursor = max(min(index, len(m.rows)-1), 0)
+
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.height {
+		m.offset = m.cursor - m.height + 1
+	}
+	// Don't leave empty space below the last row when rows were removed
+	m.offset = max(min(m.offset, len(m.rows)-m.height), 0)
+}
+
+// View renders the header followed by the rows that fit in the table's height
+func (m Model) View() string {
+	var b strings.Builder
+
+	columns, widths := m.layout()
+
+	titles := make([]string, len(m.columns))
+	for i, col := range m.columns {
+		title := col.Title
+		if i == m.sortColumn {
+			if m.sortDesc {
+				title += " ▼"
+			} else {
+				title += " ▲"
+			}
+		}
+		titles[i] = title
+	}
+	// The header is indented like the rows, which leave room for the cursor
+	b.WriteString(headerStyle.Render("  " + m.renderLine(titles, columns, widths)))
+
+	if len(m.rows) == 0 {
+		b.WriteString("\n" + emptyStyle.Render(m.Empty))
+		return b.String()
+	}
+
+	end := min(m.offset+m.height, len(m.rows))
+	for i := m.offset; i < end; i++ {
+		line := m.renderLine(m.rows[i].Cells, columns, widths)
+		b.WriteString("\n")
+		if i == m.cursor {
+			b.WriteString(selectedStyle.Render("> " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+	}
+
+	if m.total > m.height {
+		b.WriteString("\n" + emptyStyle.Render(positionLabel(m.start+m.offset, m.start+end, m.total)))
+	}
+	return b.String()
+}
+
+// renderLine lays out one line of cells in the given columns, at the given widths
+func (m Model) renderLine(cells []string, columns, widths []int) string {
+	parts := make([]string, len(columns))
+	for i, c := range columns {
+		var cell string
+		if c < len(cells) {
+			cell = cells[c]
+		}
+		parts[i] = fitCell(cell, widths[i], m.columns[c].AlignRight)
+	}
+	return strings.Join(parts, columnGap)
+}
+
+// layout returns the indexes of the columns that fit in the table's width and how wide
+// each of them is: flex columns shrink first, then optional columns are hidden
+func (m Model) layout() (columns, widths []int) {
+	for i := range m.columns {
+		columns = append(columns, i)
+	}
+
+	for {
+		widths = make([]int, len(columns))
+		total := len(columnGap) * (len(columns) - 1)
+		for i, c := range columns {
+			widths[i] = m.columns[c].Width
+			total += widths[i]
+		}
+		if m.width == 0 {
+			return columns, widths
+		}
+
+		// Shrink the flex columns evenly, each by at most what it can spare
+		for excess := total - m.width; excess > 0; {
+			shrunk := false
+			for i, c := range columns {
+				if excess > 0 && m.columns[c].Flex && widths[i] > minFlexWidth {
+					widths[i]--
+					excess--
+					shrunk = true
+				}
+			}
+			if !shrunk {
+				break
+			}
+			total = m.width + excess
+		}
+		if total <= m.width {
+			return columns, widths
+		}
+
+		hide := -1
+		for i, c := range columns {
+			if m.columns[c].Optional {
+				hide = i
+			}
+		}
+		if hide < 0 {
+			// Nothing left to hide; lines will be wider than the table
+			return columns, widths
+		}
+		columns = slices.Delete(columns, hide, hide+1)
+	}
+}
+
+// positionLabel describes which rows are shown, e.g. "1–15 of 40"
+func positionLabel(start, end, total int) string {
+	return fmt.Sprintf("%d–%d of %d", start+1, end, total)
+}
+
+// fitCell truncates or pads s to exactly width cells
+func fitCell(s string, width int, alignRight bool) string {
+	s = utils.TruncateWithTail(s, width, "…")
+	if alignRight {
+		return utils.PadLeft(s, width)
+	}
+	return utils.PadRight(s, width)
+}
@@ -0,0 +1,359 @@
+package table
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func testRows(n int) []Row {
+	rows := make([]Row, n)
+	for i := range rows {
+		id := fmt.Sprintf("row%d", i)
+		rows[i] = Row{ID: id, Cells: []string{id, fmt.Sprintf("%d.00", i)}}
+	}
+	return rows
+}
+
+func newTestTable(rows int, height int) Model {
+	m := New([]Column{{Title: "Name", Width: 8}, {Title: "Total", Width: 8, AlignRight: true}})
+	m.SetHeight(height)
+	m.SetRows(testRows(rows))
+	return m
+}
+
+func keyPress(s string) tea.KeyMsg {
+	switch s {
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "end":
+		return tea.KeyMsg{Type: tea.KeyEnd}
+	case "home":
+		return tea.KeyMsg{Type: tea.KeyHome}
+	case "pgdown":
+		return tea.KeyMsg{Type: tea.KeyPgDown}
+	case "pgup":
+		return tea.KeyMsg{Type: tea.KeyPgUp}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestCustomKeyMap(t *testing.T) {
+	m := newTestTable(20, 5)
+	m.KeyMap.Down = key.NewBinding(key.WithKeys("ctrl+n"))
+
+	if handled, _ := m.Update(keyPress("j")); handled {
+		t.Error("expected j not to move the cursor once down is rebound")
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	if m.Cursor() != 1 {
+		t.Errorf("expected ctrl+n to move the cursor down, got cursor %d", m.Cursor())
+	}
+}
+
+func TestHeaderStaysWhileScrolling(t *testing.T) {
+	m := newTestTable(10, 3)
+
+	m.Update(keyPress("end"))
+	view := m.View()
+
+	if m.SelectedID() != "row9" {
+		t.Errorf("expected row9 selected, got %q", m.SelectedID())
+	}
+	if !strings.Contains(strings.Split(view, "\n")[0], "Name") {
+		t.Errorf("expected the header on the first line, got %q", view)
+	}
+	if strings.Contains(view, "row6") || !strings.Contains(view, "row7") {
+		t.Errorf("expected only the last 3 rows, got %q", view)
+	}
+	if !strings.Contains(view, "8–10 of 10") {
+		t.Errorf("expected the scroll position, got %q", view)
+	}
+}
+
+func TestSetRowsKeepsSelectedRecord(t *testing.T) {
+	m := newTestTable(5, 10)
+	m.Update(keyPress("down"))
+	m.Update(keyPress("down"))
+
+	// Reversed, e.g. after sorting the other way
+	rows := testRows(5)
+	for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+		rows[i], rows[j] = rows[j], rows[i]
+	}
+	m.SetRows(rows)
+
+	if m.SelectedID() != "row2" || m.Cursor() != 2 {
+		t.Errorf("expected row2 still selected at index 2, got %q at %d", m.SelectedID(), m.Cursor())
+	}
+
+	// The selected row was removed; the cursor stays as close as it can
+	m.SetRows(testRows(5))
+	m.SelectID("row4")
+	m.SetRows(testRows(3))
+	if m.SelectedID() != "row2" {
+		t.Errorf("expected the last remaining row selected, got %q", m.SelectedID())
+	}
+}
+
+func TestSortIndicatorAndAlignment(t *testing.T) {
+	m := newTestTable(1, 5)
+	m.SetSort(1, true)
+
+	lines := strings.Split(m.View(), "\n")
+	if !strings.Contains(lines[0], "Total ▼") {
+		t.Errorf("expected a descending arrow on Total, got %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "    0.00") {
+		t.Errorf("expected the total right-aligned, got %q", lines[1])
+	}
+}
+
+func TestLayoutFitsWidth(t *testing.T) {
+	m := New([]Column{
+		{Title: "Number", Width: 10},
+		{Title: "Client", Width: 20, Flex: true},
+		{Title: "Provider", Width: 20, Flex: true, Optional: true},
+		{Title: "Total", Width: 10, AlignRight: true},
+	})
+	m.SetRows([]Row{{ID: "1", Cells: []string{"INV-1", "Acme Corporation", "Initech", "10.00"}}})
+
+	// Wide enough for every column in full
+	m.SetWidth(80)
+	if columns, widths := m.layout(); !slices.Equal(columns, []int{0, 1, 2, 3}) || !slices.Equal(widths, []int{10, 20, 20, 10}) {
+		t.Errorf("expected every column in full, got %v %v", columns, widths)
+	}
+
+	// Flexible columns give way first; the gaps between columns count towards the width
+	m.SetWidth(56)
+	if columns, widths := m.layout(); !slices.Equal(columns, []int{0, 1, 2, 3}) || !slices.Equal(widths, []int{10, 15, 15, 10}) {
+		t.Errorf("expected the flexible columns shrunk, got %v %v", columns, widths)
+	}
+
+	// Then optional columns are hidden
+	m.SetWidth(40)
+	columns, _ := m.layout()
+	if !slices.Equal(columns, []int{0, 1, 3}) {
+		t.Errorf("expected the provider column hidden, got %v", columns)
+	}
+	for _, line := range strings.Split(m.View(), "\n") {
+		if lipgloss.Width(line) > 40+2 {
+			t.Errorf("expected lines to fit the width, got %q", line)
+		}
+	}
+}
+
+func TestFitCell(t *testing.T) {
+	tests := []struct {
+		in         string
+		width      int
+		alignRight bool
+		want       string
+	}{
+		{"abc", 5, false, "abc  "},
+		{"abc", 5, true, "  abc"},
+		{"abcdef", 4, false, "abc…"},
+		{"", 2, false, "  "},
+		{"Zoë", 4, true, " Zoë"},
+		{"株式会社テスト", 6, false, "株式… "},
+		{"👩‍💻👩‍💻 Studio", 5, false, "👩‍💻👩‍💻…"},
+	}
+	for _, tt := range tests {
+		if got := fitCell(tt.in, tt.width, tt.alignRight); got != tt.want {
+			t.Errorf("fitCell(%q, %d, %v) = %q, want %q", tt.in, tt.width, tt.alignRight, got, tt.want)
+		}
+	}
+}
+
+func TestEmptyTable(t *testing.T) {
+	m := New([]Column{{Title: "Name", Width: 8}})
+	m.Empty = "No invoices"
+
+	if m.SelectedID() != "" {
+		t.Errorf("expected no selection, got %q", m.SelectedID())
+	}
+	if handled, _ := m.Update(keyPress("down")); !handled {
+		t.Error("expected navigation keys to be handled on an empty table")
+	}
+	if !strings.Contains(m.View(), "No invoices") {
+		t.Errorf("expected the empty message, got %q", m.View())
+	}
+}
+
+// sliceSource pages through rows held in memory, counting the pages loaded
+type sliceSource struct {
+	rows  []Row
+	pages int
+}
+
+func (s *sliceSource) Count() (int, error) {
+	return len(s.rows), nil
+}
+
+func (s *sliceSource) Page(id string, backward bool, limit int) ([]Row, error) {
+	s.pages++
+	// Like a keyset query on a deleted row, an unknown ID matches nothing
+	if id != "" && s.index(id) < 0 {
+		return nil, nil
+	}
+	if !backward {
+		start := 0
+		if id != "" {
+			start = s.index(id) + 1
+		}
+		return slices.Clone(s.rows[start:min(start+limit, len(s.rows))]), nil
+	}
+	end := len(s.rows)
+	if id != "" {
+		end = s.index(id)
+	}
+	return slices.Clone(s.rows[max(end-limit, 0):end]), nil
+}
+
+func (s *sliceSource) index(id string) int {
+	return slices.IndexFunc(s.rows, func(r Row) bool { return r.ID == id })
+}
+
+func newSourceTable(t testing.TB, rows int) (Model, *sliceSource) {
+	source := &sliceSource{rows: testRows(rows)}
+	m := New([]Column{{Title: "Name", Width: 8}, {Title: "Total", Width: 8, AlignRight: true}})
+	m.SetHeight(10)
+	if err := m.SetSource(source); err != nil {
+		t.Fatalf("SetSource failed: %v", err)
+	}
+	return m, source
+}
+
+func TestSourceKeepsWindowWhileScrolling(t *testing.T) {
+	m, _ := newSourceTable(t, 5000)
+
+	for range 300 {
+		if _, err := m.Update(keyPress("pgdown")); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if len(m.Rows()) > m.maxRows() {
+			t.Fatalf("expected at most %d rows loaded, got %d", m.maxRows(), len(m.Rows()))
+		}
+	}
+	if m.SelectedID() != "row3000" || m.Cursor() != 3000 {
+		t.Errorf("expected row3000 selected at 3000, got %q at %d", m.SelectedID(), m.Cursor())
+	}
+	if view := m.View(); !strings.Contains(view, "row3000") || !strings.Contains(view, "of 5000") {
+		t.Errorf("expected the selected row and position, got %q", view)
+	}
+
+	// Scrolling back loads the earlier rows again
+	for range 300 {
+		m.Update(keyPress("pgup"))
+	}
+	if m.SelectedID() != "row0" || m.Cursor() != 0 {
+		t.Errorf("expected row0 selected at 0, got %q at %d", m.SelectedID(), m.Cursor())
+	}
+}
+
+func TestSourceJumpsToEnds(t *testing.T) {
+	m, source := newSourceTable(t, 100000)
+
+	source.pages = 0
+	m.Update(keyPress("end"))
+	if m.SelectedID() != "row99999" || m.Cursor() != 99999 {
+		t.Errorf("expected the last row selected, got %q at %d", m.SelectedID(), m.Cursor())
+	}
+	if source.pages > 1 {
+		t.Errorf("expected the end of the table in one page, loaded %d", source.pages)
+	}
+
+	m.Update(keyPress("up"))
+	m.Update(keyPress("home"))
+	if m.SelectedID() != "row0" {
+		t.Errorf("expected the first row selected, got %q", m.SelectedID())
+	}
+}
+
+func TestSourceReloadKeepsSelection(t *testing.T) {
+	m, source := newSourceTable(t, 1000)
+	for range 50 {
+		m.Update(keyPress("down"))
+	}
+
+	// Reversed, e.g. after sorting the other way
+	slices.Reverse(source.rows)
+	if err := m.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if m.SelectedID() != "row50" || m.Cursor() != 949 {
+		t.Errorf("expected row50 selected at 949, got %q at %d", m.SelectedID(), m.Cursor())
+	}
+
+	// The selected row is removed; the table starts over
+	source.rows = slices.DeleteFunc(source.rows, func(r Row) bool { return r.ID == "row50" })
+	m.Reload()
+	if m.SelectedID() == "row50" || m.Total() != 999 {
+		t.Errorf("expected another row selected of 999, got %q of %d", m.SelectedID(), m.Total())
+	}
+}
+
+// Budgets for one step scrolling through a large source, i.e. moving the cursor down a row
+// and rendering the table, including the pages loaded along the way. They hold however many
+// rows the source has, since only a window of them is loaded and rendered.
+const (
+	scrollStepTimeBudget  = time.Millisecond
+	scrollStepAllocBudget = 100
+	scrollSourceRows      = 100_000
+)
+
+// TestScrollLargeSourceBudget holds scrolling and rendering to the scroll step budgets,
+// so that a regression fails the tests rather than only showing in benchmarks
+func TestScrollLargeSourceBudget(t *testing.T) {
+	m, _ := newSourceTable(t, scrollSourceRows)
+	step := func() {
+		if _, err := m.Update(keyPress("down")); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		_ = m.View()
+	}
+
+	// Enough steps to load several pages
+	const steps = 1000
+	if allocs := testing.AllocsPerRun(steps, step); allocs > scrollStepAllocBudget {
+		t.Errorf("allocated %.0f times per step, budget is %d", allocs, scrollStepAllocBudget)
+	}
+
+	start := time.Now()
+	for range steps {
+		step()
+	}
+	if perStep := time.Since(start) / steps; perStep > scrollStepTimeBudget {
+		t.Errorf("took %v per step, budget is %v", perStep, scrollStepTimeBudget)
+	}
+	if len(m.Rows()) > m.maxRows() {
+		t.Errorf("expected at most %d rows loaded, got %d", m.maxRows(), len(m.Rows()))
+	}
+}
+
+// BenchmarkScrollLargeSource scrolls through scrollSourceRows rows, which should render no more
+// rows and hold no more in memory than a short table does, see TestScrollLargeSourceBudget
+func BenchmarkScrollLargeSource(b *testing.B) {
+	m, _ := newSourceTable(b, scrollSourceRows)
+	b.ReportAllocs()
+
+	for b.Loop() {
+		if _, err := m.Update(keyPress("down")); err != nil {
+			b.Fatalf("Update failed: %v", err)
+		}
+		_ = m.View()
+		if m.SelectedID() == "row99999" {
+			m.Update(keyPress("home"))
+		}
+	}
+
+	if len(m.Rows()) > m.maxRows() {
+		b.Fatalf("expected at most %d rows loaded, got %d", m.maxRows(), len(m.Rows()))
+	}
+}
@@ -4,49 +4,46 @@ import (
 	"errors"
 
 	"github.com/GVPproj/termsheet/models"
+	"github.com/GVPproj/termsheet/validation"
 	"github.com/charmbracelet/huh"
 )
 
 // NewClientForm creates a new form for client input
 // Takes pointers to string variables that will be bound to form fields
-func NewClientForm(name, address, email, phone *string) *huh.Form {
-	return huh.NewForm(
-		huh.NewGroup(
-			huh.NewInput().
-				Title("Client Name").
-				Value(name).
-				Validate(func(s string) error {
-					if s == "" {
-						return errors.New("client name is required")
-					}
-					return nil
-				}),
-			huh.NewInput().
-				Title("Address").
-				Value(address),
-			huh.NewInput().
-				Title("Email").
-				Value(email),
-			huh.NewInput().
-				Title("Phone").
-				Value(phone),
-		),
+// Additional groups (e.g. NewAddressGroup) are shown as further pages of the form
+func NewClientForm(name, email, phone *string, groups ...*huh.Group) *huh.Form {
+	details := huh.NewGroup(
+		huh.NewInput().
+			Title("Client Name").
+			Value(name).
+			Validate(func(s string) error {
+				if s == "" {
+					return errors.New("client name is required")
+				}
+				return nil
+			}),
+		huh.NewInput().
+			Title("Email").
+			Description("General email; invoices go to the billing contact if one is set").
+			Value(email).
+			Validate(validation.Email),
+		huh.NewInput().
+			Title("Phone").
+			Description("International format preferred, e.g. +44 20 7946 0958").
+			Value(phone).
+			Validate(validatePhone),
 	)
+
+	return huh.NewForm(append([]*huh.Group{details}, groups...)...)
 }
 
 // NewClientFormWithData creates a form pre-populated with existing client data
 // Useful for editing an existing client
-func NewClientFormWithData(client models.Entity, name, address, email, phone *string) *huh.Form {
+func NewClientFormWithData(client models.Entity, name, email, phone *string, groups ...*huh.Group) *huh.Form {
 	// Pre-populate the bound variables with existing data
 	*name = client.Name
 
 	// Convert *string to string, empty if nil
-	if client.Address != nil {
-		*address = *client.Address
-	} else {
-		*address = ""
-	}
-
 	if client.Email != nil {
 		*email = *client.Email
 	} else {
@@ -59,5 +56,77 @@ func NewClientFormWithData(client models.Entity, name, address, email, phone *st
 		*phone = ""
 	}
 
-	return NewClientForm(name, address, email, phone)
+	return NewClientForm(name, email, phone, groups...)
+}
+
+// NewAddressGroup creates a form page for a structured address
+func NewAddressGroup(title string, address *models.Address) *huh.Group {
+	return huh.NewGroup(
+		huh.NewInput().
+			Title(title).
+			Description("Street address").
+			Value(&address.Line1),
+		huh.NewInput().
+			Title("Address Line 2").
+			Value(&address.Line2),
+		huh.NewInput().
+			Title("City").
+			Value(&address.City),
+		huh.NewInput().
+			Title("State / Region").
+			Value(&address.Region),
+		huh.NewInput().
+			Title("Country").
+			Value(&address.Country),
+		huh.NewInput().
+			Title("Postal Code").
+			Value(&address.PostalCode).
+			Validate(validatePostalCode(&address.Country)),
+	)
+}
+
+// NewShippingGroups creates the form pages for a separate shipping address.
+// The address page is skipped unless separate is confirmed.
+func NewShippingGroups(separate *bool, address *models.Address) []*huh.Group {
+	confirm := huh.NewGroup(
+		huh.NewConfirm().
+			Title("Ship to a different address than billing?").
+			Value(separate),
+	)
+
+	shipping := NewAddressGroup("Shipping Address", address).
+		WithHideFunc(func() bool {
+			return !*separate
+		})
+
+	return []*huh.Group{confirm, shipping}
+}
+
+// NewContactForm creates a form for a single client contact
+func NewContactForm(contact *models.Contact) *huh.Form {
+	return huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Contact Name").
+				Value(&contact.Name).
+				Validate(func(s string) error {
+					if s == "" {
+						return errors.New("contact name is required")
+					}
+					return nil
+				}),
+			huh.NewInput().
+				Title("Email").
+				Value(&contact.Email).
+				Validate(validation.Email),
+			huh.NewInput().
+				Title("Phone").
+				Value(&contact.Phone).
+				Validate(validatePhone),
+			huh.NewConfirm().
+				Title("Billing contact?").
+				Description("Invoices are sent to the billing contact's email").
+				Value(&contact.Billing),
+		),
+	)
 }
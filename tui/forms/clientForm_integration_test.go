@@ -11,19 +11,17 @@ import (
 func TestNewClientFormWithDataIntegration(t *testing.T) {
 	// This is a unit test, so we'll use mock data instead of requiring DB setup
 	// Create a client with all fields populated
-	addr := "321 Client Ave"
 	em := "contact@client.com"
 	ph := "555-8888"
 
 	client := models.Entity{
-		Name:    "Existing Client",
-		Address: &addr,
-		Email:   &em,
-		Phone:   &ph,
+		Name:  "Existing Client",
+		Email: &em,
+		Phone: &ph,
 	}
 
-	var name, address, email, phone string
-	form := forms.NewClientFormWithData(client, &name, &address, &email, &phone)
+	var name, email, phone string
+	form := forms.NewClientFormWithData(client, &name, &email, &phone)
 
 	if form == nil {
 		t.Fatal("expected non-nil form")
@@ -33,9 +31,6 @@ func TestNewClientFormWithDataIntegration(t *testing.T) {
 	if name != "Existing Client" {
 		t.Errorf("expected name %q, got %q", "Existing Client", name)
 	}
-	if address != "321 Client Ave" {
-		t.Errorf("expected address %q, got %q", "321 Client Ave", address)
-	}
 	if email != "contact@client.com" {
 		t.Errorf("expected email %q, got %q", "contact@client.com", email)
 	}
@@ -47,14 +42,13 @@ func TestNewClientFormWithDataIntegration(t *testing.T) {
 // TestNewClientFormWithDataNilFieldsIntegration tests the form with a minimal client
 func TestNewClientFormWithDataNilFieldsIntegration(t *testing.T) {
 	client := models.Entity{
-		Name:    "Minimal Client",
-		Address: nil,
-		Email:   nil,
-		Phone:   nil,
+		Name:  "Minimal Client",
+		Email: nil,
+		Phone: nil,
 	}
 
-	var name, address, email, phone string
-	form := forms.NewClientFormWithData(client, &name, &address, &email, &phone)
+	var name, email, phone string
+	form := forms.NewClientFormWithData(client, &name, &email, &phone)
 
 	if form == nil {
 		t.Fatal("expected non-nil form")
@@ -64,9 +58,6 @@ func TestNewClientFormWithDataNilFieldsIntegration(t *testing.T) {
 	if name != "Minimal Client" {
 		t.Errorf("expected name %q, got %q", "Minimal Client", name)
 	}
-	if address != "" {
-		t.Errorf("expected empty address, got %q", address)
-	}
 	if email != "" {
 		t.Errorf("expected empty email, got %q", email)
 	}
@@ -77,17 +68,16 @@ func TestNewClientFormWithDataNilFieldsIntegration(t *testing.T) {
 
 // TestNewClientFormWithDataMixedFields tests the form with some nil and some populated fields
 func TestNewClientFormWithDataMixedFields(t *testing.T) {
-	addr := "999 Mixed St"
+	em := "partial@client.com"
 
 	client := models.Entity{
-		Name:    "Partial Client",
-		Address: &addr,
-		Email:   nil,
-		Phone:   nil,
+		Name:  "Partial Client",
+		Email: &em,
+		Phone: nil,
 	}
 
-	var name, address, email, phone string
-	form := forms.NewClientFormWithData(client, &name, &address, &email, &phone)
+	var name, email, phone string
+	form := forms.NewClientFormWithData(client, &name, &email, &phone)
 
 	if form == nil {
 		t.Fatal("expected non-nil form")
@@ -97,11 +87,8 @@ func TestNewClientFormWithDataMixedFields(t *testing.T) {
 	if name != "Partial Client" {
 		t.Errorf("expected name %q, got %q", "Partial Client", name)
 	}
-	if address != "999 Mixed St" {
-		t.Errorf("expected address %q, got %q", "999 Mixed St", address)
-	}
-	if email != "" {
-		t.Errorf("expected empty email, got %q", email)
+	if email != "partial@client.com" {
+		t.Errorf("expected email %q, got %q", "partial@client.com", email)
 	}
 	if phone != "" {
 		t.Errorf("expected empty phone, got %q", phone)
@@ -5,9 +5,9 @@ import (
 )
 
 func TestNewClientForm(t *testing.T) {
-	var name, address, email, phone string
+	var name, email, phone string
 
-	form := NewClientForm(&name, &address, &email, &phone)
+	form := NewClientForm(&name, &email, &phone)
 
 	if form == nil {
 		t.Fatal("expected non-nil form")
@@ -15,7 +15,6 @@ func TestNewClientForm(t *testing.T) {
 
 	// Simulate user input
 	name = "Test Client"
-	address = "789 Oak St"
 	email = "client@example.com"
 	phone = "555-0200"
 
@@ -27,22 +26,20 @@ func TestNewClientForm(t *testing.T) {
 
 func TestNewClientFormWithData(t *testing.T) {
 	// Create mock client data without importing models to avoid cycle
-	addr := "321 Client Ave"
 	em := "contact@client.com"
 	ph := "555-8888"
 
 	// We can't import models.Client due to import cycle
 	// So we'll test the inline pointer conversion instead
-	var name, address, email, phone string
+	var name, email, phone string
 
 	// Manually simulate what NewClientFormWithData does
 	name = "Existing Client"
 	// Convert *string to string, empty if nil
-	address = addr
 	email = em
 	phone = ph
 
-	form := NewClientForm(&name, &address, &email, &phone)
+	form := NewClientForm(&name, &email, &phone)
 
 	if form == nil {
 		t.Fatal("expected non-nil form")
@@ -52,9 +49,6 @@ func TestNewClientFormWithData(t *testing.T) {
 	if name != "Existing Client" {
 		t.Errorf("expected name %q, got %q", "Existing Client", name)
 	}
-	if address != "321 Client Ave" {
-		t.Errorf("expected address %q, got %q", "321 Client Ave", address)
-	}
 	if email != "contact@client.com" {
 		t.Errorf("expected email %q, got %q", "contact@client.com", email)
 	}
@@ -64,15 +58,14 @@ func TestNewClientFormWithData(t *testing.T) {
 }
 
 func TestNewClientFormWithDataNilFields(t *testing.T) {
-	var name, address, email, phone string
+	var name, email, phone string
 
 	// Simulate nil fields (empty strings since we can't test with actual nil pointers here)
 	name = "Minimal Client"
-	address = ""
 	email = ""
 	phone = ""
 
-	form := NewClientForm(&name, &address, &email, &phone)
+	form := NewClientForm(&name, &email, &phone)
 
 	if form == nil {
 		t.Fatal("expected non-nil form")
@@ -82,9 +75,6 @@ func TestNewClientFormWithDataNilFields(t *testing.T) {
 	if name != "Minimal Client" {
 		t.Errorf("expected name %q, got %q", "Minimal Client", name)
 	}
-	if address != "" {
-		t.Errorf("expected empty address, got %q", address)
-	}
 	if email != "" {
 		t.Errorf("expected empty email, got %q", email)
 	}
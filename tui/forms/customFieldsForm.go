@@ -0,0 +1,96 @@
+package forms
+
+import (
+	"github.com/GVPproj/termsheet/models"
+	"github.com/GVPproj/termsheet/storage"
+	"github.com/charmbracelet/huh"
+)
+
+// CustomFieldInputs binds a record's custom field values to form inputs
+type CustomFieldInputs struct {
+	Fields []models.CustomField
+	// Values holds the input for Fields[i] at index i; inputs are bound to its elements
+	Values []string
+}
+
+// NewCustomFieldInputs prepares inputs for fields, pre-filled from values keyed by field key
+func NewCustomFieldInputs(fields []models.CustomField, values map[string]string) *CustomFieldInputs {
+	in := &CustomFieldInputs{
+		Fields: fields,
+		Values: make([]string, len(fields)),
+	}
+	for i, f := range fields {
+		in.Values[i] = values[f.Key]
+	}
+	return in
+}
+
+// LoadCustomFieldInputs prepares inputs for the custom fields of an entity type,
+// pre-filled with the values of entityID. Pass "" for a record that doesn't exist yet.
+func LoadCustomFieldInputs(entityType models.EntityType, entityID string) (*CustomFieldInputs, error) {
+	fields, err := storage.ListCustomFields(entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{}
+	if entityID != "" {
+		values, err = storage.GetCustomValues(entityType, entityID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return NewCustomFieldInputs(fields, values), nil
+}
+
+// Groups returns the form page for the custom fields, or no pages if none are defined
+func (in *CustomFieldInputs) Groups() []*huh.Group {
+	if in == nil || len(in.Fields) == 0 {
+		return nil
+	}
+
+	fields := make([]huh.Field, 0, len(in.Fields))
+	for i, f := range in.Fields {
+		fields = append(fields, newCustomFieldInput(f, &in.Values[i]))
+	}
+	return []*huh.Group{huh.NewGroup(fields...)}
+}
+
+// ValueMap returns the entered values keyed by field key
+func (in *CustomFieldInputs) ValueMap() map[string]string {
+	values := make(map[string]string)
+	if in == nil {
+		return values
+	}
+	for i, f := range in.Fields {
+		values[f.Key] = in.Values[i]
+	}
+	return values
+}
+
+// newCustomFieldInput creates the input matching a custom field's kind
+func newCustomFieldInput(f models.CustomField, value *string) huh.Field {
+	if f.Kind == models.FieldSelect {
+		options := []huh.Option[string]{huh.NewOption("(none)", "")}
+		for _, o := range f.Options {
+			options = append(options, huh.NewOption(o, o))
+		}
+		return huh.NewSelect[string]().
+			Title(f.Label).
+			Options(options...).
+			Value(value)
+	}
+
+	input := huh.NewInput().
+		Title(f.Label).
+		Value(value).
+		Validate(f.ValidateValue)
+	switch f.Kind {
+	case models.FieldDate:
+		input.Placeholder("YYYY-MM-DD")
+	case models.FieldNumber:
+		input.Placeholder("0")
+	}
+	return input
+}
@@ -0,0 +1,196 @@
+package forms
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GVPproj/termsheet/models"
+	"github.com/GVPproj/termsheet/storage"
+	"github.com/charmbracelet/huh"
+)
+
+// filterDateLayout is how dates are entered in the filter forms
+const filterDateLayout = "2006-01-02"
+
+// InvoiceFilterInputs binds a storage.InvoiceFilter to form inputs
+type InvoiceFilterInputs struct {
+	Search     string
+	Status     string
+	ProviderID string
+	ClientID   string
+	From       string
+	To         string
+	MinTotal   string
+	MaxTotal   string
+
+	// tags are kept as they are; they have their own filter form
+	tags []string
+}
+
+// NewInvoiceFilterInputs prepares inputs pre-filled from filter
+func NewInvoiceFilterInputs(filter storage.InvoiceFilter) *InvoiceFilterInputs {
+	in := &InvoiceFilterInputs{
+		Search:     filter.Search,
+		Status:     string(filter.Status),
+		ProviderID: filter.ProviderID,
+		ClientID:   filter.ClientID,
+		tags:       filter.Tags,
+	}
+	if !filter.From.IsZero() {
+		in.From = filter.From.Format(filterDateLayout)
+	}
+	if !filter.To.IsZero() {
+		in.To = filter.To.Format(filterDateLayout)
+	}
+	if filter.MinTotal != nil {
+		in.MinTotal = strconv.FormatFloat(*filter.MinTotal, 'f', -1, 64)
+	}
+	if filter.MaxTotal != nil {
+		in.MaxTotal = strconv.FormatFloat(*filter.MaxTotal, 'f', -1, 64)
+	}
+	return in
+}
+
+// Form creates the filter form; blank inputs leave that part of the filter unset
+func (in *InvoiceFilterInputs) Form() (*huh.Form, error) {
+	providers, err := storage.ListAllProviders()
+	if err != nil {
+		return nil, err
+	}
+	clients, err := storage.ListAllClients()
+	if err != nil {
+		return nil, err
+	}
+
+	statusOptions := []huh.Option[string]{huh.NewOption("Any status", "")}
+	for _, s := range models.InvoiceStatuses {
+		statusOptions = append(statusOptions, huh.NewOption(strings.ToUpper(string(s[:1]))+string(s[1:]), string(s)))
+	}
+
+	return huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Search").
+				Description("Invoice number, provider, client or item name").
+				Value(&in.Search),
+			huh.NewSelect[string]().
+				Title("Status").
+				Options(statusOptions...).
+				Value(&in.Status),
+			huh.NewSelect[string]().
+				Title("Provider").
+				Options(entityFilterOptions("Any provider", providers)...).
+				Value(&in.ProviderID),
+			huh.NewSelect[string]().
+				Title("Client").
+				Options(entityFilterOptions("Any client", clients)...).
+				Value(&in.ClientID),
+		),
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Created from").
+				Placeholder("YYYY-MM-DD").
+				Value(&in.From).
+				Validate(validateFilterDate),
+			huh.NewInput().
+				Title("Created until").
+				Placeholder("YYYY-MM-DD").
+				Value(&in.To).
+				Validate(validateFilterDate),
+			huh.NewInput().
+				Title("Minimum total").
+				Value(&in.MinTotal).
+				Validate(validateFilterAmount),
+			huh.NewInput().
+				Title("Maximum total").
+				Value(&in.MaxTotal).
+				Validate(validateFilterAmount),
+		),
+	), nil
+}
+
+// Filter returns the filter described by the inputs.
+// Inputs the form's validation would reject are ignored.
+func (in *InvoiceFilterInputs) Filter() storage.InvoiceFilter {
+	filter := storage.InvoiceFilter{
+		Search:     strings.TrimSpace(in.Search),
+		Status:     models.InvoiceStatus(in.Status),
+		ProviderID: in.ProviderID,
+		ClientID:   in.ClientID,
+		Tags:       in.tags,
+	}
+	filter.From, _ = parseFilterDate(in.From)
+	filter.To, _ = parseFilterDate(in.To)
+	filter.MinTotal, _ = parseFilterAmount(in.MinTotal)
+	filter.MaxTotal, _ = parseFilterAmount(in.MaxTotal)
+	return filter
+}
+
+// NewSearchFilterForm creates a form for the free-text search of the client and provider lists
+func NewSearchFilterForm(search *string) *huh.Form {
+	return huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Search").
+				Description("Name, email, phone or address; leave empty to list everything").
+				Value(search),
+		),
+	)
+}
+
+// entityFilterOptions lists entities as select options after an option matching any of them
+func entityFilterOptions(anyLabel string, entities []models.Entity) []huh.Option[string] {
+	options := []huh.Option[string]{huh.NewOption(anyLabel, "")}
+	for _, e := range entities {
+		label := e.Name
+		if e.Archived {
+			label += " [archived]"
+		}
+		options = append(options, huh.NewOption(label, e.ID))
+	}
+	return options
+}
+
+// parseFilterDate parses an optional date; blank input gives the zero time
+func parseFilterDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(filterDateLayout, s)
+}
+
+// parseFilterAmount parses an optional amount; blank input gives nil
+func parseFilterAmount(s string) (*float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// validateFilterDate reports whether s is blank or a YYYY-MM-DD date
+func validateFilterDate(s string) error {
+	if _, err := parseFilterDate(s); err != nil {
+		return errors.New("use YYYY-MM-DD, e.g. 2026-01-31")
+	}
+	return nil
+}
+
+// validateFilterAmount reports whether s is blank or a non-negative number
+func validateFilterAmount(s string) error {
+	v, err := parseFilterAmount(s)
+	if err != nil {
+		return errors.New("must be a number")
+	}
+	if v != nil && *v < 0 {
+		return errors.New("must not be negative")
+	}
+	return nil
+}
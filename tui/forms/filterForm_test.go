@@ -0,0 +1,72 @@
+package forms
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GVPproj/termsheet/models"
+	"github.com/GVPproj/termsheet/storage"
+)
+
+func TestInvoiceFilterInputsRoundTrip(t *testing.T) {
+	minTotal := 100.0
+	filter := storage.InvoiceFilter{
+		Search:   "acme",
+		Status:   models.StatusIssued,
+		ClientID: "client-1",
+		From:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		MinTotal: &minTotal,
+		Tags:     []string{"retainer"},
+	}
+
+	in := NewInvoiceFilterInputs(filter)
+	if in.From != "2026-01-01" || in.To != "" || in.MinTotal != "100" || in.MaxTotal != "" {
+		t.Fatalf("unexpected inputs: %+v", in)
+	}
+
+	got := in.Filter()
+	if got.Search != "acme" || got.Status != models.StatusIssued || got.ClientID != "client-1" {
+		t.Errorf("unexpected filter: %+v", got)
+	}
+	if !got.From.Equal(filter.From) || !got.To.IsZero() {
+		t.Errorf("unexpected date range: %v – %v", got.From, got.To)
+	}
+	if got.MinTotal == nil || *got.MinTotal != 100 || got.MaxTotal != nil {
+		t.Errorf("unexpected total range: %v – %v", got.MinTotal, got.MaxTotal)
+	}
+	if len(got.Tags) != 1 || got.Tags[0] != "retainer" {
+		t.Errorf("expected tags to be kept, got %v", got.Tags)
+	}
+
+	// Clearing every input clears the filter apart from tags
+	*in = InvoiceFilterInputs{tags: in.tags}
+	got = in.Filter()
+	got.Tags = nil
+	if !got.IsZero() {
+		t.Errorf("expected empty filter, got %+v", got)
+	}
+}
+
+func TestFilterValidators(t *testing.T) {
+	for _, s := range []string{"", "2026-01-31", " 2026-02-01 "} {
+		if err := validateFilterDate(s); err != nil {
+			t.Errorf("validateFilterDate(%q) = %v, want nil", s, err)
+		}
+	}
+	for _, s := range []string{"31/01/2026", "2026-13-01", "yesterday"} {
+		if err := validateFilterDate(s); err == nil {
+			t.Errorf("validateFilterDate(%q) = nil, want error", s)
+		}
+	}
+
+	for _, s := range []string{"", "0", "99.50"} {
+		if err := validateFilterAmount(s); err != nil {
+			t.Errorf("validateFilterAmount(%q) = %v, want nil", s, err)
+		}
+	}
+	for _, s := range []string{"-1", "lots"} {
+		if err := validateFilterAmount(s); err == nil {
+			t.Errorf("validateFilterAmount(%q) = nil, want error", s)
+		}
+	}
+}
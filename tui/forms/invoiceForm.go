@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/GVPproj/termsheet/models"
 	"github.com/GVPproj/termsheet/storage"
@@ -11,18 +12,25 @@ import (
 )
 
 // NewProviderSelectForm creates a form for selecting a provider
-func NewProviderSelectForm(providerID *string) (*huh.Form, error) {
-	providers, err := storage.ListProviders()
+// Archived providers are left out unless showArchived is set or they are the current selection
+func NewProviderSelectForm(providerID *string, showArchived bool) (*huh.Form, error) {
+	providers, err := storage.ListAllProviders()
 	if err != nil {
 		return nil, err
 	}
 
 	providerOptions := make([]huh.Option[string], 0, len(providers))
 	for _, p := range providers {
+		if p.Archived && !showArchived && p.ID != *providerID {
+			continue
+		}
 		label := p.Name
 		if p.Email != nil {
 			label += fmt.Sprintf(" (%s)", *p.Email)
 		}
+		if p.Archived {
+			label += " [archived]"
+		}
 		providerOptions = append(providerOptions, huh.NewOption(label, p.ID))
 	}
 
@@ -30,6 +38,7 @@ func NewProviderSelectForm(providerID *string) (*huh.Form, error) {
 		huh.NewGroup(
 			huh.NewSelect[string]().
 				Title("Select Provider").
+				Description("Press 'h' to show or hide archived providers").
 				Options(providerOptions...).
 				Value(providerID).
 				Validate(func(s string) error {
@@ -43,18 +52,25 @@ func NewProviderSelectForm(providerID *string) (*huh.Form, error) {
 }
 
 // NewClientSelectForm creates a form for selecting a client
-func NewClientSelectForm(clientID *string) (*huh.Form, error) {
-	clients, err := storage.ListClients()
+// Archived clients are left out unless showArchived is set or they are the current selection
+func NewClientSelectForm(clientID *string, showArchived bool) (*huh.Form, error) {
+	clients, err := storage.ListAllClients()
 	if err != nil {
 		return nil, err
 	}
 
 	clientOptions := make([]huh.Option[string], 0, len(clients))
 	for _, c := range clients {
+		if c.Archived && !showArchived && c.ID != *clientID {
+			continue
+		}
 		label := c.Name
 		if c.Email != nil {
 			label += fmt.Sprintf(" (%s)", *c.Email)
 		}
+		if c.Archived {
+			label += " [archived]"
+		}
 		clientOptions = append(clientOptions, huh.NewOption(label, c.ID))
 	}
 
@@ -62,6 +78,7 @@ func NewClientSelectForm(clientID *string) (*huh.Form, error) {
 		huh.NewGroup(
 			huh.NewSelect[string]().
 				Title("Select Client").
+				Description("Press 'h' to show or hide archived clients").
 				Options(clientOptions...).
 				Value(clientID).
 				Validate(func(s string) error {
@@ -123,40 +140,27 @@ func NewInvoiceItemForm(itemName, itemAmount, itemCostPerUnit *string) *huh.Form
 	)
 }
 
-// NewAddAnotherItemForm creates a confirmation form for adding another item
-func NewAddAnotherItemForm(addAnother *bool) *huh.Form {
-	return huh.NewForm(
-		huh.NewGroup(
-			huh.NewConfirm().
-				Title("Add another item?").
-				Affirmative("Yes").
-				Negative("No").
-				Value(addAnother),
-		),
-	)
-}
-
 // NewMarkPaidForm creates a form for marking invoice as paid
-func NewMarkPaidForm(paid *bool) *huh.Form {
-	return huh.NewForm(
-		huh.NewGroup(
-			huh.NewConfirm().
-				Title("Mark as Paid?").
-				Value(paid),
-		),
+// Additional groups (e.g. custom fields) are shown as further pages of the form
+func NewMarkPaidForm(paid *bool, groups ...*huh.Group) *huh.Form {
+	markPaid := huh.NewGroup(
+		huh.NewConfirm().
+			Title("Mark as Paid?").
+			Value(paid),
 	)
+	return huh.NewForm(append([]*huh.Group{markPaid}, groups...)...)
 }
 
 // NewProviderSelectFormWithData creates a provider select form with pre-populated data
-func NewProviderSelectFormWithData(providerID *string, existingProviderID string) (*huh.Form, error) {
+func NewProviderSelectFormWithData(providerID *string, existingProviderID string, showArchived bool) (*huh.Form, error) {
 	*providerID = existingProviderID
-	return NewProviderSelectForm(providerID)
+	return NewProviderSelectForm(providerID, showArchived)
 }
 
 // NewClientSelectFormWithData creates a client select form with pre-populated data
-func NewClientSelectFormWithData(clientID *string, existingClientID string) (*huh.Form, error) {
+func NewClientSelectFormWithData(clientID *string, existingClientID string, showArchived bool) (*huh.Form, error) {
 	*clientID = existingClientID
-	return NewClientSelectForm(clientID)
+	return NewClientSelectForm(clientID, showArchived)
 }
 
 // NewInvoiceItemFormWithData creates an item form with pre-populated data
@@ -168,9 +172,9 @@ func NewInvoiceItemFormWithData(itemName, itemAmount, itemCostPerUnit *string, i
 }
 
 // NewMarkPaidFormWithData creates a mark paid form with pre-populated data
-func NewMarkPaidFormWithData(paid *bool, existingPaid bool) *huh.Form {
+func NewMarkPaidFormWithData(paid *bool, existingPaid bool, groups ...*huh.Group) *huh.Form {
 	*paid = existingPaid
-	return NewMarkPaidForm(paid)
+	return NewMarkPaidForm(paid, groups...)
 }
 
 // NewDeleteConfirmForm creates a confirmation form for deleting an item
@@ -183,3 +187,52 @@ func NewDeleteConfirmForm(confirmed *bool) *huh.Form {
 		),
 	)
 }
+
+// NewNotesGroup creates a form page for the free-text notes printed at the end of an invoice
+func NewNotesGroup(notes *string) *huh.Group {
+	return huh.NewGroup(
+		huh.NewText().
+			Title("Notes").
+			Description("Printed at the end of the invoice, e.g. thanks or delivery details").
+			Value(notes),
+	)
+}
+
+// NewTermsGroup creates a form page for the payment terms printed on an invoice
+func NewTermsGroup(terms *string) *huh.Group {
+	return huh.NewGroup(
+		huh.NewText().
+			Title("Terms").
+			Description("Payment terms, e.g. Net 30, late payments subject to 2% interest").
+			Value(terms),
+	)
+}
+
+// NewDueDateGroup creates a form page for when payment of an invoice is due
+func NewDueDateGroup(due *string) *huh.Group {
+	return huh.NewGroup(
+		huh.NewInput().
+			Title("Due date").
+			Description("When payment is due; leave empty for no due date").
+			Placeholder("YYYY-MM-DD").
+			Value(due).
+			Validate(validateFilterDate),
+	)
+}
+
+// ParseDueDate parses the input of NewDueDateGroup; blank input gives nil
+func ParseDueDate(s string) (*time.Time, error) {
+	due, err := parseFilterDate(s)
+	if err != nil || due.IsZero() {
+		return nil, err
+	}
+	return &due, nil
+}
+
+// FormatDueDate formats a due date for NewDueDateGroup; nil gives ""
+func FormatDueDate(due *time.Time) string {
+	if due == nil {
+		return ""
+	}
+	return due.Format(filterDateLayout)
+}
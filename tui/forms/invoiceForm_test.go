@@ -63,25 +63,6 @@ func TestMarkPaidFormBinding(t *testing.T) {
 	}
 }
 
-func TestAddAnotherItemFormBinding(t *testing.T) {
-	// Test that add another item binding works
-	var addAnother bool
-
-	// Simulate user wanting to add another item
-	addAnother = true
-
-	if !addAnother {
-		t.Errorf("expected addAnother to be true")
-	}
-
-	// Simulate user not wanting to add another item
-	addAnother = false
-
-	if addAnother {
-		t.Errorf("expected addAnother to be false")
-	}
-}
-
 func TestMultipleItemsBinding(t *testing.T) {
 	// Test that multiple items can be collected
 	type Item struct {
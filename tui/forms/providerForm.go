@@ -2,41 +2,148 @@ package forms
 
 import (
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/GVPproj/termsheet/models"
+	"github.com/GVPproj/termsheet/numbering"
+	"github.com/GVPproj/termsheet/validation"
 	"github.com/charmbracelet/huh"
 )
 
 // NewProviderForm creates a new form for provider input
 // Takes pointers to string variables that will be bound to form fields
-func NewProviderForm(name, address, email, phone *string) *huh.Form {
-	return huh.NewForm(
-		huh.NewGroup(
-			huh.NewInput().
-				Title("Provider Name").
-				Value(name).
-				Validate(func(s string) error {
-					if s == "" {
-						return errors.New("provider name is required")
-					}
+// Additional groups (e.g. NewNumberingGroup) are shown as further pages of the form
+func NewProviderForm(name, address, email, phone *string, groups ...*huh.Group) *huh.Form {
+	details := huh.NewGroup(
+		huh.NewInput().
+			Title("Provider Name").
+			Value(name).
+			Validate(func(s string) error {
+				if s == "" {
+					return errors.New("provider name is required")
+				}
+				return nil
+			}),
+		huh.NewInput().
+			Title("Address").
+			Value(address),
+		huh.NewInput().
+			Title("Email").
+			Value(email).
+			Validate(validation.Email),
+		huh.NewInput().
+			Title("Phone").
+			Description("International format preferred, e.g. +44 20 7946 0958").
+			Value(phone).
+			Validate(validatePhone),
+	)
+
+	return huh.NewForm(append([]*huh.Group{details}, groups...)...)
+}
+
+// NewProviderProfileGroups creates the form pages for a provider's business and payment details
+func NewProviderProfileGroups(profile *models.ProviderProfile) []*huh.Group {
+	business := huh.NewGroup(
+		huh.NewInput().
+			Title("Registered Business Name").
+			Description("Leave empty if it matches the provider name").
+			Value(&profile.LegalName),
+		huh.NewInput().
+			Title("Tax / VAT Number").
+			Description("EU VAT numbers are checked, e.g. DE136695976").
+			Value(&profile.TaxID).
+			Validate(validateTaxID),
+		huh.NewInput().
+			Title("Company Registration Number").
+			Value(&profile.CompanyNumber),
+		huh.NewInput().
+			Title("Website").
+			Value(&profile.Website),
+		huh.NewInput().
+			Title("Logo File").
+			Description("Path to a PNG or JPEG image").
+			Value(&profile.LogoPath).
+			Validate(validateLogoPath),
+	)
+
+	payment := huh.NewGroup(
+		huh.NewInput().
+			Title("IBAN").
+			Value(&profile.IBAN),
+		huh.NewInput().
+			Title("BIC / SWIFT").
+			Value(&profile.BIC),
+		huh.NewText().
+			Title("Other Account Details").
+			Description("e.g. account number and sort code or routing number").
+			Lines(3).
+			Value(&profile.AccountDetails),
+		huh.NewText().
+			Title("Payment Instructions").
+			Description("Printed at the bottom of every invoice").
+			Lines(3).
+			Value(&profile.PaymentInstructions),
+	)
+
+	return []*huh.Group{business, payment}
+}
+
+// validateLogoPath checks that a logo path, if given, points to a readable image file
+func validateLogoPath(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png", ".jpg", ".jpeg":
+	default:
+		return errors.New("logo must be a PNG or JPEG file")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("logo file not found: %s", path)
+	}
+	if info.IsDir() {
+		return errors.New("logo path is a directory")
+	}
+	return nil
+}
+
+// NewNumberingGroup creates the form page for a provider's invoice numbering scheme
+func NewNumberingGroup(format, reset *string) *huh.Group {
+	return huh.NewGroup(
+		huh.NewInput().
+			Title("Invoice Number Format").
+			Description("Placeholders: {YYYY} {YY} {MM} {seq} {seq:04}").
+			Placeholder(numbering.DefaultFormat).
+			Value(format).
+			Validate(func(s string) error {
+				if s == "" {
+					// Empty means the default format
 					return nil
-				}),
-			huh.NewInput().
-				Title("Address").
-				Value(address),
-			huh.NewInput().
-				Title("Email").
-				Value(email),
-			huh.NewInput().
-				Title("Phone").
-				Value(phone),
-		),
+				}
+				return numbering.Validate(s)
+			}),
+		huh.NewSelect[string]().
+			Title("Restart Numbering").
+			Options(
+				huh.NewOption("Never", string(numbering.ResetNever)),
+				huh.NewOption("Every year", string(numbering.ResetYearly)),
+			).
+			Value(reset).
+			Validate(func(r string) error {
+				return numbering.ValidateScheme(*format, numbering.Reset(r))
+			}),
 	)
 }
 
 // NewProviderFormWithData creates a form pre-populated with existing provider data
 // Useful for editing an existing provider
-func NewProviderFormWithData(provider models.Entity, name, address, email, phone *string) *huh.Form {
+func NewProviderFormWithData(provider models.Entity, name, address, email, phone *string, groups ...*huh.Group) *huh.Form {
 	// Pre-populate the bound variables with existing data
 	*name = provider.Name
 
@@ -59,5 +166,5 @@ func NewProviderFormWithData(provider models.Entity, name, address, email, phone
 		*phone = ""
 	}
 
-	return NewProviderForm(name, address, email, phone)
+	return NewProviderForm(name, address, email, phone, groups...)
 }
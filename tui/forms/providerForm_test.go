@@ -1,6 +1,8 @@
 package forms
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -93,3 +95,30 @@ func TestNewProviderFormWithDataNilFields(t *testing.T) {
 	}
 }
 
+func TestValidateLogoPath(t *testing.T) {
+	dir := t.TempDir()
+	logo := filepath.Join(dir, "logo.png")
+	if err := os.WriteFile(logo, []byte("png"), 0o644); err != nil {
+		t.Fatalf("failed to write logo: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{"empty", "", false},
+		{"existing png", logo, false},
+		{"missing file", filepath.Join(dir, "missing.jpg"), true},
+		{"unsupported type", filepath.Join(dir, "logo.gif"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateLogoPath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateLogoPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+		})
+	}
+}
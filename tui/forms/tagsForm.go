@@ -0,0 +1,37 @@
+package forms
+
+import (
+	"fmt"
+
+	"github.com/GVPproj/termsheet/models"
+	"github.com/charmbracelet/huh"
+)
+
+// NewTagsGroup creates a form page for editing comma-separated tags, see models.ParseTags
+func NewTagsGroup(tags *string) *huh.Group {
+	return huh.NewGroup(
+		huh.NewInput().
+			Title("Tags").
+			Description("Comma-separated, e.g. agency, retainer").
+			Value(tags),
+	)
+}
+
+// NewTagFilterForm creates a form for choosing which tags a list is filtered by.
+// Only entries carrying every selected tag are listed; select none to show everything.
+func NewTagFilterForm(selected *[]string, tags []models.Tag) *huh.Form {
+	options := make([]huh.Option[string], 0, len(tags))
+	for _, t := range tags {
+		options = append(options, huh.NewOption(fmt.Sprintf("#%s (%d)", t.Name, t.Count), t.Name))
+	}
+
+	return huh.NewForm(
+		huh.NewGroup(
+			huh.NewMultiSelect[string]().
+				Title("Filter by tags").
+				Description("Space to toggle, enter to apply; select none to clear the filter").
+				Options(options...).
+				Value(selected),
+		),
+	)
+}
@@ -0,0 +1,25 @@
+package forms
+
+import (
+	"github.com/GVPproj/termsheet/validation"
+)
+
+// validatePhone reports whether s is a usable phone number, see validation.Phone
+func validatePhone(s string) error {
+	_, err := validation.Phone(s)
+	return err
+}
+
+// validateTaxID reports whether s is a usable tax ID, see validation.TaxID
+func validateTaxID(s string) error {
+	_, err := validation.TaxID(s)
+	return err
+}
+
+// validatePostalCode returns a validator for a postal code in the country currently bound to country
+func validatePostalCode(country *string) func(string) error {
+	return func(s string) error {
+		_, err := validation.PostalCode(*country, s)
+		return err
+	}
+}
@@ -0,0 +1,244 @@
+// Package keys holds the key bindings of the interface. The bindings come from a preset,
+// "default", "vim" or "emacs", with any action rebound by the user's config file;
+// components match keys against Current() rather than hardcoding them.
+package keys
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/GVPproj/termsheet/tui/components/table"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/huh"
+)
+
+// KeyMap holds a binding for every action
+type KeyMap struct {
+	// Global keys
+	Quit    key.Binding
+	Back    key.Binding
+	Search  key.Binding
+	Help    key.Binding
+	Palette key.Binding
+
+	// Moving through lists and tables
+	Up       key.Binding
+	Down     key.Binding
+	PageUp   key.Binding
+	PageDown key.Binding
+	Top      key.Binding
+	Bottom   key.Binding
+
+	// Actions in lists
+	Open         key.Binding
+	New          key.Binding
+	Delete       key.Binding
+	Undo         key.Binding
+	Archive      key.Binding
+	ShowArchived key.Binding
+	Filter       key.Binding
+	TagFilter    key.Binding
+	Trash        key.Binding
+	EditResult   key.Binding
+
+	// Actions on the invoice selected in the invoice list
+	View     key.Binding
+	Edit     key.Binding
+	Issue    key.Binding
+	MarkPaid key.Binding
+	Preview  key.Binding
+
+	// Actions in the item editor of an invoice
+	MoveUp   key.Binding
+	MoveDown key.Binding
+	Continue key.Binding
+}
+
+// Presets lists the names of the built-in key maps
+var Presets = []string{"default", "vim", "emacs"}
+
+// bind creates a binding for keys, its help showing them all
+func bind(desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(strings.Join(keys, "/"), desc))
+}
+
+// Default returns the default key map, with arrow keys and vim-style j/k for moving
+func Default() KeyMap {
+	return KeyMap{
+		Quit:    bind("quit", "q", "ctrl+c"),
+		Back:    bind("go back", "esc"),
+		Search:  bind("search everything", "/"),
+		Help:    bind("show all keys", "?"),
+		Palette: bind("open the command palette", "ctrl+k"),
+
+		Up:       bind("up", "up", "k"),
+		Down:     bind("down", "down", "j"),
+		PageUp:   bind("page up", "pgup", "ctrl+u"),
+		PageDown: bind("page down", "pgdown", "ctrl+d"),
+		Top:      bind("go to top", "home", "g"),
+		Bottom:   bind("go to bottom", "end", "G"),
+
+		Open:         bind("open", "enter"),
+		New:          bind("new", "n"),
+		Delete:       bind("delete", "d"),
+		Undo:         bind("undo delete", "u"),
+		Archive:      bind("archive/restore", "a"),
+		ShowArchived: bind("show/hide archived", "h"),
+		Filter:       bind("filter", "f"),
+		TagFilter:    bind("filter by tag", "t"),
+		Trash:        bind("open trash", "x"),
+		EditResult:   bind("edit", "ctrl+e"),
+
+		View:     bind("view", "v"),
+		Edit:     bind("edit", "e"),
+		Issue:    bind("issue", "i"),
+		MarkPaid: bind("mark paid/unpaid", "m"),
+		Preview:  bind("show/hide preview", "p"),
+
+		MoveUp:   bind("move up", "K", "shift+up"),
+		MoveDown: bind("move down", "J", "shift+down"),
+		Continue: bind("continue", "c"),
+	}
+}
+
+// Vim returns the default key map with vim's paging keys and ctrl+o to go back
+func Vim() KeyMap {
+	km := Default()
+	km.Back = bind("go back", "esc", "ctrl+o")
+	km.PageUp = bind("page up", "ctrl+b", "pgup", "ctrl+u")
+	km.PageDown = bind("page down", "ctrl+f", "pgdown", "ctrl+d")
+	return km
+}
+
+// Emacs returns a key map moving with emacs' keys, going back with ctrl+g
+func Emacs() KeyMap {
+	km := Default()
+	km.Back = bind("go back", "esc", "ctrl+g")
+	km.Search = bind("search everything", "ctrl+s", "/")
+	km.Up = bind("up", "up", "ctrl+p")
+	km.Down = bind("down", "down", "ctrl+n")
+	km.PageUp = bind("page up", "pgup", "alt+v")
+	km.PageDown = bind("page down", "pgdown", "ctrl+v")
+	km.Top = bind("go to top", "home", "alt+<")
+	km.Bottom = bind("go to bottom", "end", "alt+>")
+	return km
+}
+
+// New returns the named preset with the given actions rebound. Bindings maps action
+// names, such as "delete" or "page_down", to their keys; no keys unbinds the action.
+func New(preset string, bindings map[string][]string) (KeyMap, error) {
+	var km KeyMap
+	switch preset {
+	case "", "default":
+		km = Default()
+	case "vim":
+		km = Vim()
+	case "emacs":
+		km = Emacs()
+	default:
+		return km, fmt.Errorf("unknown keymap preset %q, expected one of %s", preset, strings.Join(Presets, ", "))
+	}
+
+	actions := km.actions()
+	for name, keys := range bindings {
+		b, ok := actions[name]
+		if !ok {
+			return km, fmt.Errorf("unknown action %q in keymap, expected one of %s",
+				name, strings.Join(slices.Sorted(maps.Keys(actions)), ", "))
+		}
+		b.SetKeys(keys...)
+		b.SetHelp(strings.Join(keys, "/"), b.Help().Desc)
+		b.SetEnabled(len(keys) > 0)
+	}
+	return km, nil
+}
+
+// actions maps the action names used in the config file to the bindings in km
+func (km *KeyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"quit":          &km.Quit,
+		"back":          &km.Back,
+		"search":        &km.Search,
+		"help":          &km.Help,
+		"palette":       &km.Palette,
+		"up":            &km.Up,
+		"down":          &km.Down,
+		"page_up":       &km.PageUp,
+		"page_down":     &km.PageDown,
+		"top":           &km.Top,
+		"bottom":        &km.Bottom,
+		"open":          &km.Open,
+		"new":           &km.New,
+		"delete":        &km.Delete,
+		"undo":          &km.Undo,
+		"archive":       &km.Archive,
+		"show_archived": &km.ShowArchived,
+		"filter":        &km.Filter,
+		"tag_filter":    &km.TagFilter,
+		"trash":         &km.Trash,
+		"edit_result":   &km.EditResult,
+		"view":          &km.View,
+		"edit":          &km.Edit,
+		"issue":         &km.Issue,
+		"mark_paid":     &km.MarkPaid,
+		"preview":       &km.Preview,
+		"move_up":       &km.MoveUp,
+		"move_down":     &km.MoveDown,
+		"continue":      &km.Continue,
+	}
+}
+
+// Global returns the bindings that work in every view
+func (km KeyMap) Global() []key.Binding {
+	return []key.Binding{km.Back, km.Search, km.Palette, km.Help, Hint(km.Quit, "quit (from the menu)")}
+}
+
+// Table returns the key map for moving through tables
+func (km KeyMap) Table() table.KeyMap {
+	return table.KeyMap{Up: km.Up, Down: km.Down, PageUp: km.PageUp, PageDown: km.PageDown, Top: km.Top, Bottom: km.Bottom}
+}
+
+// Form returns the key map for select fields in forms, moving with the same keys as the tables.
+// Selects filter with the filter key rather than huh's "/", which searches everything.
+func (km KeyMap) Form() *huh.KeyMap {
+	fk := huh.NewDefaultKeyMap()
+	fk.Select.Up = km.Up
+	fk.Select.Down = km.Down
+	fk.Select.HalfPageUp = km.PageUp
+	fk.Select.HalfPageDown = km.PageDown
+	fk.Select.GotoTop = km.Top
+	fk.Select.GotoBottom = km.Bottom
+	fk.Select.Filter = km.Filter
+	return fk
+}
+
+// Filtering reports whether form's focused field is a select being filtered,
+// in which case keys are typed into the filter rather than being shortcuts
+func Filtering(form *huh.Form) bool {
+	if form == nil {
+		return false
+	}
+	field, ok := form.GetFocusedField().(interface{ GetFiltering() bool })
+	return ok && field.GetFiltering()
+}
+
+// Hint returns b described for a particular view, e.g. the delete key as "remove a contact"
+func Hint(b key.Binding, desc string) key.Binding {
+	b.SetHelp(b.Help().Key, desc)
+	return b
+}
+
+// current is the key map in use
+var current = Default()
+
+// Use sets the key map for the whole interface
+func Use(km KeyMap) {
+	current = km
+}
+
+// Current returns the key map in use
+func Current() KeyMap {
+	return current
+}
@@ -0,0 +1,103 @@
+package keys
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func press(s string) tea.KeyMsg {
+	switch s {
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEscape}
+	case "ctrl+o":
+		return tea.KeyMsg{Type: tea.KeyCtrlO}
+	case "ctrl+g":
+		return tea.KeyMsg{Type: tea.KeyCtrlG}
+	case "ctrl+f":
+		return tea.KeyMsg{Type: tea.KeyCtrlF}
+	case "ctrl+n":
+		return tea.KeyMsg{Type: tea.KeyCtrlN}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestPresets(t *testing.T) {
+	tests := []struct {
+		preset  string
+		binding func(KeyMap) key.Binding
+		key     string
+		matches bool
+	}{
+		{"", func(km KeyMap) key.Binding { return km.Down }, "j", true},
+		{"default", func(km KeyMap) key.Binding { return km.Back }, "ctrl+o", false},
+		{"vim", func(km KeyMap) key.Binding { return km.Back }, "ctrl+o", true},
+		{"vim", func(km KeyMap) key.Binding { return km.PageDown }, "ctrl+f", true},
+		{"emacs", func(km KeyMap) key.Binding { return km.Back }, "ctrl+g", true},
+		{"emacs", func(km KeyMap) key.Binding { return km.Down }, "ctrl+n", true},
+		{"emacs", func(km KeyMap) key.Binding { return km.Down }, "j", false},
+	}
+
+	for _, tt := range tests {
+		km, err := New(tt.preset, nil)
+		if err != nil {
+			t.Fatalf("New(%q) failed: %v", tt.preset, err)
+		}
+		if got := key.Matches(press(tt.key), tt.binding(km)); got != tt.matches {
+			t.Errorf("preset %q: expected %s to match %v, got %v", tt.preset, tt.key, tt.matches, got)
+		}
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	km, err := New("vim", map[string][]string{"delete": {"D"}, "trash": {}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if key.Matches(press("d"), km.Delete) || !key.Matches(press("D"), km.Delete) {
+		t.Error("expected delete to be rebound from d to D")
+	}
+	if km.Delete.Help().Key != "D" || km.Delete.Help().Desc != "delete" {
+		t.Errorf("expected the help to show the new key, got %+v", km.Delete.Help())
+	}
+	if km.Trash.Enabled() {
+		t.Error("expected trash to be unbound")
+	}
+	if !key.Matches(press("ctrl+o"), km.Back) {
+		t.Error("expected the rest of the vim preset to be kept")
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	if _, err := New("nano", nil); err == nil || !strings.Contains(err.Error(), "nano") {
+		t.Errorf("expected an error for an unknown preset, got %v", err)
+	}
+	if _, err := New("", map[string][]string{"explode": {"e"}}); err == nil || !strings.Contains(err.Error(), "explode") {
+		t.Errorf("expected an error for an unknown action, got %v", err)
+	}
+}
+
+func TestHint(t *testing.T) {
+	km := Default()
+	hinted := Hint(km.Delete, "remove a contact")
+	if hinted.Help().Desc != "remove a contact" || km.Delete.Help().Desc != "delete" {
+		t.Errorf("expected Hint to describe a copy, got %q and %q", hinted.Help().Desc, km.Delete.Help().Desc)
+	}
+}
+
+func TestFormFilterKey(t *testing.T) {
+	fk := Default().Form()
+	if key.Matches(press("/"), fk.Select.Filter) || !key.Matches(press("f"), fk.Select.Filter) {
+		t.Error("expected selects to filter with f, leaving / to search")
+	}
+}
+
+func TestTableKeys(t *testing.T) {
+	tk := Vim().Table()
+	if !key.Matches(press("k"), tk.Up) || !key.Matches(press("ctrl+f"), tk.PageDown) {
+		t.Error("expected tables to move with the key map's keys")
+	}
+}
@@ -0,0 +1,149 @@
+// Package router maps each view to the component that owns it and how it is drawn,
+// so the root model can pass messages to, render and return to any view without
+// knowing them all. A new part of the interface registers its component and nothing else.
+package router
+
+import (
+	"fmt"
+
+	"github.com/GVPproj/termsheet/types"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
+
+// Route describes a view and how it is drawn
+type Route struct {
+	View types.View
+	// Title labels the view in the breadcrumbs
+	Title string
+	// Render draws the view in a terminal width wide; form is the form shown in it, if any
+	Render func(form *huh.Form, width int) string
+	// Shortcuts are on in views where keys can't be text input, e.g. menus and lists,
+	// so "/" opens the global search there and "?" the help overlay
+	Shortcuts bool
+	// Prompt is on in views showing a form to fill in, e.g. to create or filter records;
+	// accessible mode asks its questions as plain prompts
+	Prompt bool
+	// Keys are the keys the view responds to, listed in the help overlay
+	Keys []key.Binding
+}
+
+// Component is a part of the interface owning one or more views
+type Component interface {
+	// Routes lists the views the component owns
+	Routes() []Route
+	// Update handles a message in one of the component's views,
+	// returning a transition if it moves to another view
+	Update(msg tea.Msg, view types.View) (*types.ViewTransition, tea.Cmd)
+	// Resume shows one of the component's views again when the user goes back to it,
+	// or returns a nil transition if it can't be shown again, e.g. a half-filled form
+	Resume(view types.View) (*types.ViewTransition, tea.Cmd)
+	// GetForm returns the form the component is showing, if any
+	GetForm() *huh.Form
+}
+
+// MenuEntry is an option in the main menu
+type MenuEntry struct {
+	// Key identifies the option when it is selected
+	Key string
+	// Label is what the menu shows
+	Label string
+	// Open shows the component's first view
+	Open func() (*types.ViewTransition, tea.Cmd)
+}
+
+// MenuComponent is a component listed in the main menu
+type MenuComponent interface {
+	Component
+	MenuEntry() MenuEntry
+}
+
+// route is a registered view with the component that owns it, if any
+type route struct {
+	Route
+	owner Component
+}
+
+// Router holds the registered views
+type Router struct {
+	routes map[types.View]route
+	menu   []MenuEntry
+}
+
+// New creates a router with no views registered
+func New() *Router {
+	return &Router{routes: make(map[types.View]route)}
+}
+
+// Register adds the views a component owns, and its main menu entry if it has one.
+// Registering a view twice is a programming error and panics.
+func (r *Router) Register(component Component) {
+	for _, rt := range component.Routes() {
+		r.add(rt, component)
+	}
+	if entry, ok := component.(MenuComponent); ok {
+		r.menu = append(r.menu, entry.MenuEntry())
+	}
+}
+
+// Handle adds a view the root model handles itself, such as the main menu
+func (r *Router) Handle(rt Route) {
+	r.add(rt, nil)
+}
+
+func (r *Router) add(rt Route, owner Component) {
+	if _, exists := r.routes[rt.View]; exists {
+		panic(fmt.Sprintf("router: view %d registered twice", rt.View))
+	}
+	r.routes[rt.View] = route{Route: rt, owner: owner}
+}
+
+// Owner returns the component that owns view, or nil if the root model handles it or it isn't registered
+func (r *Router) Owner(view types.View) Component {
+	return r.routes[view].owner
+}
+
+// Title returns the breadcrumb label of view
+func (r *Router) Title(view types.View) string {
+	return r.routes[view].Title
+}
+
+// Shortcuts reports whether global shortcuts, such as "/" for search, are on in view
+func (r *Router) Shortcuts(view types.View) bool {
+	return r.routes[view].Shortcuts
+}
+
+// Prompt reports whether view shows a form that accessible mode asks as plain prompts
+func (r *Router) Prompt(view types.View) bool {
+	return r.routes[view].Prompt
+}
+
+// Keys returns the keys view responds to
+func (r *Router) Keys(view types.View) []key.Binding {
+	return r.routes[view].Keys
+}
+
+// Render draws view with the given form in a terminal width wide
+func (r *Router) Render(view types.View, form *huh.Form, width int) string {
+	rt, ok := r.routes[view]
+	if !ok || rt.Render == nil {
+		return "View not implemented yet\n\nPress ESC to go back"
+	}
+	return rt.Render(form, width)
+}
+
+// Menu returns the main menu entries in the order their components were registered
+func (r *Router) Menu() []MenuEntry {
+	return r.menu
+}
+
+// Entry returns the main menu entry with the given key
+func (r *Router) Entry(key string) (MenuEntry, bool) {
+	for _, entry := range r.menu {
+		if entry.Key == key {
+			return entry, true
+		}
+	}
+	return MenuEntry{}, false
+}
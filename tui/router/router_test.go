@@ -0,0 +1,88 @@
+package router
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/GVPproj/termsheet/types"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
+
+// fakeComponent owns the views it is given, rendering each as its title
+type fakeComponent struct {
+	routes []Route
+}
+
+func (f *fakeComponent) Routes() []Route { return f.routes }
+
+func (f *fakeComponent) Update(tea.Msg, types.View) (*types.ViewTransition, tea.Cmd) { return nil, nil }
+
+func (f *fakeComponent) Resume(types.View) (*types.ViewTransition, tea.Cmd) { return nil, nil }
+
+func (f *fakeComponent) GetForm() *huh.Form { return nil }
+
+// fakeMenuComponent is also listed in the main menu
+type fakeMenuComponent struct {
+	fakeComponent
+	key string
+}
+
+func (f *fakeMenuComponent) MenuEntry() MenuEntry {
+	return MenuEntry{Key: f.key, Label: f.key + " label"}
+}
+
+func render(title string) func(*huh.Form, int) string {
+	return func(*huh.Form, int) string { return "rendered " + title }
+}
+
+func TestRegisterRoutesViewsToTheirOwner(t *testing.T) {
+	r := New()
+	r.Handle(Route{View: types.MenuView, Title: "Menu", Render: render("menu"), Shortcuts: true})
+	invoices := &fakeMenuComponent{key: "Invoices", fakeComponent: fakeComponent{routes: []Route{
+		{View: types.InvoicesListView, Title: "Invoices", Render: render("list"), Shortcuts: true},
+		{View: types.InvoiceEditView, Title: "Edit", Render: render("edit"), Prompt: true},
+	}}}
+	search := &fakeComponent{routes: []Route{{View: types.SearchView, Title: "Search", Render: render("search")}}}
+	r.Register(invoices)
+	r.Register(search)
+
+	if r.Owner(types.InvoiceEditView) != invoices || r.Owner(types.SearchView) != search {
+		t.Error("expected each view owned by the component that registered it")
+	}
+	if r.Owner(types.MenuView) != nil {
+		t.Error("expected the menu to be handled by the root model")
+	}
+	if got := r.Render(types.InvoiceEditView, nil, 0); got != "rendered edit" {
+		t.Errorf("expected the edit view's renderer, got %q", got)
+	}
+	if r.Title(types.InvoicesListView) != "Invoices" || !r.Shortcuts(types.InvoicesListView) || r.Shortcuts(types.InvoiceEditView) {
+		t.Error("expected the registered title and search setting")
+	}
+	if !r.Prompt(types.InvoiceEditView) || r.Prompt(types.InvoicesListView) {
+		t.Error("expected only the edit form to be asked as prompts")
+	}
+	if !strings.Contains(r.Render(types.ClientsListView, nil, 0), "not implemented") {
+		t.Error("expected a placeholder for views nobody registered")
+	}
+
+	// Only components with an entry are in the menu
+	if menu := r.Menu(); len(menu) != 1 || menu[0].Key != "Invoices" {
+		t.Errorf("expected only the invoices in the menu, got %v", menu)
+	}
+	if _, ok := r.Entry("Invoices"); !ok {
+		t.Error("expected the invoices entry to be found by key")
+	}
+}
+
+func TestRegisterTwicePanics(t *testing.T) {
+	r := New()
+	r.Register(&fakeComponent{routes: []Route{{View: types.SearchView}}})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a view twice to panic")
+		}
+	}()
+	r.Register(&fakeComponent{routes: []Route{{View: types.SearchView}}})
+}
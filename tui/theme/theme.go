@@ -0,0 +1,234 @@
+// Package theme holds the colours of the interface. A theme is chosen by name,
+// "dark", "light", "solarized" or "high-contrast", in the user's config file, with any of
+// its colours changed there; packages with styles restyle themselves through OnChange
+// rather than hardcoding colours.
+package theme
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Theme holds a colour for every role text plays in the interface
+type Theme struct {
+	// Primary colours titles, labels, headers and borders
+	Primary lipgloss.TerminalColor
+	// Accent colours the selected row and section titles
+	Accent lipgloss.TerminalColor
+	// Selector colours the cursor in forms
+	Selector lipgloss.TerminalColor
+	// Text colours values and list items
+	Text lipgloss.TerminalColor
+	// Muted colours secondary text, such as empty lists and breadcrumbs
+	Muted lipgloss.TerminalColor
+	// Subtle colours help text and separators
+	Subtle lipgloss.TerminalColor
+
+	// Notification colours
+	Info    lipgloss.TerminalColor
+	Success lipgloss.TerminalColor
+	Warning lipgloss.TerminalColor
+	Error   lipgloss.TerminalColor
+}
+
+// Names lists the names of the built-in themes
+var Names = []string{"dark", "light", "solarized", "high-contrast"}
+
+// Dark returns the default theme, made for dark terminals
+func Dark() Theme {
+	return Theme{
+		Primary:  lipgloss.Color("#61AFEF"),
+		Accent:   lipgloss.Color("#D33682"),
+		Selector: lipgloss.Color("#F255A1"),
+		Text:     lipgloss.Color("#98C379"),
+		Muted:    lipgloss.Color("#5C6370"),
+		Subtle:   lipgloss.AdaptiveColor{Light: "#D9DCCF", Dark: "#383838"},
+		Info:     lipgloss.Color("#61AFEF"),
+		Success:  lipgloss.Color("#98C379"),
+		Warning:  lipgloss.Color("#E5C07B"),
+		Error:    lipgloss.Color("#E06C75"),
+	}
+}
+
+// Light returns a theme made for light terminals
+func Light() Theme {
+	return Theme{
+		Primary:  lipgloss.Color("#0550AE"),
+		Accent:   lipgloss.Color("#A626A4"),
+		Selector: lipgloss.Color("#C4167F"),
+		Text:     lipgloss.Color("#116329"),
+		Muted:    lipgloss.Color("#6E7781"),
+		Subtle:   lipgloss.Color("#8C959F"),
+		Info:     lipgloss.Color("#0550AE"),
+		Success:  lipgloss.Color("#116329"),
+		Warning:  lipgloss.Color("#9A6700"),
+		Error:    lipgloss.Color("#CF222E"),
+	}
+}
+
+// Solarized returns a theme in the Solarized palette, readable on its light and dark backgrounds
+func Solarized() Theme {
+	return Theme{
+		Primary:  lipgloss.Color("#268BD2"),
+		Accent:   lipgloss.Color("#D33682"),
+		Selector: lipgloss.Color("#6C71C4"),
+		Text:     lipgloss.Color("#859900"),
+		Muted:    lipgloss.AdaptiveColor{Light: "#93A1A1", Dark: "#586E75"},
+		Subtle:   lipgloss.AdaptiveColor{Light: "#93A1A1", Dark: "#586E75"},
+		Info:     lipgloss.Color("#268BD2"),
+		Success:  lipgloss.Color("#859900"),
+		Warning:  lipgloss.Color("#B58900"),
+		Error:    lipgloss.Color("#DC322F"),
+	}
+}
+
+// HighContrast returns a theme of bright colours on dark terminals and dark ones on light
+// terminals, with no dimmed text
+func HighContrast() Theme {
+	fg := lipgloss.AdaptiveColor{Light: "#000000", Dark: "#FFFFFF"}
+	return Theme{
+		Primary:  lipgloss.AdaptiveColor{Light: "#00008B", Dark: "#00FFFF"},
+		Accent:   lipgloss.AdaptiveColor{Light: "#8B008B", Dark: "#FFFF00"},
+		Selector: lipgloss.AdaptiveColor{Light: "#8B008B", Dark: "#FFFF00"},
+		Text:     fg,
+		Muted:    fg,
+		Subtle:   fg,
+		Info:     lipgloss.AdaptiveColor{Light: "#00008B", Dark: "#00FFFF"},
+		Success:  lipgloss.AdaptiveColor{Light: "#005500", Dark: "#00FF00"},
+		Warning:  lipgloss.AdaptiveColor{Light: "#5C4000", Dark: "#FFFF00"},
+		Error:    lipgloss.AdaptiveColor{Light: "#A00000", Dark: "#FF5555"},
+	}
+}
+
+// New returns the built-in theme named base, empty meaning "dark", with the given colours
+// changed. Colors maps role names, such as "primary" or "error", to a hex colour like
+// "#61AFEF" or an ANSI colour number from 0 to 255.
+func New(base string, colors map[string]string) (Theme, error) {
+	var t Theme
+	switch base {
+	case "", "dark":
+		t = Dark()
+	case "light":
+		t = Light()
+	case "solarized":
+		t = Solarized()
+	case "high-contrast":
+		t = HighContrast()
+	default:
+		return t, fmt.Errorf("unknown theme %q; choose one of %s", base, strings.Join(slices.Sorted(slices.Values(Names)), ", "))
+	}
+
+	roles := t.roles()
+	for name, value := range colors {
+		role, ok := roles[name]
+		if !ok {
+			return t, fmt.Errorf("unknown colour %q in theme, expected one of %s",
+				name, strings.Join(slices.Sorted(maps.Keys(roles)), ", "))
+		}
+		if !validColor(value) {
+			return t, fmt.Errorf("invalid colour %q for %s in theme, expected a hex colour like #61AFEF or a number from 0 to 255", value, name)
+		}
+		*role = lipgloss.Color(value)
+	}
+	return t, nil
+}
+
+// roles maps the colour names used in the config file to the colours in t
+func (t *Theme) roles() map[string]*lipgloss.TerminalColor {
+	return map[string]*lipgloss.TerminalColor{
+		"primary":  &t.Primary,
+		"accent":   &t.Accent,
+		"selector": &t.Selector,
+		"text":     &t.Text,
+		"muted":    &t.Muted,
+		"subtle":   &t.Subtle,
+		"info":     &t.Info,
+		"success":  &t.Success,
+		"warning":  &t.Warning,
+		"error":    &t.Error,
+	}
+}
+
+// validColor reports whether value is a colour lipgloss can draw: "#RGB", "#RRGGBB",
+// or the number of an ANSI colour
+func validColor(value string) bool {
+	if hex, ok := strings.CutPrefix(value, "#"); ok {
+		if len(hex) != 3 && len(hex) != 6 {
+			return false
+		}
+		_, err := strconv.ParseUint(hex, 16, 32)
+		return err == nil
+	}
+	n, err := strconv.Atoi(value)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// Plain reports whether text is drawn without colours or other styling, because NO_COLOR
+// is set or the terminal has no colours. Styling can't tell things apart then.
+func Plain() bool {
+	return lipgloss.ColorProfile() == termenv.Ascii
+}
+
+// Form returns the theme for forms
+func (t Theme) Form() *huh.Theme {
+	ft := huh.ThemeCharm()
+	ft.Focused.Title = ft.Focused.Title.Foreground(t.Primary)
+	ft.Focused.Description = ft.Focused.Description.Foreground(t.Muted)
+	ft.Focused.SelectedOption = ft.Focused.SelectedOption.Foreground(t.Accent)
+	ft.Focused.SelectSelector = ft.Focused.SelectSelector.Foreground(t.Selector)
+	ft.Focused.MultiSelectSelector = ft.Focused.MultiSelectSelector.Foreground(t.Selector)
+	ft.Focused.ErrorIndicator = ft.Focused.ErrorIndicator.Foreground(t.Error)
+	ft.Focused.ErrorMessage = ft.Focused.ErrorMessage.Foreground(t.Error)
+	ft.Focused.NextIndicator = ft.Focused.NextIndicator.Foreground(t.Selector)
+	ft.Focused.PrevIndicator = ft.Focused.PrevIndicator.Foreground(t.Selector)
+	ft.Focused.FocusedButton = ft.Focused.FocusedButton.Background(t.Selector)
+	if Plain() {
+		// Without colours the buttons of a confirm field look alike, so brackets mark the chosen one
+		ft.Focused.FocusedButton = ft.Focused.FocusedButton.Transform(func(s string) string { return "[" + s + "]" })
+		ft.Focused.BlurredButton = ft.Focused.BlurredButton.Transform(func(s string) string { return " " + s + " " })
+	}
+	ft.Focused.Next = ft.Focused.FocusedButton
+
+	// Fields other than the focused one look the same, without its border and page indicators
+	ft.Blurred = ft.Focused
+	ft.Blurred.Base = ft.Focused.Base.BorderStyle(lipgloss.HiddenBorder())
+	ft.Blurred.Card = ft.Blurred.Base
+	ft.Blurred.NextIndicator = lipgloss.NewStyle()
+	ft.Blurred.PrevIndicator = lipgloss.NewStyle()
+	ft.Group.Title = ft.Focused.Title
+	ft.Group.Description = ft.Focused.Description
+	return ft
+}
+
+// current is the theme in use
+var current = Dark()
+
+// restylers are called with the theme whenever it changes
+var restylers []func(Theme)
+
+// Use sets the theme for the whole interface, restyling every package registered with OnChange
+func Use(t Theme) {
+	current = t
+	for _, restyle := range restylers {
+		restyle(t)
+	}
+}
+
+// Current returns the theme in use
+func Current() Theme {
+	return current
+}
+
+// OnChange styles a package with the theme in use now, by calling restyle,
+// and again whenever Use changes it. Packages call it from init.
+func OnChange(restyle func(Theme)) {
+	restylers = append(restylers, restyle)
+	restyle(current)
+}
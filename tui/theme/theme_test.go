@@ -0,0 +1,91 @@
+package theme
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestNew(t *testing.T) {
+	for _, name := range append([]string{""}, Names...) {
+		th, err := New(name, nil)
+		if err != nil {
+			t.Errorf("New(%q) failed: %v", name, err)
+		}
+		if th.Primary == nil || th.Error == nil {
+			t.Errorf("New(%q) left colours unset: %+v", name, th)
+		}
+	}
+
+	if th, _ := New("", nil); th != Dark() {
+		t.Error("expected the dark theme by default")
+	}
+
+	_, err := New("neon", nil)
+	if err == nil || !strings.Contains(err.Error(), "high-contrast") {
+		t.Errorf("expected an error listing the themes, got %v", err)
+	}
+}
+
+func TestNewColors(t *testing.T) {
+	th, err := New("light", map[string]string{"primary": "#FF8800", "error": "196"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if th.Primary != lipgloss.Color("#FF8800") || th.Error != lipgloss.Color("196") {
+		t.Errorf("expected the colours to be changed, got %v and %v", th.Primary, th.Error)
+	}
+	if th.Accent != Light().Accent {
+		t.Error("expected the other colours to come from the base theme")
+	}
+
+	_, err = New("", map[string]string{"background": "#000000"})
+	if err == nil || !strings.Contains(err.Error(), "primary") {
+		t.Errorf("expected an error listing the colours, got %v", err)
+	}
+
+	for _, value := range []string{"red", "#12345", "#GGGGGG", "256", "-1", ""} {
+		if _, err := New("", map[string]string{"text": value}); err == nil {
+			t.Errorf("expected %q to be rejected", value)
+		}
+	}
+	for _, value := range []string{"#abc", "#61AFEF", "0", "255"} {
+		if _, err := New("", map[string]string{"text": value}); err != nil {
+			t.Errorf("expected %q to be accepted, got %v", value, err)
+		}
+	}
+}
+
+func TestUseRestyles(t *testing.T) {
+	t.Cleanup(func() {
+		Use(Dark())
+		restylers = nil
+	})
+
+	var got Theme
+	OnChange(func(th Theme) { got = th })
+	if got != Dark() {
+		t.Error("expected OnChange to style with the current theme straight away")
+	}
+
+	Use(Light())
+	if got != Light() || Current() != Light() {
+		t.Error("expected Use to restyle with the new theme")
+	}
+}
+
+func TestPlainFormMarksFocusedButton(t *testing.T) {
+	// Tests run without a terminal, so without colours
+	if !Plain() {
+		t.Skip("colours are on")
+	}
+
+	ft := Dark().Form()
+	if got := ft.Focused.FocusedButton.Render("Yes"); !strings.Contains(got, "[Yes]") {
+		t.Errorf("expected the focused button in brackets, got %q", got)
+	}
+	if got := ft.Focused.BlurredButton.Render("No"); strings.Contains(got, "[") || lipgloss.Width(got) != lipgloss.Width(ft.Focused.FocusedButton.Render("No")) {
+		t.Errorf("expected the other button as wide, without brackets, got %q", got)
+	}
+}
@@ -0,0 +1,55 @@
+package views
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/GVPproj/termsheet/utils"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	breadcrumbStyle = lipgloss.NewStyle().
+			PaddingLeft(1)
+
+	currentCrumbStyle = lipgloss.NewStyle().
+				Bold(true)
+)
+
+// breadcrumbSeparator goes between the views in the breadcrumbs
+const breadcrumbSeparator = " › "
+
+// RenderBreadcrumbs renders the trail of views leading to the current one, which comes last.
+// On narrow terminals the oldest views are replaced by an ellipsis to make room for the latest.
+func RenderBreadcrumbs(crumbs []string, width int) string {
+	if len(crumbs) == 0 {
+		return ""
+	}
+	current := crumbs[len(crumbs)-1]
+	trail := crumbs[:len(crumbs)-1]
+
+	if width > 0 {
+		available := width - breadcrumbStyle.GetHorizontalPadding()
+		current = utils.TruncateText(current, available)
+
+		dropped := 0
+		shown := func() []string {
+			if dropped == 0 {
+				return trail
+			}
+			return append([]string{"…"}, trail[dropped:]...)
+		}
+		for dropped < len(trail) && utils.TextWidth(strings.Join(slices.Concat(shown(), []string{current}), breadcrumbSeparator)) > available {
+			dropped++
+		}
+		trail = shown()
+	}
+
+	var b strings.Builder
+	for _, crumb := range trail {
+		b.WriteString(crumb)
+		b.WriteString(breadcrumbSeparator)
+	}
+	b.WriteString(currentCrumbStyle.Render(current))
+	return breadcrumbStyle.Render(b.String())
+}
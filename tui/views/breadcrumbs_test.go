@@ -0,0 +1,29 @@
+package views
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestRenderBreadcrumbs(t *testing.T) {
+	crumbs := []string{"Menu", "Invoices", "INV-0042", "Edit"}
+
+	rendered := RenderBreadcrumbs(crumbs, 0)
+	if !strings.Contains(rendered, "Menu › Invoices › INV-0042 › Edit") {
+		t.Errorf("expected the whole trail, got %q", rendered)
+	}
+
+	// The oldest views give way on narrow terminals
+	rendered = RenderBreadcrumbs(crumbs, 24)
+	if !strings.Contains(rendered, "… › INV-0042 › Edit") || strings.Contains(rendered, "Menu") {
+		t.Errorf("expected the trail shortened from the start, got %q", rendered)
+	}
+	if lipgloss.Width(rendered) > 24 {
+		t.Errorf("expected the trail to fit the terminal, got %q", rendered)
+	}
+	if crumbs[len(crumbs)-1] != "Edit" || crumbs[0] != "Menu" {
+		t.Errorf("expected the crumbs left as they were, got %q", crumbs)
+	}
+}
@@ -0,0 +1,58 @@
+package views
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/GVPproj/termsheet/models"
+	"github.com/charmbracelet/huh"
+)
+
+// CreateClientContactsForm creates a form listing a client's contacts while it is being edited.
+// Contacts are identified by their index in contacts since new ones have no ID yet.
+func CreateClientContactsForm(selection *string, clientName string, contacts []models.Contact) *huh.Form {
+	options := make([]huh.Option[string], 0, len(contacts)+2)
+
+	for i, c := range contacts {
+		label := c.Name
+		if c.Email != "" {
+			label += fmt.Sprintf(" <%s>", c.Email)
+		}
+		if c.Billing {
+			label += " [billing]"
+		}
+		options = append(options, huh.NewOption(label, strconv.Itoa(i)))
+	}
+
+	options = append(options,
+		huh.NewOption("+ Add Contact", "ADD_CONTACT"),
+		huh.NewOption("✓ Save Client", "SAVE"),
+	)
+
+	title := fmt.Sprintf("Contacts for %s", clientName)
+	if len(contacts) == 0 {
+		title += "\n\nNo contacts yet"
+	}
+
+	return huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title(title).
+				Options(options...).
+				Value(selection),
+		),
+	).WithTheme(GetMenuTheme())
+}
+
+// RenderClientContacts renders the client contacts list
+func RenderClientContacts(form *huh.Form, width int) string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Client Contacts"))
+	b.WriteString("\n\n")
+
+	b.WriteString(form.View())
+
+	b.WriteString(helpStyle.Render("\n\n" + helpLine(ClientContactsKeys()...)))
+	return container(defaultWidth, width).Render(b.String())
+}
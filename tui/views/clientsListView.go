@@ -5,54 +5,12 @@ import (
 	"strings"
 
 	"github.com/GVPproj/termsheet/storage"
+	"github.com/GVPproj/termsheet/tui/keys"
 	"github.com/charmbracelet/huh"
 )
 
-// CreateClientListForm creates a form for selecting or creating providers
-func CreateClientListForm(selection *string) (*huh.Form, error) {
-	return CreateClientListFormWithError(selection, "")
-}
-
-// CreateClientListFormWithError creates a form with an optional error message
-func CreateClientListFormWithError(selection *string, errorMsg string) (*huh.Form, error) {
-	clients, err := storage.ListClients()
-	if err != nil {
-		return nil, err
-	}
-
-	// Build options for the select form
-	options := make([]huh.Option[string], 0)
-
-	// Add existing clients
-	for _, c := range clients {
-		label := c.Name
-		if c.Email != nil {
-			label += fmt.Sprintf(" (%s)", *c.Email)
-		}
-		options = append(options, huh.NewOption(label, c.ID))
-	}
-
-	// Add "Create New Provider" option
-	options = append(options, huh.NewOption("+ Create New Client", "CREATE_NEW"))
-
-	title := "Select a client or create a new one"
-	if errorMsg != "" {
-		title = "⚠️  " + errorMsg + "\n\nSelect a client or create a new one"
-	}
-
-	form := huh.NewForm(
-		huh.NewGroup(
-			huh.NewSelect[string]().
-				Title(title).
-				Options(options...).
-				Value(selection),
-		),
-	).WithTheme(GetMenuTheme())
-
-	return form, nil
-}
-
-func RenderClients(form *huh.Form) string {
+// RenderClients renders the client create, edit and filter views with the given form
+func RenderClients(form *huh.Form, width int) string {
 	var b strings.Builder
 	b.WriteString(titleStyle.Render("Clients"))
 	b.WriteString("\n\n")
@@ -61,8 +19,8 @@ func RenderClients(form *huh.Form) string {
 	b.WriteString(form.View())
 
 	// Render help text
-	b.WriteString(helpStyle.Render("\n\nPress 'd' to delete | ESC to return to menu"))
-	return containerStyle.Render(b.String())
+	b.WriteString(helpStyle.Render("\n\n" + helpLine(keys.Current().Back)))
+	return container(defaultWidth, width).Render(b.String())
 }
 
 func DeleteSelectedClient(clientID string) (int, error) {
@@ -0,0 +1,128 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GVPproj/termsheet/models"
+	"github.com/GVPproj/termsheet/storage"
+	"github.com/GVPproj/termsheet/tui/components/table"
+	"github.com/GVPproj/termsheet/tui/keys"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ProviderColumns are the columns of the provider list
+// On narrow terminals the name and email shrink, then the phone and email are hidden.
+var ProviderColumns = []table.Column{
+	{Title: "Name", Width: 24, Flex: true},
+	{Title: "Email", Width: 24, Flex: true, Optional: true},
+	{Title: "Phone", Width: 14, Optional: true},
+}
+
+// ClientColumns are the columns of the client list: those of the provider list and the
+// client's tags, which are hidden first on narrow terminals
+var ClientColumns = append(ProviderColumns[:len(ProviderColumns):len(ProviderColumns)],
+	table.Column{Title: "Tags", Width: 16, Optional: true},
+)
+
+// EntityList is what the client and provider list views show
+type EntityList struct {
+	Table  table.Model
+	Filter storage.EntityFilter
+}
+
+// NewEntityTable creates an empty table for the clients or providers, as named by tableName
+func NewEntityTable(tableName string) table.Model {
+	columns := ProviderColumns
+	if tableName == "client" {
+		columns = ClientColumns
+	}
+	km := keys.Current()
+	t := table.New(columns)
+	t.Empty = fmt.Sprintf("No %ss yet; press %s to create one", tableName, km.New.Help().Key)
+	t.KeyMap = km.Table()
+	return t
+}
+
+// LoadEntityRows makes t page through the clients or providers matching filter, in the order
+// they were created, keeping the selected one if it is still listed
+func LoadEntityRows(t *table.Model, tableName string, filter storage.EntityFilter) error {
+	return t.SetSource(entitySource{tableName: tableName, filter: filter})
+}
+
+// entitySource pages through the clients or providers matching a filter, so the list
+// never loads more than a few pages of them however many there are
+type entitySource struct {
+	tableName string
+	filter    storage.EntityFilter
+}
+
+func (s entitySource) Count() (int, error) {
+	return storage.CountEntities(s.tableName, s.filter)
+}
+
+func (s entitySource) Page(id string, backward bool, limit int) ([]table.Row, error) {
+	entities, err := storage.ListEntitiesPage(s.tableName, s.filter, storage.PageCursor{ID: id, Backward: backward}, limit)
+	if err != nil {
+		return nil, err
+	}
+	return EntityTableRows(entities, s.tableName == "client"), nil
+}
+
+// EntityTableRows turns clients or providers into table rows identified by their ID,
+// with a tags column when withTags is set
+func EntityTableRows(entities []models.Entity, withTags bool) []table.Row {
+	rows := make([]table.Row, 0, len(entities))
+	for _, e := range entities {
+		name := e.Name
+		if e.Archived {
+			name += " [archived]"
+		}
+		cells := []string{name, optional(e.Email), optional(e.Phone)}
+		if withTags {
+			cells = append(cells, models.TagChips(e.Tags))
+		}
+		rows = append(rows, table.Row{ID: e.ID, Cells: cells})
+	}
+	return rows
+}
+
+// optional returns the value of an optional field, or "" if it is not set
+func optional(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// RenderClientList renders the client table with the active filters above it
+func RenderClientList(list EntityList) string {
+	return renderEntityList("Clients", list, ClientListKeys())
+}
+
+// RenderProviderList renders the provider table with the active filters above it
+func RenderProviderList(list EntityList) string {
+	return renderEntityList("Providers", list, ProviderListKeys())
+}
+
+// renderEntityList renders a client or provider list with the given title and keys
+func renderEntityList(title string, list EntityList, listKeys []key.Binding) string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render(title))
+	b.WriteString("\n\n")
+
+	if bar := entityFilterBar(list.Filter); bar != "" {
+		b.WriteString(bar)
+		b.WriteString("\n\n")
+	}
+
+	tableView := list.Table.View()
+	b.WriteString(tableView)
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Width(lipgloss.Width(tableView)).Render(helpLine(append(listKeys, keys.Current().Help)...)))
+
+	// The table sets the container's width
+	return containerStyle.UnsetWidth().Render(b.String())
+}
@@ -0,0 +1,36 @@
+package views
+
+import (
+	"testing"
+
+	"github.com/GVPproj/termsheet/models"
+)
+
+func TestEntityTableRows(t *testing.T) {
+	email := "billing@globex.test"
+	entities := []models.Entity{
+		{ID: "c1", Name: "Globex", Email: &email, Tags: []string{"agency"}},
+		{ID: "c2", Name: "Initech", Archived: true},
+	}
+
+	clients := EntityTableRows(entities, true)
+	if len(clients) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(clients))
+	}
+	for _, row := range clients {
+		if len(row.Cells) != len(ClientColumns) {
+			t.Errorf("row %s: expected %d cells, got %d", row.ID, len(ClientColumns), len(row.Cells))
+		}
+	}
+	if got := clients[0].Cells; got[0] != "Globex" || got[1] != email || got[2] != "" || got[3] != "#agency" {
+		t.Errorf("unexpected cells for Globex: %q", got)
+	}
+	if name := clients[1].Cells[0]; name != "Initech [archived]" {
+		t.Errorf("expected archived clients to be marked, got %q", name)
+	}
+
+	providers := EntityTableRows(entities, false)
+	if len(providers[0].Cells) != len(ProviderColumns) {
+		t.Errorf("expected %d cells for a provider, got %d", len(ProviderColumns), len(providers[0].Cells))
+	}
+}
@@ -0,0 +1,82 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GVPproj/termsheet/models"
+	"github.com/GVPproj/termsheet/storage"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// filterBarStyle renders the summary of the filters applied to a list
+var filterBarStyle = lipgloss.NewStyle()
+
+// invoiceFilterBar describes the active invoice filters, or returns "" when none are set
+func invoiceFilterBar(filter storage.InvoiceFilter) string {
+	var parts []string
+	if filter.Search != "" {
+		parts = append(parts, fmt.Sprintf("%q", filter.Search))
+	}
+	if filter.Status != "" {
+		parts = append(parts, string(filter.Status))
+	}
+	if filter.ProviderID != "" {
+		parts = append(parts, "from "+entityName("provider", filter.ProviderID))
+	}
+	if filter.ClientID != "" {
+		parts = append(parts, "to "+entityName("client", filter.ClientID))
+	}
+
+	switch {
+	case !filter.From.IsZero() && !filter.To.IsZero():
+		parts = append(parts, fmt.Sprintf("created %s – %s", filter.From.Format("2006-01-02"), filter.To.Format("2006-01-02")))
+	case !filter.From.IsZero():
+		parts = append(parts, "created since "+filter.From.Format("2006-01-02"))
+	case !filter.To.IsZero():
+		parts = append(parts, "created until "+filter.To.Format("2006-01-02"))
+	}
+
+	switch {
+	case filter.MinTotal != nil && filter.MaxTotal != nil:
+		parts = append(parts, fmt.Sprintf("total $%.2f – $%.2f", *filter.MinTotal, *filter.MaxTotal))
+	case filter.MinTotal != nil:
+		parts = append(parts, fmt.Sprintf("total ≥ $%.2f", *filter.MinTotal))
+	case filter.MaxTotal != nil:
+		parts = append(parts, fmt.Sprintf("total ≤ $%.2f", *filter.MaxTotal))
+	}
+
+	if len(filter.Tags) > 0 {
+		parts = append(parts, models.TagChips(filter.Tags))
+	}
+	return renderFilterBar(parts)
+}
+
+// entityFilterBar describes the active client or provider filters, or returns "" when none are set
+func entityFilterBar(filter storage.EntityFilter) string {
+	var parts []string
+	if filter.Search != "" {
+		parts = append(parts, fmt.Sprintf("%q", filter.Search))
+	}
+	if len(filter.Tags) > 0 {
+		parts = append(parts, models.TagChips(filter.Tags))
+	}
+	return renderFilterBar(parts)
+}
+
+// renderFilterBar joins filter descriptions into a single line
+func renderFilterBar(parts []string) string {
+	if len(parts) == 0 {
+		return ""
+	}
+	return filterBarStyle.Render("Filter: " + strings.Join(parts, " · "))
+}
+
+// entityName looks up a provider or client name for display, falling back to its ID
+func entityName(tableName, id string) string {
+	e, err := storage.GetEntity(tableName, id)
+	if err != nil {
+		return id
+	}
+	return e.Name
+}
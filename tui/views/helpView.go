@@ -0,0 +1,115 @@
+package views
+
+import (
+	"strings"
+
+	"github.com/GVPproj/termsheet/tui/keys"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// Each list of keys below is what one view does with them; the line of help under
+// the view shows them, and so does the help overlay along with the global keys.
+
+// selectKey picks the highlighted option in a form, which the forms handle themselves
+var selectKey = key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "open"))
+
+// sortKeys sort the invoice list by the matching column
+var sortKeys = key.NewBinding(key.WithKeys("1", "2", "3", "4", "5", "6", "7"), key.WithHelp("1-7", "sort by column"))
+
+// ProviderListKeys returns the keys of the provider list
+func ProviderListKeys() []key.Binding {
+	km := keys.Current()
+	return []key.Binding{
+		keys.Hint(km.Open, "edit a provider"), keys.Hint(km.New, "create a provider"),
+		km.Delete, km.Archive, km.ShowArchived, keys.Hint(km.Filter, "search"), km.Back,
+	}
+}
+
+// ClientListKeys returns the keys of the client list
+func ClientListKeys() []key.Binding {
+	km := keys.Current()
+	return []key.Binding{
+		keys.Hint(km.Open, "edit a client"), keys.Hint(km.New, "create a client"),
+		km.Delete, km.Archive, km.ShowArchived, keys.Hint(km.Filter, "search"), km.TagFilter, km.Back,
+	}
+}
+
+// InvoiceListKeys returns the keys of the invoice list
+func InvoiceListKeys() []key.Binding {
+	return invoiceListKeys(keys.Current().Trash)
+}
+
+// invoiceListKeys returns the keys of the invoice list with the given trash key,
+// which the list describes with the number of invoices in the trash
+func invoiceListKeys(trash key.Binding) []key.Binding {
+	km := keys.Current()
+	return []key.Binding{
+		km.Open, km.View, km.Edit, km.Issue, km.MarkPaid, keys.Hint(km.New, "create an invoice"),
+		sortKeys, km.Delete, km.Undo, km.Filter, km.TagFilter, trash, km.Preview, km.Back,
+	}
+}
+
+// InvoiceItemsKeys returns the keys of the item editor of an invoice
+func InvoiceItemsKeys() []key.Binding {
+	km := keys.Current()
+	return []key.Binding{
+		keys.Hint(km.New, "add an item"), keys.Hint(km.Open, "edit an item"), keys.Hint(km.Delete, "remove an item"),
+		km.MoveUp, km.MoveDown, keys.Hint(km.Continue, "continue to the details"), keys.Hint(km.Back, "discard changes"),
+	}
+}
+
+// ClientContactsKeys returns the keys of the contacts list in the client form
+func ClientContactsKeys() []key.Binding {
+	km := keys.Current()
+	return []key.Binding{keys.Hint(km.Delete, "remove a contact"), keys.Hint(km.Back, "discard changes")}
+}
+
+// SearchKeys returns the keys of the global search
+func SearchKeys() []key.Binding {
+	km := keys.Current()
+	return []key.Binding{selectKey, km.EditResult, km.Back}
+}
+
+// helpLine describes what the given keys do, for the line of help under a view.
+// Keys the user unbound are left out.
+func helpLine(bindings ...key.Binding) string {
+	parts := make([]string, 0, len(bindings))
+	for _, b := range bindings {
+		if b.Enabled() {
+			parts = append(parts, b.Help().Key+" to "+b.Help().Desc)
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+// RenderHelp renders the help overlay: the keys of the current view, then the keys
+// for moving through lists, then those that work everywhere
+func RenderHelp(viewKeys []key.Binding, width int) string {
+	km := keys.Current()
+	groups := []struct {
+		title    string
+		bindings []key.Binding
+	}{
+		{"This view", viewKeys},
+		{"Moving", []key.Binding{km.Up, km.Down, km.PageUp, km.PageDown, km.Top, km.Bottom}},
+		{"Everywhere", km.Global()},
+	}
+
+	h := help.New()
+	h.Width = contentWidth(defaultWidth, width)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Keys"))
+	for _, group := range groups {
+		if len(group.bindings) == 0 {
+			continue
+		}
+		b.WriteString("\n")
+		b.WriteString(sectionTitleStyle.Render(group.title))
+		b.WriteString("\n")
+		b.WriteString(h.FullHelpView([][]key.Binding{group.bindings}))
+	}
+	b.WriteString(helpStyle.Render("\n\nPress any key to close"))
+	return container(defaultWidth, width).Render(b.String())
+}
@@ -3,6 +3,8 @@ package views
 import (
 	"strings"
 
+	"github.com/GVPproj/termsheet/models"
+	"github.com/GVPproj/termsheet/tui/keys"
 	"github.com/charmbracelet/huh"
 )
 
@@ -10,30 +12,42 @@ import (
 type InvoiceActionOption string
 
 const (
-	ActionView   InvoiceActionOption = "view"
-	ActionEdit   InvoiceActionOption = "edit"
-	ActionPDF    InvoiceActionOption = "pdf"
-	ActionCancel InvoiceActionOption = "cancel"
+	ActionView      InvoiceActionOption = "view"
+	ActionEdit      InvoiceActionOption = "edit"
+	ActionDuplicate InvoiceActionOption = "duplicate"
+	ActionIssue     InvoiceActionOption = "issue"
+	ActionCancel    InvoiceActionOption = "cancel"
 )
 
 // CreateInvoiceActionForm creates a form for selecting an action on an invoice
 func CreateInvoiceActionForm(selection *string) *huh.Form {
+	return CreateInvoiceActionFormWithData(selection, nil)
+}
+
+// CreateInvoiceActionFormWithData creates the action form for a specific invoice,
+// offering to issue it when it is still a draft
+func CreateInvoiceActionFormWithData(selection *string, data *models.InvoiceData) *huh.Form {
+	options := []huh.Option[string]{
+		huh.NewOption("View Invoice", string(ActionView)),
+		huh.NewOption("Edit Invoice", string(ActionEdit)),
+		huh.NewOption("Duplicate Invoice (as a new draft)", string(ActionDuplicate)),
+	}
+	if data != nil && data.IsDraft() {
+		options = append(options, huh.NewOption("Issue Invoice (assign number)", string(ActionIssue)))
+	}
+
 	return huh.NewForm(
 		huh.NewGroup(
 			huh.NewSelect[string]().
 				Title("What would you like to do?").
-				Options(
-					huh.NewOption("View Invoice", string(ActionView)),
-					huh.NewOption("Edit Invoice", string(ActionEdit)),
-					huh.NewOption("Output PDF", string(ActionPDF)),
-				).
+				Options(options...).
 				Value(selection),
 		),
 	).WithTheme(GetMenuTheme())
 }
 
 // RenderInvoiceActionMenu renders the invoice action menu view
-func RenderInvoiceActionMenu(form *huh.Form) string {
+func RenderInvoiceActionMenu(form *huh.Form, width int) string {
 	var b strings.Builder
 
 	// Render title
@@ -44,8 +58,8 @@ func RenderInvoiceActionMenu(form *huh.Form) string {
 	b.WriteString(form.View())
 
 	// Render help text
-	b.WriteString(helpStyle.Render("\n\nESC to return to invoice list"))
+	b.WriteString(helpStyle.Render("\n\n" + helpLine(keys.Hint(keys.Current().Back, "return to invoice list"))))
 
 	// Wrap in container
-	return containerStyle.Render(b.String())
+	return container(defaultWidth, width).Render(b.String())
 }
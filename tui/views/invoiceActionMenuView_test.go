@@ -23,7 +23,7 @@ func TestRenderInvoiceActionMenu(t *testing.T) {
 	var selection string
 	form := CreateInvoiceActionForm(&selection)
 
-	rendered := RenderInvoiceActionMenu(form)
+	rendered := RenderInvoiceActionMenu(form, 0)
 
 	if rendered == "" {
 		t.Error("RenderInvoiceActionMenu should return non-empty string")
@@ -34,7 +34,7 @@ func TestRenderInvoiceActionMenu(t *testing.T) {
 		t.Error("Rendered output should contain title 'Invoice Actions'")
 	}
 
-	if !contains(rendered, "ESC to return to invoice list") {
+	if !contains(rendered, "esc to return to invoice list") {
 		t.Error("Rendered output should contain help text")
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/GVPproj/termsheet/models"
+	"github.com/GVPproj/termsheet/tui/keys"
 	"github.com/GVPproj/termsheet/utils"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -13,69 +14,96 @@ var (
 	// Table styles
 	tableHeaderStyle = lipgloss.NewStyle().
 				Bold(true).
-				Foreground(lipgloss.Color("#61AFEF")).
 				Padding(0, 1)
 
 	tableCellStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#98C379")).
 			Padding(0, 1)
 
 	tableRowStyle = lipgloss.NewStyle().
 			BorderStyle(lipgloss.NormalBorder()).
-			BorderBottom(true).
-			BorderForeground(subtle)
+			BorderBottom(true)
 
 	sectionTitleStyle = lipgloss.NewStyle().
 				Bold(true).
-				Foreground(lipgloss.Color("#D33682")).
 				MarginTop(1).
 				MarginBottom(1)
 
 	labelStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#61AFEF"))
+			Bold(true)
 
-	valueStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#98C379"))
+	valueStyle = lipgloss.NewStyle()
 )
 
 // RenderInvoiceView renders a read-only view of an invoice
-func RenderInvoiceView(data *models.InvoiceData) string {
+func RenderInvoiceView(data *models.InvoiceData, width int) string {
+	var b strings.Builder
+
+	b.WriteString(renderInvoiceDetails(data, contentWidth(invoiceViewWidth, width)))
+
+	// Help text
+	b.WriteString(helpStyle.Render("\n\n\n" + helpLine(keys.Current().Back, keys.Current().Help)))
+
+	// Wrap in container
+	return container(invoiceViewWidth, width).Render(b.String())
+}
+
+// renderInvoiceDetails renders everything on an invoice in lines of at most width cells
+func renderInvoiceDetails(data *models.InvoiceData, width int) string {
 	var b strings.Builder
 
 	// Title
-	b.WriteString(titleStyle.Render(fmt.Sprintf("Invoice #%d", data.InvoiceID)))
+	title := "Invoice " + data.DisplayNumber()
+	if data.IsDraft() {
+		title += " (draft)"
+	}
+	b.WriteString(titleStyle.Render(title))
 	b.WriteString("\n\n")
 
-	// Date and status
+	// Date and status; issued invoices are dated by their issue date
 	dateStr := data.DateCreated.Format("2006-01-02")
+	if data.IssuedAt != nil {
+		dateStr = data.IssuedAt.Format("2006-01-02")
+	}
 	status := "Unpaid"
 	if data.Paid {
 		status = "Paid"
 	}
-	b.WriteString(fmt.Sprintf("%s %s  |  %s %s\n\n",
+	b.WriteString(fmt.Sprintf("%s %s  |  %s %s",
 		labelStyle.Render("Date:"),
 		valueStyle.Render(dateStr),
 		labelStyle.Render("Status:"),
 		valueStyle.Render(status),
 	))
+	if data.DueDate != nil {
+		b.WriteString(fmt.Sprintf("  |  %s %s", labelStyle.Render("Due:"), valueStyle.Render(data.DueDate.Format("2006-01-02"))))
+	}
+	b.WriteString("\n\n")
+
+	// Invoice custom fields, e.g. a PO number
+	if fields := renderCustomFields(data.CustomFieldsFor(models.EntityInvoice), width); fields != "" {
+		b.WriteString(fields)
+		b.WriteString("\n")
+	}
 
 	// Provider section
 	b.WriteString(sectionTitleStyle.Render("Provider"))
 	b.WriteString("\n")
-	b.WriteString(renderEntity(&data.Provider))
+	b.WriteString(renderEntity(&data.Provider, width))
+	b.WriteString(renderProviderProfile(&data.ProviderProfile, width))
+	b.WriteString(renderCustomFields(data.CustomFieldsFor(models.EntityProvider), width))
 	b.WriteString("\n\n")
 
 	// Client section
 	b.WriteString(sectionTitleStyle.Render("Client"))
 	b.WriteString("\n")
-	b.WriteString(renderEntity(&data.Client))
+	b.WriteString(renderClient(&data.Client, &data.ClientDetails, width))
+	b.WriteString(renderCustomFields(data.CustomFieldsFor(models.EntityClient), width))
 	b.WriteString("\n\n")
 
 	// Items section
 	b.WriteString(sectionTitleStyle.Render("Items"))
 	b.WriteString("\n")
-	b.WriteString(renderItemsTable(data.Items))
+	b.WriteString(renderItemsTable(data.Items, width))
 	b.WriteString("\n\n")
 
 	// Total
@@ -83,74 +111,230 @@ func RenderInvoiceView(data *models.InvoiceData) string {
 	b.WriteString(labelStyle.Render("Total: "))
 	b.WriteString(valueStyle.Render(fmt.Sprintf("$%.2f", total)))
 
-	// Help text
-	b.WriteString(helpStyle.Render("\n\n\nESC to return"))
+	// Payment section
+	if data.ProviderProfile.HasPaymentDetails() {
+		b.WriteString("\n")
+		b.WriteString(sectionTitleStyle.Render("Payment"))
+		b.WriteString("\n")
+		b.WriteString(renderPaymentDetails(&data.ProviderProfile, width))
+	}
 
-	// Wrap in container
-	return containerStyle.Render(b.String())
+	// Terms section
+	if data.Terms != "" {
+		b.WriteString("\n")
+		b.WriteString(sectionTitleStyle.Render("Terms"))
+		b.WriteString("\n")
+		b.WriteString(valueStyle.Render(wrap(data.Terms, width)))
+	}
+
+	// Notes section
+	if data.Notes != "" {
+		b.WriteString("\n")
+		b.WriteString(sectionTitleStyle.Render("Notes"))
+		b.WriteString("\n")
+		b.WriteString(valueStyle.Render(wrap(data.Notes, width)))
+	}
+
+	return b.String()
+}
+
+// renderField renders a labelled value, wrapping the value beside its label,
+// or below it when width is too narrow for both
+func renderField(label, value string, width int) string {
+	renderedLabel := labelStyle.Render(label)
+
+	if width < narrowWidth {
+		return renderedLabel + "\n" + valueStyle.Render(wrap(value, width)) + "\n"
+	}
+
+	valueWidth := width - lipgloss.Width(renderedLabel) - 1
+	if utils.TextWidth(value) <= valueWidth {
+		return renderedLabel + " " + valueStyle.Render(value) + "\n"
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, renderedLabel, " ", valueStyle.Render(wrap(value, valueWidth))) + "\n"
 }
 
 // renderEntity renders provider or client information
-func renderEntity(entity *models.Entity) string {
+func renderEntity(entity *models.Entity, width int) string {
 	var b strings.Builder
 
-	b.WriteString(fmt.Sprintf("%s %s\n",
-		labelStyle.Render("Name:"),
-		valueStyle.Render(entity.Name),
-	))
+	b.WriteString(renderField("Name:", entity.Name, width))
 
 	if entity.Address != nil && *entity.Address != "" {
-		b.WriteString(fmt.Sprintf("%s %s\n",
-			labelStyle.Render("Address:"),
-			valueStyle.Render(*entity.Address),
-		))
+		b.WriteString(renderField("Address:", *entity.Address, width))
 	}
 
 	if entity.Email != nil && *entity.Email != "" {
-		b.WriteString(fmt.Sprintf("%s %s\n",
-			labelStyle.Render("Email:"),
-			valueStyle.Render(*entity.Email),
-		))
+		b.WriteString(renderField("Email:", *entity.Email, width))
 	}
 
 	if entity.Phone != nil && *entity.Phone != "" {
-		b.WriteString(fmt.Sprintf("%s %s\n",
-			labelStyle.Render("Phone:"),
-			valueStyle.Render(*entity.Phone),
+		b.WriteString(renderField("Phone:", *entity.Phone, width))
+	}
+
+	return b.String()
+}
+
+// renderClient renders the client with its structured addresses and billing contact.
+// Clients without structured details fall back to renderEntity.
+func renderClient(client *models.Entity, details *models.ClientDetails, width int) string {
+	if details.BillingAddress.IsEmpty() && len(details.Contacts) == 0 {
+		return renderEntity(client, width)
+	}
+
+	var b strings.Builder
+
+	b.WriteString(renderField("Name:", client.Name, width))
+
+	if contact := details.BillingContact(); contact != nil {
+		b.WriteString(renderField("Attn:", contact.Name, width))
+	}
+
+	if !details.BillingAddress.IsEmpty() {
+		b.WriteString(renderAddress("Billing Address:", details.BillingAddress))
+	}
+
+	if !details.ShippingAddress.IsEmpty() {
+		b.WriteString(renderAddress("Shipping Address:", details.ShippingAddress))
+	}
+
+	if email := details.InvoiceEmail(*client); email != "" {
+		b.WriteString(renderField("Email:", email, width))
+	}
+
+	if client.Phone != nil && *client.Phone != "" {
+		b.WriteString(renderField("Phone:", *client.Phone, width))
+	}
+
+	return b.String()
+}
+
+// renderCustomFields renders custom field values as labelled lines
+func renderCustomFields(values []models.CustomFieldValue, width int) string {
+	var b strings.Builder
+	for _, v := range values {
+		b.WriteString(renderField(v.Field.Label+":", v.Value, width))
+	}
+	return b.String()
+}
+
+// renderAddress renders a labelled address with one line per address line
+func renderAddress(label string, address models.Address) string {
+	var b strings.Builder
+	b.WriteString(labelStyle.Render(label))
+	b.WriteString("\n")
+	for _, line := range address.Lines() {
+		b.WriteString(valueStyle.Render("  " + line))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderProviderProfile renders the provider's business details below its contact details
+func renderProviderProfile(profile *models.ProviderProfile, width int) string {
+	var b strings.Builder
+
+	fields := []struct {
+		label string
+		value string
+	}{
+		{"Registered Name:", profile.LegalName},
+		{"Tax / VAT No.:", profile.TaxID},
+		{"Company No.:", profile.CompanyNumber},
+		{"Website:", profile.Website},
+		{"Logo:", profile.LogoPath},
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		b.WriteString(renderField(f.label, f.value, width))
+	}
+
+	return b.String()
+}
+
+// renderPaymentDetails renders the bank details and payment instructions
+func renderPaymentDetails(profile *models.ProviderProfile, width int) string {
+	var b strings.Builder
+
+	if profile.IBAN != "" {
+		b.WriteString(renderField("IBAN:", formatIBAN(profile.IBAN), width))
+	}
+
+	if profile.BIC != "" {
+		b.WriteString(renderField("BIC:", profile.BIC, width))
+	}
+
+	if profile.AccountDetails != "" {
+		b.WriteString(fmt.Sprintf("%s\n%s\n",
+			labelStyle.Render("Account:"),
+			valueStyle.Render(wrap(profile.AccountDetails, width)),
 		))
 	}
 
+	if profile.PaymentInstructions != "" {
+		b.WriteString("\n")
+		b.WriteString(valueStyle.Render(wrap(profile.PaymentInstructions, width)))
+		b.WriteString("\n")
+	}
+
 	return b.String()
 }
 
-// renderItemsTable renders the invoice items in a table format
-func renderItemsTable(items []models.InvoiceItem) string {
+// formatIBAN groups an IBAN into blocks of four characters for readability
+func formatIBAN(iban string) string {
+	var groups []string
+	for len(iban) > 4 {
+		groups = append(groups, iban[:4])
+		iban = iban[4:]
+	}
+	groups = append(groups, iban)
+	return strings.Join(groups, " ")
+}
+
+// Widths of the items table's number columns; the item column takes the rest of the width
+const (
+	quantityColumnWidth = 12
+	costColumnWidth     = 15
+	totalColumnWidth    = 15
+	minItemColumnWidth  = 16
+)
+
+// renderItemsTable renders the invoice items in a table width cells wide, wrapping long item names.
+// When the item column would be too narrow, each item is stacked over its amounts instead.
+func renderItemsTable(items []models.InvoiceItem, width int) string {
 	if len(items) == 0 {
 		return valueStyle.Render("No items")
 	}
 
+	itemWidth := width - quantityColumnWidth - costColumnWidth - totalColumnWidth
+	if itemWidth < minItemColumnWidth {
+		return renderStackedItems(items, width)
+	}
+
 	var b strings.Builder
 
 	// Header row
 	headerRow := lipgloss.JoinHorizontal(
 		lipgloss.Left,
-		tableHeaderStyle.Width(20).Render("Item"),
-		tableHeaderStyle.Width(12).Render("Quantity"),
-		tableHeaderStyle.Width(15).Render("Cost/Unit"),
-		tableHeaderStyle.Width(15).Render("Total"),
+		tableHeaderStyle.Width(itemWidth).Render("Item"),
+		tableHeaderStyle.Width(quantityColumnWidth).Render("Quantity"),
+		tableHeaderStyle.Width(costColumnWidth).Render("Cost/Unit"),
+		tableHeaderStyle.Width(totalColumnWidth).Render("Total"),
 	)
 	b.WriteString(headerRow)
 	b.WriteString("\n")
 
-	// Data rows
+	// Data rows; the amounts line up with the first line of a wrapped item name
 	for _, item := range items {
 		itemTotal := item.Amount * item.CostPerUnit
 		row := lipgloss.JoinHorizontal(
-			lipgloss.Left,
-			tableCellStyle.Width(20).Render(utils.TruncateText(item.ItemName, 18)),
-			tableCellStyle.Width(12).Render(fmt.Sprintf("%.2f", item.Amount)),
-			tableCellStyle.Width(15).Render(fmt.Sprintf("$%.2f", item.CostPerUnit)),
-			tableCellStyle.Width(15).Render(fmt.Sprintf("$%.2f", itemTotal)),
+			lipgloss.Top,
+			tableCellStyle.Width(itemWidth).Render(wrap(item.ItemName, itemWidth-tableCellStyle.GetHorizontalPadding())),
+			tableCellStyle.Width(quantityColumnWidth).Render(fmt.Sprintf("%.2f", item.Amount)),
+			tableCellStyle.Width(costColumnWidth).Render(fmt.Sprintf("$%.2f", item.CostPerUnit)),
+			tableCellStyle.Width(totalColumnWidth).Render(fmt.Sprintf("$%.2f", itemTotal)),
 		)
 		b.WriteString(row)
 		b.WriteString("\n")
@@ -159,6 +343,20 @@ func renderItemsTable(items []models.InvoiceItem) string {
 	return b.String()
 }
 
+// renderStackedItems renders each item's name above its quantity, cost and total,
+// where there is no room for the items table
+func renderStackedItems(items []models.InvoiceItem, width int) string {
+	var b strings.Builder
+	for _, item := range items {
+		b.WriteString(labelStyle.Render(wrap(item.ItemName, width)))
+		b.WriteString("\n")
+		b.WriteString(valueStyle.Render(wrap(fmt.Sprintf("  %.2f × $%.2f = $%.2f",
+			item.Amount, item.CostPerUnit, item.Amount*item.CostPerUnit), width)))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
 // calculateTotal calculates the total cost of all items
 func calculateTotal(items []models.InvoiceItem) float64 {
 	total := 0.0
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/GVPproj/termsheet/models"
+	"github.com/charmbracelet/lipgloss"
 )
 
 func TestRenderInvoiceView(t *testing.T) {
@@ -49,7 +50,7 @@ func TestRenderInvoiceView(t *testing.T) {
 		},
 	}
 
-	rendered := RenderInvoiceView(data)
+	rendered := RenderInvoiceView(data, 0)
 
 	// Basic checks
 	if rendered == "" {
@@ -103,7 +104,7 @@ func TestRenderInvoiceViewPaid(t *testing.T) {
 		Items: []models.InvoiceItem{},
 	}
 
-	rendered := RenderInvoiceView(data)
+	rendered := RenderInvoiceView(data, 0)
 
 	if !strings.Contains(rendered, "Paid") {
 		t.Error("Rendered output should contain 'Paid' status")
@@ -153,7 +154,7 @@ func TestRenderItemsTable(t *testing.T) {
 		{ItemName: "Test Item", Amount: 2, CostPerUnit: 50.0},
 	}
 
-	rendered := renderItemsTable(items)
+	rendered := renderItemsTable(items, contentWidth(invoiceViewWidth, 0))
 
 	if rendered == "" {
 		t.Error("renderItemsTable should return non-empty string")
@@ -164,10 +165,58 @@ func TestRenderItemsTable(t *testing.T) {
 	}
 }
 
+func TestRenderItemsTableWrapsLongNames(t *testing.T) {
+	items := []models.InvoiceItem{
+		{ItemName: "Website redesign including accessibility audit", Amount: 2, CostPerUnit: 50.0},
+	}
+
+	rendered := renderItemsTable(items, contentWidth(invoiceViewWidth, 100))
+	if !strings.Contains(rendered, "Quantity") {
+		t.Errorf("expected the items table on a wide terminal, got %q", rendered)
+	}
+	if !strings.Contains(rendered, "accessibility") || !strings.Contains(rendered, "audit") {
+		t.Errorf("expected the whole item name, wrapped rather than truncated, got %q", rendered)
+	}
+
+	// Too narrow for the columns; each item's amounts go below its name
+	rendered = renderItemsTable(items, contentWidth(invoiceViewWidth, 40))
+	if strings.Contains(rendered, "Quantity") {
+		t.Errorf("expected stacked items on a narrow terminal, got %q", rendered)
+	}
+	if !strings.Contains(rendered, "audit") || !strings.Contains(rendered, "2.00 × $50.00 = $100.00") {
+		t.Errorf("expected the item name and amounts, got %q", rendered)
+	}
+	for _, line := range strings.Split(rendered, "\n") {
+		if lipgloss.Width(line) > 40 {
+			t.Errorf("expected lines to fit the terminal, got %q", line)
+		}
+	}
+	if strings.HasPrefix(rendered, "\n") {
+		t.Errorf("expected stacked items to start with the first item, like the table, got %q", rendered)
+	}
+}
+
+func TestRenderItemsTableAlignsWideCharacters(t *testing.T) {
+	items := []models.InvoiceItem{
+		{ItemName: "翻訳サービス一式（年間契約）株式会社テスト向け", Amount: 1, CostPerUnit: 500.0},
+		{ItemName: "Café rénovation 👩‍💻", Amount: 2, CostPerUnit: 50.0},
+	}
+
+	lines := strings.Split(strings.TrimRight(renderItemsTable(items, contentWidth(invoiceViewWidth, 90)), "\n"), "\n")
+	for _, line := range lines {
+		if lipgloss.Width(line) != lipgloss.Width(lines[0]) {
+			t.Errorf("expected every line as wide as the header (%d), got %d: %q", lipgloss.Width(lines[0]), lipgloss.Width(line), line)
+		}
+	}
+	if len(lines) < 4 {
+		t.Errorf("expected the long name wrapped onto several lines, got %q", lines)
+	}
+}
+
 func TestRenderItemsTableEmpty(t *testing.T) {
 	items := []models.InvoiceItem{}
 
-	rendered := renderItemsTable(items)
+	rendered := renderItemsTable(items, contentWidth(invoiceViewWidth, 0))
 
 	if !strings.Contains(rendered, "No items") {
 		t.Error("Empty items should render 'No items' message")
@@ -187,7 +236,7 @@ func TestRenderEntity(t *testing.T) {
 		Phone:   &phone,
 	}
 
-	rendered := renderEntity(entity)
+	rendered := renderEntity(entity, contentWidth(invoiceViewWidth, 0))
 
 	if !strings.Contains(rendered, "Test Entity") {
 		t.Error("Rendered entity should contain name")
@@ -212,7 +261,7 @@ func TestRenderEntityMinimal(t *testing.T) {
 		Name: "Minimal Entity",
 	}
 
-	rendered := renderEntity(entity)
+	rendered := renderEntity(entity, contentWidth(invoiceViewWidth, 0))
 
 	if !strings.Contains(rendered, "Minimal Entity") {
 		t.Error("Rendered entity should contain name")
@@ -224,3 +273,115 @@ func TestRenderEntityMinimal(t *testing.T) {
 	}
 }
 
+func TestRenderInvoiceViewProviderProfile(t *testing.T) {
+	data := &models.InvoiceData{
+		InvoiceID:   1,
+		DateCreated: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		Provider:    models.Entity{ID: "p1", Name: "Acme"},
+		ProviderProfile: models.ProviderProfile{
+			LegalName:           "Acme Consulting Ltd",
+			TaxID:               "GB123456789",
+			IBAN:                "GB29NWBK60161331926819",
+			BIC:                 "NWBKGB2L",
+			PaymentInstructions: "Payment due within 30 days",
+		},
+		Client: models.Entity{ID: "c1", Name: "Client"},
+	}
+
+	rendered := RenderInvoiceView(data, 0)
+
+	for _, want := range []string{
+		"Acme Consulting Ltd",
+		"GB123456789",
+		"Payment",
+		"GB29 NWBK 6016 1331 9268 19",
+		"NWBKGB2L",
+		"Payment due within 30 days",
+	} {
+		if !strings.Contains(rendered, want) {
+			t.Errorf("rendered invoice should contain %q", want)
+		}
+	}
+}
+
+func TestRenderInvoiceViewWithoutPaymentDetails(t *testing.T) {
+	data := &models.InvoiceData{
+		InvoiceID:   1,
+		DateCreated: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		Provider:    models.Entity{ID: "p1", Name: "Acme"},
+		Client:      models.Entity{ID: "c1", Name: "Client"},
+	}
+
+	rendered := RenderInvoiceView(data, 0)
+
+	if strings.Contains(rendered, "IBAN:") || strings.Contains(rendered, "Registered Name:") {
+		t.Error("rendered invoice should not contain labels for an empty provider profile")
+	}
+}
+
+func TestRenderClientWithDetails(t *testing.T) {
+	clientEmail := "office@acme.example"
+	client := &models.Entity{ID: "c1", Name: "Acme", Email: &clientEmail}
+	details := &models.ClientDetails{
+		BillingAddress: models.Address{
+			Line1:      "1 Main St",
+			City:       "Springfield",
+			Region:     "IL",
+			PostalCode: "62701",
+			Country:    "United States",
+		},
+		ShippingAddress: models.Address{Line1: "2 Dock Rd", City: "Springfield"},
+		Contacts: []models.Contact{
+			{Name: "Ann", Email: "ann@acme.example"},
+			{Name: "Bob", Email: "bob@acme.example", Billing: true},
+		},
+	}
+
+	rendered := renderClient(client, details, contentWidth(invoiceViewWidth, 0))
+
+	for _, want := range []string{
+		"Attn:", "Bob",
+		"Billing Address:", "1 Main St", "Springfield, IL 62701", "United States",
+		"Shipping Address:", "2 Dock Rd",
+		"bob@acme.example",
+	} {
+		if !strings.Contains(rendered, want) {
+			t.Errorf("rendered client should contain %q", want)
+		}
+	}
+	if strings.Contains(rendered, "office@acme.example") {
+		t.Error("rendered client should show the billing contact's email instead of the general one")
+	}
+}
+
+func TestRenderClientWithoutDetails(t *testing.T) {
+	address := "123 Main St"
+	client := &models.Entity{ID: "c1", Name: "Acme", Address: &address}
+
+	rendered := renderClient(client, &models.ClientDetails{}, contentWidth(invoiceViewWidth, 0))
+
+	if !strings.Contains(rendered, "123 Main St") {
+		t.Error("clients without structured details should render their plain address")
+	}
+}
+
+func TestRenderInvoiceViewCustomFields(t *testing.T) {
+	data := &models.InvoiceData{
+		InvoiceID:   1,
+		DateCreated: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		Provider:    models.Entity{ID: "p1", Name: "Acme"},
+		Client:      models.Entity{ID: "c1", Name: "Client"},
+		CustomFields: []models.CustomFieldValue{
+			{Field: models.CustomField{EntityType: models.EntityInvoice, Key: "po_number", Label: "PO Number"}, Value: "PO-991"},
+			{Field: models.CustomField{EntityType: models.EntityClient, Key: "supplier_id", Label: "Supplier ID"}, Value: "SUP-7"},
+		},
+	}
+
+	rendered := RenderInvoiceView(data, 0)
+
+	for _, want := range []string{"PO Number:", "PO-991", "Supplier ID:", "SUP-7"} {
+		if !strings.Contains(rendered, want) {
+			t.Errorf("rendered invoice should contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package views
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/GVPproj/termsheet/models"
+	"github.com/GVPproj/termsheet/tui/components/table"
+	"github.com/GVPproj/termsheet/tui/keys"
+)
+
+// InvoiceItemColumns are the columns of the item editor
+var InvoiceItemColumns = []table.Column{
+	{Title: "Item", Width: 30, Flex: true},
+	{Title: "Quantity", Width: 8, AlignRight: true},
+	{Title: "Cost/Unit", Width: 10, AlignRight: true},
+	{Title: "Total", Width: 10, AlignRight: true},
+}
+
+// InvoiceItems is what the item editor shows: the items of the invoice being
+// created or edited, not yet saved
+type InvoiceItems struct {
+	Table table.Model
+	Items []models.InvoiceItem
+}
+
+// NewInvoiceItemTable creates an empty item editor table
+func NewInvoiceItemTable() table.Model {
+	km := keys.Current()
+	t := table.New(InvoiceItemColumns)
+	t.Empty = fmt.Sprintf("No items yet; press %s to add one", km.New.Help().Key)
+	t.KeyMap = km.Table()
+	return t
+}
+
+// FitInvoiceItemTable fits the item editor table to a terminal of the given size
+func FitInvoiceItemTable(t *table.Model, width, height int) {
+	// Rows are indented to leave room for the cursor
+	t.SetWidth(contentWidth(invoiceViewWidth, width) - 2)
+	if _, rows := ListTableSize(width, height); rows > 0 {
+		t.SetHeight(rows)
+	}
+}
+
+// InvoiceItemRows turns items into table rows. Items are identified by their position,
+// since new ones have no ID until the invoice is saved.
+func InvoiceItemRows(items []models.InvoiceItem) []table.Row {
+	rows := make([]table.Row, 0, len(items))
+	for i, item := range items {
+		rows = append(rows, table.Row{
+			ID: strconv.Itoa(i),
+			Cells: []string{
+				item.ItemName,
+				fmt.Sprintf("%.2f", item.Amount),
+				fmt.Sprintf("$%.2f", item.CostPerUnit),
+				fmt.Sprintf("$%.2f", item.Amount*item.CostPerUnit),
+			},
+		})
+	}
+	return rows
+}
+
+// RenderInvoiceItems renders the item editor with the invoice's total under it
+func RenderInvoiceItems(items InvoiceItems, width int) string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Invoice Items"))
+	b.WriteString("\n\n")
+	b.WriteString(items.Table.View())
+
+	if len(items.Items) > 0 {
+		var total float64
+		for _, item := range items.Items {
+			total += item.Amount * item.CostPerUnit
+		}
+		b.WriteString("\n\n")
+		b.WriteString(labelStyle.Render("Total: "))
+		b.WriteString(valueStyle.Render(fmt.Sprintf("$%.2f", total)))
+	}
+
+	b.WriteString(helpStyle.Render("\n\n" + helpLine(InvoiceItemsKeys()...)))
+	return container(invoiceViewWidth, width).Render(b.String())
+}
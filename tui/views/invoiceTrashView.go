@@ -0,0 +1,91 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GVPproj/termsheet/storage"
+	"github.com/GVPproj/termsheet/tui/keys"
+	"github.com/charmbracelet/huh"
+)
+
+// TrashActionOption represents the action to take on a trashed invoice
+type TrashActionOption string
+
+const (
+	TrashActionRestore TrashActionOption = "restore"
+	TrashActionPurge   TrashActionOption = "purge"
+	TrashActionCancel  TrashActionOption = "cancel"
+)
+
+// CreateInvoiceTrashForm creates a form for selecting a trashed invoice
+func CreateInvoiceTrashForm(selection *string) (*huh.Form, error) {
+	invoices, err := storage.ListTrashedInvoices()
+	if err != nil {
+		return nil, err
+	}
+
+	options := make([]huh.Option[string], 0, len(invoices)+2)
+	for _, inv := range invoices {
+		label := fmt.Sprintf("%s - %s → %s", inv.DisplayNumber(), inv.ProviderName, inv.ClientName)
+		if inv.DeletedAt != nil {
+			label += fmt.Sprintf(" (deleted %s)", inv.DeletedAt.Format("2006-01-02"))
+		}
+		options = append(options, huh.NewOption(label, fmt.Sprintf("%d", inv.ID)))
+	}
+
+	if len(invoices) > 0 {
+		options = append(options, huh.NewOption("✗ Empty Trash", "EMPTY_TRASH"))
+	}
+	options = append(options, huh.NewOption("← Back to Invoices", "BACK"))
+
+	title := "Select an invoice to restore or delete permanently"
+	if len(invoices) == 0 {
+		title = "The trash is empty"
+	}
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title(title).
+				Options(options...).
+				Value(selection),
+		),
+	).WithTheme(GetMenuTheme())
+
+	return form, nil
+}
+
+// CreateTrashActionForm creates a form for choosing what to do with a trashed invoice
+func CreateTrashActionForm(selection *string) *huh.Form {
+	return huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("What would you like to do?").
+				Options(
+					huh.NewOption("Restore Invoice", string(TrashActionRestore)),
+					huh.NewOption("Delete Permanently", string(TrashActionPurge)),
+					huh.NewOption("Cancel", string(TrashActionCancel)),
+				).
+				Value(selection),
+		),
+	).WithTheme(GetMenuTheme())
+}
+
+// RenderInvoiceTrash renders the invoice trash view
+func RenderInvoiceTrash(form *huh.Form, width int) string {
+	var b strings.Builder
+
+	// Render title
+	b.WriteString(titleStyle.Render("Trash"))
+	b.WriteString("\n\n")
+
+	// Render the form
+	b.WriteString(form.View())
+
+	// Render help text
+	b.WriteString(helpStyle.Render("\n\nDeleted invoices stay here until purged | " + helpLine(keys.Current().Back, keys.Current().Help)))
+
+	// Wrap in container
+	return container(defaultWidth, width).Render(b.String())
+}
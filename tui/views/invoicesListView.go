@@ -2,100 +2,198 @@ package views
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/GVPproj/termsheet/models"
 	"github.com/GVPproj/termsheet/storage"
+	"github.com/GVPproj/termsheet/tui/components/table"
+	"github.com/GVPproj/termsheet/tui/keys"
 	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
 )
 
-// CreateInvoiceListForm creates a form for selecting or creating invoices
-func CreateInvoiceListForm(selection *string) (*huh.Form, error) {
-	invoices, err := storage.ListInvoices()
-	if err != nil {
-		return nil, err
+// InvoiceColumns are the columns of the invoice list
+// On narrow terminals the names shrink, then the tags, due date, provider and date are hidden.
+// The tags column comes after the sortable ones, so it has no sort key.
+var InvoiceColumns = []table.Column{
+	{Title: "Number", Width: 12},
+	{Title: "Date", Width: 10, Optional: true},
+	{Title: "Client", Width: 16, Flex: true},
+	{Title: "Provider", Width: 16, Flex: true, Optional: true},
+	{Title: "Total", Width: 10, AlignRight: true},
+	{Title: "Status", Width: 8},
+	{Title: "Due", Width: 10, Optional: true},
+	{Title: "Tags", Width: 16, Optional: true},
+}
+
+// InvoiceSortColumns are the sort orders selected by the invoice list's columns, in column order
+var InvoiceSortColumns = []storage.InvoiceSortColumn{
+	storage.SortByNumber,
+	storage.SortByDate,
+	storage.SortByClient,
+	storage.SortByProvider,
+	storage.SortByTotal,
+	storage.SortByStatus,
+	storage.SortByDueDate,
+}
+
+// InvoiceList is what the invoice list view shows
+type InvoiceList struct {
+	Table  table.Model
+	Filter storage.InvoiceFilter
+	// TrashCount is the number of invoices in the trash
+	TrashCount int
+	// Preview is the selected invoice, shown beside the list; nil hides the preview
+	Preview *models.InvoiceData
+}
+
+// previewMinWindowWidth is the narrowest terminal with room for the preview beside the invoice list
+const previewMinWindowWidth = 110
+
+// previewStyle separates the preview from the invoice list
+var previewStyle = lipgloss.NewStyle().
+	Border(lipgloss.NormalBorder(), false, false, false, true).
+	MarginLeft(1).
+	PaddingLeft(2)
+
+// InvoicePreviewWidth returns how wide the preview beside the invoice list is in a terminal
+// width wide, or 0 if the terminal is too narrow for one or its size isn't known yet
+func InvoicePreviewWidth(width int) int {
+	if width < previewMinWindowWidth {
+		return 0
 	}
+	return width * 2 / 5
+}
 
-	// Build options for the select form
-	options := make([]huh.Option[string], 0)
+// NewInvoiceTable creates an empty invoice table
+func NewInvoiceTable() table.Model {
+	km := keys.Current()
+	t := table.New(InvoiceColumns)
+	t.Empty = fmt.Sprintf("No invoices yet; press %s to create one", km.New.Help().Key)
+	t.KeyMap = km.Table()
+	return t
+}
 
-	// Add existing invoices
-	for _, inv := range invoices {
-		paidStatus := "Unpaid"
-		if inv.Paid {
-			paidStatus = "Paid"
-		}
-		label := fmt.Sprintf("#%d - %s → %s (%s)", inv.ID, inv.ProviderName, inv.ClientName, paidStatus)
-		// Store invoice ID as string for selection
-		options = append(options, huh.NewOption(label, fmt.Sprintf("%d", inv.ID)))
+// LoadInvoiceRows makes t page through the invoices matching filter in the given order,
+// marking the sort column in its header and keeping the selected invoice if it is still listed
+func LoadInvoiceRows(t *table.Model, filter storage.InvoiceFilter, sort storage.InvoiceSort) error {
+	if sort.Column == "" {
+		sort = storage.DefaultInvoiceSort
 	}
+	t.SetSort(slices.Index(InvoiceSortColumns, sort.Column), sort.Desc)
+	return t.SetSource(invoiceSource{filter: filter, sort: sort})
+}
 
-	// Add "Create New Invoice" option
-	options = append(options, huh.NewOption("+ Create New Invoice", "CREATE_NEW"))
+// invoiceSource pages through the invoices matching a filter, so the list
+// never loads more than a few pages of invoices however many there are
+type invoiceSource struct {
+	filter storage.InvoiceFilter
+	sort   storage.InvoiceSort
+}
 
-	form := huh.NewForm(
-		huh.NewGroup(
-			huh.NewSelect[string]().
-				Title("Select an invoice or create a new one").
-				Options(options...).
-				Value(selection),
-		),
-	).WithTheme(GetMenuTheme())
+func (s invoiceSource) Count() (int, error) {
+	return storage.CountInvoices(s.filter)
+}
 
-	return form, nil
+func (s invoiceSource) Page(id string, backward bool, limit int) ([]table.Row, error) {
+	invoices, err := storage.ListInvoicesPage(s.filter, s.sort, storage.PageCursor{ID: id, Backward: backward}, limit)
+	if err != nil {
+		return nil, err
+	}
+	return InvoiceTableRows(invoices), nil
 }
 
-// RenderInvoices renders the invoice list view with the given form
-func RenderInvoices(form *huh.Form) string {
+// InvoiceTableRows turns invoices into table rows identified by invoice ID
+func InvoiceTableRows(invoices []models.InvoiceSummary) []table.Row {
+	rows := make([]table.Row, 0, len(invoices))
+	for _, inv := range invoices {
+		due := ""
+		if inv.DueDate != nil {
+			due = inv.DueDate.Format("2006-01-02")
+		}
+		status := string(inv.Status())
+		rows = append(rows, table.Row{
+			ID: strconv.Itoa(inv.ID),
+			Cells: []string{
+				inv.DisplayNumber(),
+				inv.DateCreated.Format("2006-01-02"),
+				inv.ClientName,
+				inv.ProviderName,
+				fmt.Sprintf("$%.2f", inv.Total),
+				strings.ToUpper(status[:1]) + status[1:],
+				due,
+				models.TagChips(inv.Tags),
+			},
+		})
+	}
+	return rows
+}
+
+// RenderInvoiceList renders the invoice table with the active filters above it
+func RenderInvoiceList(list InvoiceList, width int) string {
 	var b strings.Builder
 
-	// Render title
 	b.WriteString(titleStyle.Render("Invoices"))
 	b.WriteString("\n\n")
 
-	// Render the form
-	b.WriteString(form.View())
+	if bar := invoiceFilterBar(list.Filter); bar != "" {
+		b.WriteString(bar)
+		b.WriteString("\n\n")
+	}
 
-	// Render help text
-	b.WriteString(helpStyle.Render("\n\nPress 'd' to delete | ESC to return to menu"))
+	// The selected invoice is previewed beside the table when there is room
+	tableView := list.Table.View()
+	if previewWidth := InvoicePreviewWidth(width); list.Preview != nil && previewWidth > 0 {
+		preview := renderInvoicePreview(list.Preview, previewWidth, list.Table.Height()+2)
+		tableView = lipgloss.JoinHorizontal(lipgloss.Top, tableView, preview)
+	}
+	b.WriteString(tableView)
 
-	// Wrap in container
-	return containerStyle.Render(b.String())
+	// The trash key is only shown when there is something in the trash
+	km := keys.Current()
+	trash := keys.Hint(km.Trash, fmt.Sprintf("open trash (%d)", list.TrashCount))
+	trash.SetEnabled(list.TrashCount > 0)
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Width(lipgloss.Width(tableView)).Render(helpLine(append(invoiceListKeys(trash), km.Help)...)))
+
+	// The table sets the container's width
+	return containerStyle.UnsetWidth().Render(b.String())
 }
 
-// DeleteSelectedInvoice deletes the invoice with the given ID and returns the index to preserve
-func DeleteSelectedInvoice(invoiceIDStr string) (int, error) {
-	if invoiceIDStr == "" || invoiceIDStr == "CREATE_NEW" {
-		return -1, fmt.Errorf("invalid invoice selection")
+// renderInvoicePreview renders the invoice for the preview beside the list, width wide, with
+// no more than one blank line in a row, cutting it off after height lines, where the table can
+// end at the most: its rows, header and scroll position
+func renderInvoicePreview(data *models.InvoiceData, width, height int) string {
+	var lines []string
+	for _, line := range strings.Split(renderInvoiceDetails(data, width-previewStyle.GetHorizontalFrameSize()), "\n") {
+		blank := strings.TrimSpace(line) == ""
+		if blank && len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+			continue
+		}
+		lines = append(lines, line)
 	}
-
-	// Parse invoice ID
-	var invoiceID int
-	_, err := fmt.Sscanf(invoiceIDStr, "%d", &invoiceID)
-	if err != nil {
-		return -1, fmt.Errorf("invalid invoice ID: %v", err)
+	if len(lines) > height {
+		lines = append(lines[:height-1], helpStyle.UnsetMarginTop().Render("…"))
 	}
+	return previewStyle.Render(strings.Join(lines, "\n"))
+}
 
-	// Get current invoices to find the index before deletion
-	invoices, err := storage.ListInvoices()
-	if err != nil {
-		return -1, err
-	}
+// RenderInvoices renders the invoice create, edit and filter views with the given form
+func RenderInvoices(form *huh.Form, width int) string {
+	var b strings.Builder
 
-	// Find index of invoice being deleted
-	deletedIndex := -1
-	for i, inv := range invoices {
-		if inv.ID == invoiceID {
-			deletedIndex = i
-			break
-		}
-	}
+	// Render title
+	b.WriteString(titleStyle.Render("Invoices"))
+	b.WriteString("\n\n")
 
-	// Delete the invoice
-	if err := storage.DeleteInvoice(invoiceID); err != nil {
-		return -1, err
-	}
+	// Render the form
+	b.WriteString(form.View())
 
-	// Return the index where cursor should be after deletion
-	// (same index will select next item, or previous if last was deleted)
-	return deletedIndex, nil
+	// Render help text
+	b.WriteString(helpStyle.Render("\n\n" + helpLine(keys.Current().Back)))
+
+	// Wrap in container
+	return container(defaultWidth, width).Render(b.String())
 }
@@ -0,0 +1,45 @@
+package views
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GVPproj/termsheet/models"
+)
+
+func TestInvoiceTableRows(t *testing.T) {
+	due := time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC)
+	rows := InvoiceTableRows([]models.InvoiceSummary{
+		{
+			ID:           4,
+			Number:       "INV-0004",
+			ClientName:   "Globex",
+			ProviderName: "Acme",
+			DateCreated:  time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+			DueDate:      &due,
+			Total:        150,
+			Tags:         []string{"agency", "retainer"},
+		},
+		{ID: 5, ClientName: "Initech", ProviderName: "Acme"},
+	})
+
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+	for _, row := range rows {
+		if len(row.Cells) != len(InvoiceColumns) {
+			t.Errorf("row %s: expected %d cells, got %d", row.ID, len(InvoiceColumns), len(row.Cells))
+		}
+	}
+
+	tags := rows[0].Cells[len(InvoiceColumns)-1]
+	if tags != "#agency #retainer" {
+		t.Errorf("expected tag chips in the last column, got %q", tags)
+	}
+	if due := rows[0].Cells[6]; due != "2025-03-31" {
+		t.Errorf("expected due date 2025-03-31, got %q", due)
+	}
+	if tags := rows[1].Cells[len(InvoiceColumns)-1]; tags != "" {
+		t.Errorf("expected no tags for an untagged invoice, got %q", tags)
+	}
+}
@@ -0,0 +1,68 @@
+package views
+
+import (
+	"strings"
+
+	"github.com/GVPproj/termsheet/utils"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// defaultWidth is how wide menus, lists and forms are on terminals wide enough for them
+	defaultWidth = 60
+	// invoiceViewWidth is how wide the invoice display is on terminals wide enough for it
+	invoiceViewWidth = 84
+	// narrowWidth is the content width below which views stack labels and values
+	// and table columns instead of laying them out side by side
+	narrowWidth = 50
+	// minContainerWidth keeps views usable, if cramped, on tiny terminals
+	minContainerWidth = 24
+)
+
+// containerWidth returns how wide a container that would like to be preferred
+// wide can be in a terminal width wide, not counting its border. Views are given
+// the terminal's size by the root model, or zeros before the first
+// tea.WindowSizeMsg, in which case they take the size they would like to be.
+func containerWidth(preferred, width int) int {
+	if width == 0 {
+		return preferred
+	}
+	border := containerStyle.GetHorizontalBorderSize()
+	return max(min(preferred, width-border), minContainerWidth)
+}
+
+// container returns the container style for a view that would like to be preferred wide
+func container(preferred, width int) lipgloss.Style {
+	return containerStyle.Width(containerWidth(preferred, width))
+}
+
+// contentWidth returns the width available inside container(preferred, width)
+func contentWidth(preferred, width int) int {
+	return containerWidth(preferred, width) - containerStyle.GetHorizontalPadding()
+}
+
+// FormWidth returns the width forms are laid out in
+func FormWidth(width int) int {
+	return contentWidth(defaultWidth, width)
+}
+
+// listChromeHeight is how many lines a list view takes up besides its rows:
+// the container, title, filter bar, table header, scroll position and help text
+const listChromeHeight = 14
+
+// ListTableSize returns how wide and how many rows tall a table in a list view can be
+// in a window of the given size, or zeros if the window size isn't known yet
+func ListTableSize(width, height int) (int, int) {
+	if width == 0 {
+		return 0, 0
+	}
+	// The table sets the container's width, so it may use the whole window;
+	// its rows are indented to leave room for the cursor
+	chrome := containerStyle.GetHorizontalFrameSize() + 2
+	return max(width-chrome, minContainerWidth), max(height-listChromeHeight, 3)
+}
+
+// wrap breaks text into lines of at most width cells, for styles that pad rather than wrap
+func wrap(text string, width int) string {
+	return strings.Join(utils.WrapText(text, width), "\n")
+}
@@ -4,45 +4,60 @@ package views
 import (
 	"strings"
 
+	"github.com/GVPproj/termsheet/tui/keys"
+	"github.com/GVPproj/termsheet/tui/theme"
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
 )
 
-// GetMenuTheme returns a customized theme for the menu form
+// GetMenuTheme returns the theme for forms, in the colours of the current theme
 func GetMenuTheme() *huh.Theme {
-	t := huh.ThemeCharm()
-	t.Focused.Title = t.Focused.Title.Foreground(lipgloss.Color("#61AFEF"))
-	t.Focused.SelectedOption = t.Focused.SelectedOption.Foreground(lipgloss.Color("#D33682"))
-	t.Focused.SelectSelector = t.Focused.SelectSelector.Foreground(lipgloss.Color("#F255A1"))
-	return t
+	return theme.Current().Form()
 }
 
 var (
-	// Adaptive colors for light/dark terminals
-	subtle = lipgloss.AdaptiveColor{Light: "#D9DCCF", Dark: "#383838"}
-
 	// Title styling
 	titleStyle = lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("#61AFEF")).
 			MarginBottom(1)
 
 	// Help text styling
 	helpStyle = lipgloss.NewStyle().
-			Foreground(subtle).
 			MarginTop(1)
-	itemStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#98C379"))
+	itemStyle = lipgloss.NewStyle()
 
 	// Container styling
 	containerStyle = lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("#61AFEF")).
 			Padding(1, 2).
 			Width(60)
 )
 
-func RenderMenu(form *huh.Form) string {
+func init() {
+	theme.OnChange(applyTheme)
+}
+
+// applyTheme colours the styles of every view with t
+func applyTheme(t theme.Theme) {
+	titleStyle = titleStyle.Foreground(t.Primary)
+	helpStyle = helpStyle.Foreground(t.Subtle)
+	itemStyle = itemStyle.Foreground(t.Text)
+	containerStyle = containerStyle.BorderForeground(t.Primary)
+
+	tableHeaderStyle = tableHeaderStyle.Foreground(t.Primary)
+	tableCellStyle = tableCellStyle.Foreground(t.Text)
+	tableRowStyle = tableRowStyle.BorderForeground(t.Subtle)
+	sectionTitleStyle = sectionTitleStyle.Foreground(t.Accent)
+	labelStyle = labelStyle.Foreground(t.Primary)
+	valueStyle = valueStyle.Foreground(t.Text)
+
+	breadcrumbStyle = breadcrumbStyle.Foreground(t.Muted)
+	currentCrumbStyle = currentCrumbStyle.Foreground(t.Primary)
+	filterBarStyle = filterBarStyle.Foreground(t.Warning)
+	previewStyle = previewStyle.BorderForeground(t.Subtle)
+}
+
+func RenderMenu(form *huh.Form, width int) string {
 	var b strings.Builder
 
 	// Render title
@@ -54,14 +69,14 @@ func RenderMenu(form *huh.Form) string {
 	b.WriteString(form.View())
 
 	// Render help text
-	b.WriteString(helpStyle.Render("\nPress q to quit"))
+	b.WriteString(helpStyle.Render("\n" + helpLine(keys.Current().Quit, keys.Current().Help)))
 
 	// Wrap in container
-	return containerStyle.Render(b.String())
+	return container(defaultWidth, width).Render(b.String())
 }
 
 // RenderDeleteConfirm renders the delete confirmation view
-func RenderDeleteConfirm(form *huh.Form) string {
+func RenderDeleteConfirm(form *huh.Form, width int) string {
 	var b strings.Builder
 
 	// Render title
@@ -72,8 +87,8 @@ func RenderDeleteConfirm(form *huh.Form) string {
 	b.WriteString(form.View())
 
 	// Render help text
-	b.WriteString(helpStyle.Render("\n\nESC to cancel"))
+	b.WriteString(helpStyle.Render("\n\n" + helpLine(keys.Hint(keys.Current().Back, "cancel"))))
 
 	// Wrap in container
-	return containerStyle.Render(b.String())
+	return container(defaultWidth, width).Render(b.String())
 }
@@ -0,0 +1,69 @@
+package views
+
+import (
+	"strings"
+
+	"github.com/GVPproj/termsheet/tui/keys"
+	"github.com/charmbracelet/huh"
+)
+
+// PaletteAction is something the command palette can do
+type PaletteAction string
+
+const (
+	PaletteNewInvoice   PaletteAction = "new-invoice"
+	PaletteNewClient    PaletteAction = "new-client"
+	PaletteNewProvider  PaletteAction = "new-provider"
+	PaletteOpenInvoice  PaletteAction = "open-invoice"
+	PaletteOpenClient   PaletteAction = "open-client"
+	PaletteOpenProvider PaletteAction = "open-provider"
+	PaletteMarkPaid     PaletteAction = "mark-paid"
+)
+
+// PaletteCommand is an entry in the command palette
+type PaletteCommand struct {
+	Action PaletteAction
+	// ID is the invoice, client or provider the action applies to, if it needs one
+	ID    string
+	Label string
+}
+
+// paletteHeight is how many commands the palette shows at a time
+const paletteHeight = 12
+
+// CreatePaletteForm creates the command palette: a query input with the commands matching
+// it below, which commands returns best match first
+func CreatePaletteForm(query *string, selection *PaletteCommand, commands func(query string) []PaletteCommand) *huh.Form {
+	return huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Command").
+				Placeholder("e.g. new invoice, mark 142 paid, or a client's name").
+				Value(query),
+			huh.NewSelect[PaletteCommand]().
+				OptionsFunc(func() []huh.Option[PaletteCommand] {
+					matches := commands(*query)
+					options := make([]huh.Option[PaletteCommand], 0, len(matches))
+					for _, cmd := range matches {
+						options = append(options, huh.NewOption(cmd.Label, cmd))
+					}
+					return options
+				}, query).
+				Height(paletteHeight).
+				Value(selection),
+		),
+	).WithTheme(GetMenuTheme())
+}
+
+// RenderPalette renders the command palette with the given form
+func RenderPalette(form *huh.Form, width int) string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Commands"))
+	b.WriteString("\n\n")
+
+	b.WriteString(form.View())
+
+	b.WriteString(helpStyle.Render("\n\n" + helpLine(keys.Hint(selectKey, "run"), keys.Current().Back)))
+	return container(defaultWidth, width).Render(b.String())
+}
@@ -0,0 +1,107 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GVPproj/termsheet/models"
+	"github.com/GVPproj/termsheet/storage"
+	"github.com/charmbracelet/huh"
+)
+
+// searchResultLimit is how many results the global search shows
+const searchResultLimit = 20
+
+// CreateSearchForm creates the global search form: a query input with results that
+// update as the query changes. The selected value is a search target, see SearchTarget.
+func CreateSearchForm(query, selection *string) *huh.Form {
+	return huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Search").
+				Placeholder("Item, note, client, provider or contact").
+				Value(query),
+			huh.NewSelect[string]().
+				TitleFunc(func() string {
+					if strings.TrimSpace(*query) == "" {
+						return "Results"
+					}
+					return fmt.Sprintf("Results for %q", *query)
+				}, query).
+				OptionsFunc(func() []huh.Option[string] {
+					return searchOptions(*query)
+				}, query).
+				Height(searchResultLimit/2).
+				Value(selection),
+		),
+	).WithTheme(GetMenuTheme())
+}
+
+// searchOptions runs the search and turns the results into select options
+func searchOptions(query string) []huh.Option[string] {
+	results, err := storage.Search(query, searchResultLimit)
+	if err != nil {
+		return []huh.Option[string]{huh.NewOption("Search failed: "+err.Error(), "")}
+	}
+
+	options := make([]huh.Option[string], 0, len(results))
+	for _, r := range results {
+		options = append(options, huh.NewOption(SearchResultLabel(r), SearchTarget(r)))
+	}
+	return options
+}
+
+// SearchTarget returns where selecting a result leads, as "invoice:<id>", "client:<id>" or
+// "provider:<id>"; items lead to their invoice and contacts to their client
+func SearchTarget(r models.SearchResult) string {
+	switch r.Kind {
+	case models.SearchInvoice, models.SearchItem:
+		return fmt.Sprintf("%s:%d", models.SearchInvoice, r.InvoiceID)
+	case models.SearchContact:
+		return fmt.Sprintf("%s:%s", models.SearchClient, r.EntityID)
+	}
+	return fmt.Sprintf("%s:%s", r.Kind, r.EntityID)
+}
+
+// ParseSearchTarget splits a value returned by SearchTarget into the kind and ID of the record to open
+func ParseSearchTarget(target string) (models.SearchKind, string, bool) {
+	kind, id, ok := strings.Cut(target, ":")
+	if !ok || id == "" {
+		return "", "", false
+	}
+	return models.SearchKind(kind), id, true
+}
+
+// SearchResultLabel describes a search result on one line
+func SearchResultLabel(r models.SearchResult) string {
+	var description string
+	switch r.Kind {
+	case models.SearchInvoice:
+		description = fmt.Sprintf("%s · %s", r.DisplayNumber(), r.ClientName)
+	case models.SearchItem:
+		description = fmt.Sprintf("%s · invoice %s for %s", r.Title, r.DisplayNumber(), r.ClientName)
+	case models.SearchContact:
+		description = fmt.Sprintf("%s · %s", r.Title, r.ClientName)
+	default:
+		description = r.Title
+	}
+
+	label := fmt.Sprintf("%-8s %s", strings.ToUpper(string(r.Kind[:1]))+string(r.Kind[1:]), description)
+	if snippet := strings.Join(strings.Fields(r.Snippet), " "); snippet != "" {
+		label += " — " + snippet
+	}
+	return label
+}
+
+// RenderSearch renders the global search view with the given form
+func RenderSearch(form *huh.Form, width int) string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Search"))
+	b.WriteString("\n\n")
+
+	b.WriteString(form.View())
+
+	b.WriteString(helpStyle.Render("\n\n" + helpLine(SearchKeys()...)))
+	return container(defaultWidth, width).Render(b.String())
+}
@@ -0,0 +1,51 @@
+package views
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/GVPproj/termsheet/models"
+)
+
+func TestSearchTargets(t *testing.T) {
+	tests := []struct {
+		result models.SearchResult
+		kind   models.SearchKind
+		id     string
+	}{
+		{models.SearchResult{Kind: models.SearchInvoice, InvoiceID: 12}, models.SearchInvoice, "12"},
+		{models.SearchResult{Kind: models.SearchItem, InvoiceID: 7}, models.SearchInvoice, "7"},
+		{models.SearchResult{Kind: models.SearchClient, EntityID: "c-1"}, models.SearchClient, "c-1"},
+		{models.SearchResult{Kind: models.SearchContact, EntityID: "c-2"}, models.SearchClient, "c-2"},
+		{models.SearchResult{Kind: models.SearchProvider, EntityID: "p-1"}, models.SearchProvider, "p-1"},
+	}
+
+	for _, tt := range tests {
+		kind, id, ok := ParseSearchTarget(SearchTarget(tt.result))
+		if !ok || kind != tt.kind || id != tt.id {
+			t.Errorf("%s result: expected %s %s, got %s %s (ok %v)", tt.result.Kind, tt.kind, tt.id, kind, id, ok)
+		}
+	}
+
+	if _, _, ok := ParseSearchTarget(""); ok {
+		t.Error("expected an empty selection not to parse")
+	}
+}
+
+func TestSearchResultLabel(t *testing.T) {
+	label := SearchResultLabel(models.SearchResult{
+		Kind:          models.SearchItem,
+		Title:         "Logo redesign",
+		InvoiceID:     3,
+		InvoiceNumber: "INV-0003",
+		ClientName:    "Globex",
+	})
+	if label != "Item     Logo redesign · invoice INV-0003 for Globex" {
+		t.Errorf("unexpected label %q", label)
+	}
+
+	label = SearchResultLabel(models.SearchResult{Kind: models.SearchInvoice, InvoiceID: 3, Snippet: "the\nredesigned page"})
+	if !strings.HasPrefix(label, "Invoice  #3") || !strings.HasSuffix(label, "— the redesigned page") {
+		t.Errorf("unexpected label %q", label)
+	}
+}
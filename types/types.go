@@ -1,7 +1,10 @@
 // Package types defines the view constants used for navigation in the termsheet application.
 package types
 
-import "github.com/charmbracelet/huh"
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
 
 type View int
 
@@ -11,19 +14,45 @@ const (
 	ProviderCreateView
 	ProviderEditView
 	ProviderDeleteConfirmView
+	ProviderFilterView
 	ClientsListView
 	ClientCreateView
 	ClientEditView
 	ClientDeleteConfirmView
+	ClientContactsView
+	ClientContactEditView
+	ClientTagFilterView
+	ClientFilterView
 	InvoicesListView
 	InvoiceActionMenuView
 	InvoiceViewView
 	InvoiceCreateView
 	InvoiceEditView
+	InvoiceItemsView
+	InvoiceItemEditView
+	InvoiceDetailsView
+	InvoiceDeleteConfirmView
+	InvoiceTrashView
+	InvoiceTrashActionView
+	InvoiceTagFilterView
+	InvoiceFilterView
+	SearchView
+	PaletteView
 )
 
 // ViewTransition represents a request to change views
 type ViewTransition struct {
 	NewView View
 	Form    *huh.Form
+	// Crumb labels the view in the breadcrumbs, e.g. with the record it shows;
+	// empty uses the view's title
+	Crumb string
+}
+
+// FormTransition changes to view showing form, initializing the form and running any extra commands
+func FormTransition(view View, form *huh.Form, cmds ...tea.Cmd) (*ViewTransition, tea.Cmd) {
+	return &ViewTransition{
+		NewView: view,
+		Form:    form,
+	}, tea.Batch(append([]tea.Cmd{form.Init()}, cmds...)...)
 }
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Scores FuzzyMatch gives each matched character, on top of one point
+const (
+	// consecutiveBonus rewards characters matched right after the previous one
+	consecutiveBonus = 5
+	// wordStartBonus rewards characters matched at the start of a word
+	wordStartBonus = 3
+)
+
+// FuzzyMatch reports whether the characters of pattern appear in text in order, ignoring
+// case and the spaces in pattern, e.g. "mkpd" in "Mark paid". The score ranks matches:
+// higher for runs of characters and characters starting words, so "np" scores higher for
+// "New provider" than for "Open provider". An empty pattern matches everything with score 0.
+func FuzzyMatch(pattern, text string) (score int, ok bool) {
+	p := []rune(strings.ToLower(strings.Join(strings.Fields(pattern), "")))
+	if len(p) == 0 {
+		return 0, true
+	}
+
+	t := []rune(strings.ToLower(text))
+	matched, prev := 0, -2
+	for i, r := range t {
+		if matched == len(p) {
+			break
+		}
+		if r != p[matched] {
+			continue
+		}
+
+		score++
+		if i == prev+1 {
+			score += consecutiveBonus
+		}
+		if i == 0 || !unicode.IsLetter(t[i-1]) && !unicode.IsDigit(t[i-1]) {
+			score += wordStartBonus
+		}
+		prev = i
+		matched++
+	}
+
+	if matched < len(p) {
+		return 0, false
+	}
+	return score, true
+}
@@ -0,0 +1,39 @@
+package utils
+
+import "testing"
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		text    string
+		ok      bool
+	}{
+		{"", "Anything", true},
+		{"mark paid", "Mark invoice 142 paid", true},
+		{"mk142pd", "Mark invoice 142 paid", true},
+		{"NEW INV", "New invoice", true},
+		{"paid mark", "Mark invoice 142 paid", false},
+		{"newx", "New invoice", false},
+		{"café", "Open client Café Olé", true},
+	}
+
+	for _, tt := range tests {
+		if _, ok := FuzzyMatch(tt.pattern, tt.text); ok != tt.ok {
+			t.Errorf("FuzzyMatch(%q, %q) matched %v, expected %v", tt.pattern, tt.text, ok, tt.ok)
+		}
+	}
+}
+
+func TestFuzzyMatchRanksRunsAndWordStarts(t *testing.T) {
+	better, _ := FuzzyMatch("np", "New provider")
+	worse, _ := FuzzyMatch("np", "Open provider")
+	if better <= worse {
+		t.Errorf("expected word starts to score higher, got %d and %d", better, worse)
+	}
+
+	better, _ = FuzzyMatch("inv", "New invoice")
+	worse, _ = FuzzyMatch("inv", "Open client Initech Ventures")
+	if better <= worse {
+		t.Errorf("expected a run of characters to score higher, got %d and %d", better, worse)
+	}
+}
@@ -1,12 +1,111 @@
 package utils
 
-// TruncateText truncates text to maxLen characters, adding "..." if truncated
+import (
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// The functions below measure text in terminal cells rather than bytes or runes:
+// CJK characters and most emoji take two cells, combining marks none. They work on
+// grapheme clusters, so an accented letter or a flag is never split.
+// Text must be plain, without ANSI escape sequences.
+
+// TextWidth returns how many cells text takes up in a terminal
+func TextWidth(text string) int {
+	return uniseg.StringWidth(text)
+}
+
+// TruncateText truncates text to maxLen cells, adding "..." if truncated
 func TruncateText(text string, maxLen int) string {
-	if len(text) <= maxLen {
+	return TruncateWithTail(text, maxLen, "...")
+}
+
+// TruncateWithTail truncates text to maxWidth cells, ending it with tail if truncated.
+// The result may be a cell narrower than maxWidth where a wide character doesn't fit.
+func TruncateWithTail(text string, maxWidth int, tail string) string {
+	if TextWidth(text) <= maxWidth {
 		return text
 	}
-	if maxLen <= 3 {
-		return text[:maxLen]
+	tailWidth := TextWidth(tail)
+	if tailWidth >= maxWidth {
+		return cutText(text, maxWidth)
+	}
+	return cutText(text, maxWidth-tailWidth) + tail
+}
+
+// PadRight pads text with spaces on the right to width cells, aligning it left
+func PadRight(text string, width int) string {
+	return text + strings.Repeat(" ", max(width-TextWidth(text), 0))
+}
+
+// PadLeft pads text with spaces on the left to width cells, aligning it right
+func PadLeft(text string, width int) string {
+	return strings.Repeat(" ", max(width-TextWidth(text), 0)) + text
+}
+
+// WrapText breaks text into lines of at most width cells, at the places Unicode
+// allows a line break, e.g. between words or CJK characters. Words too long for
+// a line of their own are broken between characters; line breaks in text are kept.
+func WrapText(text string, width int) []string {
+	width = max(width, 1)
+	var lines []string
+
+	for _, paragraph := range strings.Split(text, "\n") {
+		var line strings.Builder
+		lineWidth := 0
+		state := -1
+
+		for paragraph != "" {
+			var segment string
+			segment, paragraph, _, state = uniseg.FirstLineSegmentInString(paragraph, state)
+
+			// Trailing spaces may hang past the end of a line, so they don't count towards it
+			word := strings.TrimRight(segment, " ")
+			spaces := segment[len(word):]
+			wordWidth := TextWidth(word)
+
+			if lineWidth > 0 && lineWidth+wordWidth > width {
+				lines = append(lines, strings.TrimRight(line.String(), " "))
+				line.Reset()
+				lineWidth = 0
+			}
+			for wordWidth > width {
+				head := cutText(word, width)
+				if head == "" {
+					// A double-width character on lines one cell wide
+					head, _, _, _ = uniseg.FirstGraphemeClusterInString(word, -1)
+				}
+				if head == word {
+					break
+				}
+				lines = append(lines, head)
+				word = word[len(head):]
+				wordWidth = TextWidth(word)
+			}
+
+			line.WriteString(word)
+			line.WriteString(spaces)
+			lineWidth += wordWidth + len(spaces)
+		}
+		lines = append(lines, strings.TrimRight(line.String(), " "))
+	}
+	return lines
+}
+
+// cutText returns the longest start of text, in whole grapheme clusters, that fits in width cells
+func cutText(text string, width int) string {
+	rest := text
+	used := 0
+	state := -1
+	for rest != "" {
+		var cluster string
+		var clusterWidth int
+		cluster, rest, clusterWidth, state = uniseg.FirstGraphemeClusterInString(rest, state)
+		if used+clusterWidth > width {
+			return text[:len(text)-len(rest)-len(cluster)]
+		}
+		used += clusterWidth
 	}
-	return text[:maxLen-3] + "..."
+	return text
 }
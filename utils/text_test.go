@@ -1,6 +1,9 @@
 package utils
 
-import "testing"
+import (
+	"slices"
+	"testing"
+)
 
 func TestTruncateText(t *testing.T) {
 	tests := []struct {
@@ -50,3 +53,194 @@ func TestTruncateText(t *testing.T) {
 		})
 	}
 }
+
+func TestTruncateTextMultibyte(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		maxLen   int
+		expected string
+	}{
+		{
+			name:     "accented text that fits",
+			text:     "Café Müller",
+			maxLen:   11,
+			expected: "Café Müller",
+		},
+		{
+			name:     "accented text is cut between characters",
+			text:     "Crème brûlée Ltd",
+			maxLen:   12,
+			expected: "Crème brû...",
+		},
+		{
+			name:     "combining marks take no width",
+			text:     "Café Café",
+			maxLen:   9,
+			expected: "Café Café",
+		},
+		{
+			name:     "double-width characters count twice",
+			text:     "株式会社テスト",
+			maxLen:   10,
+			expected: "株式会...",
+		},
+		{
+			name:     "a wide character that doesn't fit is left out",
+			text:     "株式会社テスト",
+			maxLen:   8,
+			expected: "株式...",
+		},
+		{
+			name:     "emoji sequences are not split",
+			text:     "👩‍💻👩‍💻 Studio",
+			maxLen:   6,
+			expected: "👩‍💻...",
+		},
+		{
+			name:     "flags are not split",
+			text:     "🇯🇵🇫🇷🇩🇪",
+			maxLen:   3,
+			expected: "🇯🇵",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := TruncateText(tt.text, tt.maxLen)
+			if result != tt.expected {
+				t.Errorf("TruncateText(%q, %d) = %q, want %q", tt.text, tt.maxLen, result, tt.expected)
+			}
+			if TextWidth(result) > tt.maxLen {
+				t.Errorf("TruncateText(%q, %d) is %d cells wide", tt.text, tt.maxLen, TextWidth(result))
+			}
+		})
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected int
+	}{
+		{"", 0},
+		{"Invoice", 7},
+		{"Zoë", 3},
+		{"Café", 4},
+		{"東京", 4},
+		{"Ｆｕｌｌ", 8},
+		{"👍", 2},
+		{"👩‍💻", 2},
+		{"🇯🇵", 2},
+	}
+
+	for _, tt := range tests {
+		if got := TextWidth(tt.text); got != tt.expected {
+			t.Errorf("TextWidth(%q) = %d, want %d", tt.text, got, tt.expected)
+		}
+	}
+}
+
+func TestPadText(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		left  string
+		right string
+	}{
+		{"abc", 5, "abc  ", "  abc"},
+		{"Zoë", 5, "Zoë  ", "  Zoë"},
+		{"東京", 5, "東京 ", " 東京"},
+		{"👩‍💻", 3, "👩‍💻 ", " 👩‍💻"},
+		{"too long", 3, "too long", "too long"},
+		{"", 2, "  ", "  "},
+	}
+
+	for _, tt := range tests {
+		if got := PadRight(tt.text, tt.width); got != tt.left {
+			t.Errorf("PadRight(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.left)
+		}
+		if got := PadLeft(tt.text, tt.width); got != tt.right {
+			t.Errorf("PadLeft(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.right)
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		width    int
+		expected []string
+	}{
+		{
+			name:     "fits on one line",
+			text:     "Web design",
+			width:    20,
+			expected: []string{"Web design"},
+		},
+		{
+			name:     "breaks between words",
+			text:     "Website redesign and accessibility audit",
+			width:    16,
+			expected: []string{"Website redesign", "and", "accessibility", "audit"},
+		},
+		{
+			name:     "accented words",
+			text:     "Crème brûlée à la carte",
+			width:    12,
+			expected: []string{"Crème brûlée", "à la carte"},
+		},
+		{
+			name:     "breaks between CJK characters",
+			text:     "株式会社テスト",
+			width:    6,
+			expected: []string{"株式会", "社テス", "ト"},
+		},
+		{
+			name:     "double-width characters never straddle a line",
+			text:     "東京都",
+			width:    5,
+			expected: []string{"東京", "都"},
+		},
+		{
+			name:     "long words are broken between characters",
+			text:     "Supercalifragilistic",
+			width:    8,
+			expected: []string{"Supercal", "ifragili", "stic"},
+		},
+		{
+			name:     "emoji are not split",
+			text:     "👩‍💻👩‍💻👩‍💻",
+			width:    5,
+			expected: []string{"👩‍💻👩‍💻", "👩‍💻"},
+		},
+		{
+			name:     "keeps line breaks",
+			text:     "Line one\nLine two",
+			width:    20,
+			expected: []string{"Line one", "Line two"},
+		},
+		{
+			name:     "wide characters on narrow lines",
+			text:     "東京",
+			width:    1,
+			expected: []string{"東", "京"},
+		},
+		{
+			name:     "empty text",
+			text:     "",
+			width:    10,
+			expected: []string{""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := WrapText(tt.text, tt.width)
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("WrapText(%q, %d) = %q, want %q", tt.text, tt.width, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// postalFormats holds postal code patterns by ISO 3166 country code.
+// Codes are upper-cased before matching.
+var postalFormats = map[string]*regexp.Regexp{
+	"AT": regexp.MustCompile(`^\d{4}$`),
+	"AU": regexp.MustCompile(`^\d{4}$`),
+	"BE": regexp.MustCompile(`^\d{4}$`),
+	"BR": regexp.MustCompile(`^\d{5}-?\d{3}$`),
+	"CA": regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY]\d[ABCEGHJ-NPRSTV-Z] ?\d[ABCEGHJ-NPRSTV-Z]\d$`),
+	"CH": regexp.MustCompile(`^\d{4}$`),
+	"DE": regexp.MustCompile(`^\d{5}$`),
+	"DK": regexp.MustCompile(`^\d{4}$`),
+	"ES": regexp.MustCompile(`^\d{5}$`),
+	"FI": regexp.MustCompile(`^\d{5}$`),
+	"FR": regexp.MustCompile(`^\d{5}$`),
+	"GB": regexp.MustCompile(`^([A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}|GIR ?0AA)$`),
+	"IE": regexp.MustCompile(`^([AC-FHKNPRTV-Y]\d{2}|D6W) ?[0-9AC-FHKNPRTV-Y]{4}$`),
+	"IN": regexp.MustCompile(`^[1-9]\d{5}$`),
+	"IT": regexp.MustCompile(`^\d{5}$`),
+	"JP": regexp.MustCompile(`^\d{3}-?\d{4}$`),
+	"NL": regexp.MustCompile(`^\d{4} ?[A-Z]{2}$`),
+	"NO": regexp.MustCompile(`^\d{4}$`),
+	"NZ": regexp.MustCompile(`^\d{4}$`),
+	"PL": regexp.MustCompile(`^\d{2}-\d{3}$`),
+	"PT": regexp.MustCompile(`^\d{4}-\d{3}$`),
+	"SE": regexp.MustCompile(`^\d{3} ?\d{2}$`),
+	"US": regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+}
+
+// anyPostalFormat is used for countries without a known pattern
+var anyPostalFormat = regexp.MustCompile(`^[A-Z0-9][A-Z0-9 -]{1,11}$`)
+
+// countryNames maps common English country names to ISO codes so free-text
+// country fields can be matched against postalFormats
+var countryNames = map[string]string{
+	"AUSTRALIA":                "AU",
+	"AUSTRIA":                  "AT",
+	"BELGIUM":                  "BE",
+	"BRAZIL":                   "BR",
+	"CANADA":                   "CA",
+	"DENMARK":                  "DK",
+	"ENGLAND":                  "GB",
+	"FINLAND":                  "FI",
+	"FRANCE":                   "FR",
+	"GERMANY":                  "DE",
+	"GREAT BRITAIN":            "GB",
+	"INDIA":                    "IN",
+	"IRELAND":                  "IE",
+	"ITALY":                    "IT",
+	"JAPAN":                    "JP",
+	"NETHERLANDS":              "NL",
+	"THE NETHERLANDS":          "NL",
+	"NEW ZEALAND":              "NZ",
+	"NORTHERN IRELAND":         "GB",
+	"NORWAY":                   "NO",
+	"POLAND":                   "PL",
+	"PORTUGAL":                 "PT",
+	"SCOTLAND":                 "GB",
+	"SPAIN":                    "ES",
+	"SWEDEN":                   "SE",
+	"SWITZERLAND":              "CH",
+	"UK":                       "GB",
+	"UNITED KINGDOM":           "GB",
+	"UNITED STATES":            "US",
+	"UNITED STATES OF AMERICA": "US",
+	"USA":                      "US",
+	"WALES":                    "GB",
+}
+
+// CountryCode returns the ISO 3166 alpha-2 code for a country given by code or
+// common English name, or "" if it isn't recognised
+func CountryCode(country string) string {
+	c := strings.ToUpper(strings.TrimSpace(country))
+	if code, ok := countryNames[c]; ok {
+		return code
+	}
+	if _, ok := postalFormats[c]; ok {
+		return c
+	}
+	return ""
+}
+
+// PostalCode checks a postal code against the pattern for country and returns
+// it trimmed and upper-cased. Codes for countries without a known pattern are
+// only checked for stray characters.
+func PostalCode(country, code string) (string, error) {
+	code = strings.ToUpper(strings.Join(strings.Fields(code), " "))
+	if code == "" {
+		return "", nil
+	}
+
+	format, ok := postalFormats[CountryCode(country)]
+	if !ok {
+		format = anyPostalFormat
+	}
+	if !format.MatchString(code) {
+		if country == "" {
+			return "", fmt.Errorf("invalid postal code %q", code)
+		}
+		return "", fmt.Errorf("invalid postal code %q for %s", code, strings.TrimSpace(country))
+	}
+	return code, nil
+}
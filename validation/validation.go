@@ -0,0 +1,88 @@
+// Package validation checks and normalises contact and business details.
+// It is shared by the huh forms, which show its errors inline, and the storage
+// layer, which rejects invalid data however it arrives.
+//
+// Every check treats empty input as valid; required fields are checked separately.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// Email checks that s is a bare email address such as "ann@example.com"
+func Email(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s || addr.Name != "" {
+		return fmt.Errorf("invalid email address %q", s)
+	}
+
+	domain := s[strings.LastIndex(s, "@")+1:]
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return fmt.Errorf("invalid email domain %q", domain)
+	}
+	return nil
+}
+
+// Phone checks a phone number and normalises it.
+// International numbers, written with a leading "+" or "00", are returned in
+// E.164 form, e.g. "+44 20 7946 0958" becomes "+442079460958". National numbers
+// without a country code can't be normalised and are returned trimmed as entered.
+func Phone(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil
+	}
+
+	var digits strings.Builder
+	international := false
+	for i, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+			international = true
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')' || r == '/':
+			// Formatting characters are dropped
+		default:
+			return "", fmt.Errorf("invalid character %q in phone number", r)
+		}
+	}
+
+	number := digits.String()
+	if !international && strings.HasPrefix(number, "00") {
+		international = true
+		number = number[2:]
+	}
+
+	if international {
+		// E.164 allows up to 15 digits including the country code, which never starts with 0
+		if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+			return "", errors.New("international phone numbers need a country code and 8-15 digits")
+		}
+		return "+" + number, nil
+	}
+
+	if len(number) < 6 || len(number) > 15 {
+		return "", errors.New("phone numbers need 6-15 digits")
+	}
+	return s, nil
+}
+
+// compact upper-cases s and removes spaces and common separators
+func compact(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '/':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(s)))
+}
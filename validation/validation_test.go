@@ -0,0 +1,170 @@
+package validation
+
+import "testing"
+
+func TestEmail(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{"", false},
+		{"ann@example.com", false},
+		{"first.last+tag@mail.example.co.uk", false},
+		{"ann", true},
+		{"ann@", true},
+		{"@example.com", true},
+		{"ann@localhost", true},
+		{"ann@example.", true},
+		{"Ann <ann@example.com>", true},
+		{"ann@exa mple.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			err := Email(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Email(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPhone(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"", "", false},
+		{"+44 20 7946 0958", "+442079460958", false},
+		{"0044 (20) 7946-0958", "+442079460958", false},
+		{"+1 (555) 010-0100", "+15550100100", false},
+		{"555-0100", "555-0100", false},
+		{" 020 7946 0958 ", "020 7946 0958", false},
+		{"+0 123 456 789", "", true},
+		{"+1 555", "", true},
+		{"+1234567890123456", "", true},
+		{"555-CALL-NOW", "", true},
+		{"12345", "", true},
+		{"555+0100", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := Phone(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Phone(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("Phone(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestVAT(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"", "", false},
+		{"ATU13585627", "ATU13585627", false},
+		{"BE0428759497", "BE0428759497", false},
+		{"DE 136 695 976", "DE136695976", false},
+		{"dk13585628", "DK13585628", false},
+		{"FI20774740", "FI20774740", false},
+		{"FR40303265045", "FR40303265045", false},
+		{"IT00743110157", "IT00743110157", false},
+		{"LU15027442", "LU15027442", false},
+		{"NL004495445B01", "NL004495445B01", false},
+		{"PL 856-734-62-15", "PL8567346215", false},
+		{"PT501964843", "PT501964843", false},
+		{"SE123456789701", "SE123456789701", false},
+		{"ESA12345674", "ESA12345674", false},
+		{"ATU13585626", "", true},
+		{"DE136695977", "", true},
+		{"FR41303265045", "", true},
+		{"IT00743110158", "", true},
+		{"PL8567346216", "", true},
+		{"DE12345", "", true},
+		{"NL004495445A01", "", true},
+		{"XX123456789", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := VAT(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VAT(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("VAT(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTaxID(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"12-3456789", "12-3456789", false},
+		{" GB123456789 ", "GB123456789", false},
+		{"de 136695976", "DE136695976", false},
+		{"DE136695977", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := TaxID(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TaxID(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("TaxID(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPostalCode(t *testing.T) {
+	tests := []struct {
+		country  string
+		code     string
+		expected string
+		wantErr  bool
+	}{
+		{"US", "", "", false},
+		{"US", "62701", "62701", false},
+		{"United States", "62701-1234", "62701-1234", false},
+		{"usa", "6270", "", true},
+		{"GB", "sw1a  1aa", "SW1A 1AA", false},
+		{"United Kingdom", "EC1A 1BB", "EC1A 1BB", false},
+		{"GB", "12345", "", true},
+		{"Canada", "K1A 0B1", "K1A 0B1", false},
+		{"CA", "D1A 0B1", "", true},
+		{"Germany", "10115", "10115", false},
+		{"DE", "1011", "", true},
+		{"Netherlands", "1012 ab", "1012 AB", false},
+		{"IE", "D6W 1234", "D6W 1234", false},
+		{"Ireland", "A65 F4E2", "A65 F4E2", false},
+		{"PL", "00-950", "00-950", false},
+		{"Atlantis", "ABC-123", "ABC-123", false},
+		{"", "!!", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.country+"/"+tt.code, func(t *testing.T) {
+			result, err := PostalCode(tt.country, tt.code)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PostalCode(%q, %q) error = %v, wantErr %v", tt.country, tt.code, err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("PostalCode(%q, %q) = %q, want %q", tt.country, tt.code, result, tt.expected)
+			}
+		})
+	}
+}